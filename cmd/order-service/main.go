@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"log"
 	"order-service/internal/app"
@@ -14,12 +15,29 @@ import (
 	_ "github.com/pressly/goose/v3"
 )
 
+// @title Order Service API
+// @version 1.0.0
+// @BasePath /api/v1
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "replay-dlq" {
+		os.Exit(replayDLQ(os.Args[2:]))
+	}
+
+	brokerKind := flag.String("broker", "", "message broker, kafka or memory; APP_BROKER when empty")
+	messages := flag.String("messages", "", "directory of order messages (*.json) to publish at start, with -broker=memory")
+	flag.Parse()
+
 	cfg, err := config.InitConfig()
 	if err != nil {
 		fmt.Printf("config err: %v", err)
 		return
 	}
+	if *brokerKind != "" {
+		if err := cfg.Broker.SetValue(*brokerKind); err != nil {
+			fmt.Printf("config err: %v", err)
+			return
+		}
+	}
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer cancel()
 
@@ -35,6 +53,17 @@ func main() {
 		}
 	}()
 
+	if *messages != "" {
+		if app.MemoryBroker() == nil {
+			log.Fatalf("-messages needs -broker=memory")
+		}
+		n, err := publishMessages(app.MemoryBroker(), *messages)
+		if err != nil {
+			log.Fatalf("failed to publish messages: %v", err)
+		}
+		log.Printf("published %d messages from %s", n, *messages)
+	}
+
 	<-ctx.Done()
 
 	if err := app.Shutdown(context.Background()); err != nil {
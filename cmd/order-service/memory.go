@@ -0,0 +1,27 @@
+package main
+
+import (
+	"bytes"
+	"order-service/internal/infra/broker/memory"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// publishMessages publishes every *.json file of dir to the memory broker
+// as one order message, keyed by the file name.
+func publishMessages(consumer *memory.Consumer, dir string) (int, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return 0, err
+	}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return 0, err
+		}
+		key := strings.TrimSuffix(filepath.Base(file), ".json")
+		consumer.PublishOrder([]byte(key), bytes.TrimSpace(data))
+	}
+	return len(files), nil
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"order-service/internal/app"
+	"order-service/internal/config"
+	"order-service/internal/infra/broker/kafka"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// replayDLQ runs "order-service replay-dlq [flags]", republishing DLQ
+// messages and printing the replay report as JSON to stdout.
+func replayDLQ(args []string) int {
+	fs := flag.NewFlagSet("replay-dlq", flag.ContinueOnError)
+	partitions := fs.String("partitions", "", "comma separated DLQ partitions, all when empty")
+	fromOffset := fs.Int64("from-offset", 0, "first offset to read in every partition")
+	toOffset := fs.Int64("to-offset", 0, "offset to stop before in every partition, 0 reads to the end")
+	from := fs.String("from", "", "read messages written at or after this RFC 3339 time")
+	to := fs.String("to", "", "read messages written before this RFC 3339 time")
+	orderUID := fs.String("order-uid", "", "replay only the messages of this order")
+	errorClass := fs.String("error-class", "", "replay only this error class: malformed, validation, conflict, invalid_state or internal")
+	target := fs.String("target", string(kafka.ReplayToSource), "publish to the order topics (orders) or the first retry tier (retry)")
+	dryRun := fs.Bool("dry-run", false, "report the matching messages without publishing them")
+	limit := fs.Int("limit", kafka.DefaultReplayLimit, fmt.Sprintf("replay at most this many messages, up to %d", kafka.MaxReplayLimit))
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+
+	req := kafka.ReplayRequest{
+		Range:  kafka.DLQRange{FromOffset: *fromOffset, ToOffset: *toOffset},
+		Filter: kafka.DLQFilter{OrderUID: *orderUID, ErrorClass: kafka.ErrorClass(*errorClass)},
+		Target: kafka.ReplayTarget(*target),
+		DryRun: *dryRun,
+		Limit:  *limit,
+	}
+	if *partitions != "" {
+		for _, p := range strings.Split(*partitions, ",") {
+			id, err := strconv.Atoi(strings.TrimSpace(p))
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "invalid partition %q\n", p)
+				return 2
+			}
+			req.Range.Partitions = append(req.Range.Partitions, id)
+		}
+	}
+	for _, bound := range []struct {
+		flag  string
+		value string
+		dst   *time.Time
+	}{{"from", *from, &req.Range.From}, {"to", *to, &req.Range.To}} {
+		if bound.value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, bound.value)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "-%s must be an RFC 3339 time\n", bound.flag)
+			return 2
+		}
+		*bound.dst = t
+	}
+
+	cfg, err := config.InitConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "config err: %v\n", err)
+		return 1
+	}
+	// the report goes to stdout, so logs go to stderr
+	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
+	replayer := app.BuildReplayer(&cfg.Kafka, logger)
+	defer replayer.Close()
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	report, err := replayer.Replay(ctx, req)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "replay failed: %v\n", err)
+		return 1
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		return 1
+	}
+	return 0
+}
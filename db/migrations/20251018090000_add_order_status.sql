@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE orders ADD COLUMN status VARCHAR(20) NOT NULL DEFAULT 'created';
+
+CREATE TABLE order_status_history (
+    id SERIAL PRIMARY KEY,
+    order_id INT REFERENCES orders(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL,
+    changed_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_order_status_history_order_id ON order_status_history (order_id);
+
+INSERT INTO order_status_history (order_id, status, changed_at)
+SELECT id, status, date_created FROM orders;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS order_status_history;
+ALTER TABLE orders DROP COLUMN IF EXISTS status;
+-- +goose StatementEnd
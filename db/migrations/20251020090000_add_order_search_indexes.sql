@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE INDEX idx_orders_date_created_id ON orders (date_created, id);
+CREATE INDEX idx_orders_customer_id ON orders (customer_id, date_created, id);
+CREATE INDEX idx_orders_track_number ON orders (track_number);
+CREATE INDEX idx_orders_delivery_service ON orders (delivery_service, date_created, id);
+
+CREATE INDEX idx_deliveries_order_id ON deliveries (order_id);
+CREATE INDEX idx_payments_order_id ON payments (order_id);
+CREATE INDEX idx_payments_provider ON payments (provider, order_id);
+CREATE INDEX idx_payments_currency ON payments (currency, order_id);
+CREATE INDEX idx_order_items_order_id ON order_items (order_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_order_items_order_id;
+DROP INDEX IF EXISTS idx_payments_currency;
+DROP INDEX IF EXISTS idx_payments_provider;
+DROP INDEX IF EXISTS idx_payments_order_id;
+DROP INDEX IF EXISTS idx_deliveries_order_id;
+DROP INDEX IF EXISTS idx_orders_delivery_service;
+DROP INDEX IF EXISTS idx_orders_track_number;
+DROP INDEX IF EXISTS idx_orders_customer_id;
+DROP INDEX IF EXISTS idx_orders_date_created_id;
+-- +goose StatementEnd
@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE order_outbox (
+    id BIGSERIAL PRIMARY KEY,
+    order_uid VARCHAR(50) NOT NULL,
+    event_type VARCHAR(50) NOT NULL,
+    payload JSONB NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT now(),
+    attempts INT NOT NULL DEFAULT 0,
+    last_error TEXT,
+    published_at TIMESTAMP
+);
+
+CREATE INDEX idx_order_outbox_pending ON order_outbox (id) WHERE published_at IS NULL;
+CREATE INDEX idx_order_outbox_order_uid_pending ON order_outbox (order_uid, id) WHERE published_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS order_outbox;
+-- +goose StatementEnd
@@ -0,0 +1,9 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE deliveries ADD COLUMN country VARCHAR(2) NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE deliveries DROP COLUMN IF EXISTS country;
+-- +goose StatementEnd
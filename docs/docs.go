@@ -15,6 +15,707 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/admin/cache": {
+            "get": {
+                "description": "Report the size, capacity and counters of the order cache and the orders with the most hits",
+                "tags": [
+                    "admin"
+                ],
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Number of hottest orders, 0-100",
+                        "name": "top",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.CacheStatsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Drop every order from the cache",
+                "tags": [
+                    "admin"
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.CacheFlushResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/cache/warm": {
+            "post": {
+                "description": "Load the latest orders into the cache, up to its capacity",
+                "tags": [
+                    "admin"
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.CacheStatsResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/cache/{uid}": {
+            "get": {
+                "description": "Tell whether an order is cached, without counting as a use of it",
+                "tags": [
+                    "admin"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Order UID",
+                        "name": "uid",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.CacheEntryResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Drop an order from the cache, the next read loads it from the database",
+                "tags": [
+                    "admin"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Order UID",
+                        "name": "uid",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/dlq": {
+            "get": {
+                "description": "Page through the DLQ topic by partition and offset. Payloads are decoded as orders where possible. A page reads at most 10000 messages, so a filtered page may be short or empty and still have a next_cursor",
+                "tags": [
+                    "admin"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Dead-lettered at or after, RFC 3339",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Dead-lettered before, RFC 3339",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Order UID",
+                        "name": "order_uid",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "malformed",
+                            "validation",
+                            "conflict",
+                            "invalid_state",
+                            "internal"
+                        ],
+                        "type": "string",
+                        "description": "Error class",
+                        "name": "error_class",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size, 1-100",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.DLQListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/dlq/counts": {
+            "get": {
+                "description": "Count the messages dead-lettered in a time window by error class and by reason",
+                "tags": [
+                    "admin"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Dead-lettered at or after, RFC 3339, 24 hours before to by default",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Dead-lettered before, RFC 3339, now by default",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.DLQCountsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/dlq/replay": {
+            "post": {
+                "description": "Republish the DLQ messages of an offset or time range, optionally filtered by order_uid or error class, to the order topics or the retry topic",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "parameters": [
+                    {
+                        "description": "Replay request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.DLQReplayRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.DLQReplayResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/order/{uid}": {
+            "put": {
+                "description": "Replace order data, delivery, payment and items, creating the order if it does not exist",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Order UID",
+                        "name": "uid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Order",
+                        "name": "order",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.OrderParams"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.OrderResponse"
+                        }
+                    },
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.OrderResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Update the given fields of an existing order; arrays such as items are replaced as a whole",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Order UID",
+                        "name": "uid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Fields to update",
+                        "name": "order",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.OrderParams"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.OrderResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/order/{uid}/status": {
+            "post": {
+                "description": "Move the order to the next status of its lifecycle: created -\u003e paid -\u003e assembling -\u003e shipped -\u003e delivered, cancelled before shipping, returned after it",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Order UID",
+                        "name": "uid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New status",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.OrderStatusRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.OrderResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders": {
+            "get": {
+                "description": "Search orders by filters. Results are sorted by creation date and paged with an opaque cursor",
+                "tags": [
+                    "orders"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Customer ID",
+                        "name": "customer_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Track number",
+                        "name": "track_number",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Delivery service",
+                        "name": "delivery_service",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Payment provider",
+                        "name": "provider",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Payment currency, ISO 4217",
+                        "name": "currency",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created at or after, RFC 3339",
+                        "name": "created_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created before, RFC 3339",
+                        "name": "created_to",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "asc",
+                            "desc"
+                        ],
+                        "type": "string",
+                        "description": "Sort by date_created",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size, 1-100",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.OrderListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create an order with the validation and idempotency of the Kafka consumer. An exact replay of a stored order returns it with 200. With async=true the order is validated and queued to the orders topic instead of being stored",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "parameters": [
+                    {
+                        "description": "Order",
+                        "name": "order",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.OrderParams"
+                        }
+                    },
+                    {
+                        "type": "boolean",
+                        "default": false,
+                        "description": "Queue the order for the consumer",
+                        "name": "async",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.OrderResponse"
+                        }
+                    },
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.OrderResponse"
+                        }
+                    },
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/dto.OrderAcceptedResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
         "/orders/{uid}": {
             "get": {
                 "description": "Get order by UID",
@@ -29,37 +730,521 @@ const docTemplate = `{
                         "in": "path",
                         "required": true
                     }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/dto.OrderResponse"
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/dto.ErrorResponse"
-                        }
-                    },
-                    "404": {
-                        "description": "Not Found",
-                        "schema": {
-                            "$ref": "#/definitions/dto.ErrorResponse"
-                        }
-                    },
-                    "500": {
-                        "description": "Internal Server Error",
-                        "schema": {
-                            "$ref": "#/definitions/dto.ErrorResponse"
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.OrderResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "domain.DeliveryParams": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string"
+                },
+                "city": {
+                    "type": "string"
+                },
+                "country": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "phone": {
+                    "type": "string"
+                },
+                "region": {
+                    "type": "string"
+                },
+                "zip": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.FieldDiff": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "type": "string"
+                },
+                "incoming": {},
+                "stored": {}
+            }
+        },
+        "domain.ItemParams": {
+            "type": "object",
+            "properties": {
+                "brand": {
+                    "type": "string"
+                },
+                "chrt_id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "nm_id": {
+                    "type": "integer"
+                },
+                "price": {
+                    "type": "integer"
+                },
+                "rid": {
+                    "type": "string"
+                },
+                "sale": {
+                    "type": "integer"
+                },
+                "size": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "integer"
+                },
+                "total_price": {
+                    "type": "integer"
+                },
+                "track_number": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.OrderParams": {
+            "type": "object",
+            "properties": {
+                "customer_id": {
+                    "type": "string"
+                },
+                "date_created": {
+                    "type": "string"
+                },
+                "delivery": {
+                    "$ref": "#/definitions/domain.DeliveryParams"
+                },
+                "delivery_service": {
+                    "type": "string"
+                },
+                "entry": {
+                    "type": "string"
+                },
+                "internal_signature": {
+                    "type": "string"
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.ItemParams"
+                    }
+                },
+                "locale": {
+                    "type": "string"
+                },
+                "oof_shard": {
+                    "type": "string"
+                },
+                "order_uid": {
+                    "type": "string"
+                },
+                "payment": {
+                    "$ref": "#/definitions/domain.PaymentParams"
+                },
+                "shardkey": {
+                    "type": "string"
+                },
+                "sm_id": {
+                    "type": "integer"
+                },
+                "track_number": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.PaymentParams": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "integer"
+                },
+                "bank": {
+                    "type": "string"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "custom_fee": {
+                    "type": "integer"
+                },
+                "delivery_cost": {
+                    "type": "integer"
+                },
+                "goods_total": {
+                    "type": "integer"
+                },
+                "payment_dt": {
+                    "type": "integer"
+                },
+                "provider": {
+                    "type": "string"
+                },
+                "request_id": {
+                    "type": "string"
+                },
+                "transaction": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.CacheEntryResponse": {
+            "type": "object",
+            "properties": {
+                "expires_at": {
+                    "type": "string",
+                    "example": "2021-11-26T06:22:19Z"
+                },
+                "hits": {
+                    "type": "integer",
+                    "example": 42
+                },
+                "order_uid": {
+                    "type": "string",
+                    "example": "b563feb7b2b84b6test"
+                },
+                "size_bytes": {
+                    "type": "integer",
+                    "example": 2264
+                }
+            }
+        },
+        "dto.CacheFlushResponse": {
+            "type": "object",
+            "properties": {
+                "removed": {
+                    "type": "integer",
+                    "example": 812
+                }
+            }
+        },
+        "dto.CacheStatsResponse": {
+            "type": "object",
+            "properties": {
+                "bytes": {
+                    "type": "integer",
+                    "example": 1843200
+                },
+                "capacity": {
+                    "type": "integer",
+                    "example": 1000
+                },
+                "entries": {
+                    "type": "integer",
+                    "example": 812
+                },
+                "evictions": {
+                    "type": "integer",
+                    "example": 27
+                },
+                "expirations": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "hits": {
+                    "type": "integer",
+                    "example": 10452
+                },
+                "hottest": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.CacheEntryResponse"
+                    }
+                },
+                "max_bytes": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "misses": {
+                    "type": "integer",
+                    "example": 318
+                }
+            }
+        },
+        "dto.DLQCountsResponse": {
+            "type": "object",
+            "properties": {
+                "by_error_class": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "by_reason": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "from": {
+                    "type": "string",
+                    "example": "2021-11-25T06:22:19Z"
+                },
+                "to": {
+                    "type": "string",
+                    "example": "2021-11-26T06:22:19Z"
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "truncated": {
+                    "type": "boolean",
+                    "example": false
+                }
+            }
+        },
+        "dto.DLQFailureResponse": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "consumer_group": {
+                    "type": "string",
+                    "example": "orders-group"
+                },
+                "dead_lettered_at": {
+                    "type": "string",
+                    "example": "2021-11-26T06:22:19Z"
+                },
+                "error_class": {
+                    "type": "string",
+                    "example": "validation"
+                },
+                "error_message": {
+                    "type": "string",
+                    "example": "invalid domain state"
+                },
+                "reason": {
+                    "type": "string",
+                    "example": "dlq"
+                },
+                "service_version": {
+                    "type": "string",
+                    "example": "dev"
+                },
+                "source_offset": {
+                    "type": "integer",
+                    "example": 42
+                },
+                "source_partition": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "source_topic": {
+                    "type": "string",
+                    "example": "orders"
+                }
+            }
+        },
+        "dto.DLQListResponse": {
+            "type": "object",
+            "properties": {
+                "messages": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.DLQMessageResponse"
+                    }
+                },
+                "next_cursor": {
+                    "type": "string",
+                    "example": "eyJwIjowLCJvIjoyMH0"
+                }
+            }
+        },
+        "dto.DLQMessageResponse": {
+            "type": "object",
+            "properties": {
+                "conflict_diff": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.FieldDiff"
+                    }
+                },
+                "decode_error": {
+                    "type": "string",
+                    "example": "unexpected end of JSON input"
+                },
+                "failure": {
+                    "$ref": "#/definitions/dto.DLQFailureResponse"
+                },
+                "headers": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "key": {
+                    "type": "string",
+                    "example": "b563feb7b2b84b6test"
+                },
+                "offset": {
+                    "type": "integer",
+                    "example": 17
+                },
+                "order": {
+                    "description": "Order is the decoded payload; Payload holds the raw one when it does\nnot decode as an order.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.OrderParams"
                         }
+                    ]
+                },
+                "partition": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "payload": {
+                    "type": "string"
+                },
+                "time": {
+                    "type": "string",
+                    "example": "2021-11-26T06:22:19Z"
+                },
+                "violations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.ViolationResponse"
                     }
                 }
             }
-        }
-    },
-    "definitions": {
+        },
+        "dto.DLQReplayRequest": {
+            "type": "object",
+            "properties": {
+                "dry_run": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "error_class": {
+                    "type": "string",
+                    "example": "internal"
+                },
+                "from": {
+                    "type": "string",
+                    "example": "2025-10-01T00:00:00Z"
+                },
+                "from_offset": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "limit": {
+                    "type": "integer",
+                    "example": 1000
+                },
+                "order_uid": {
+                    "type": "string",
+                    "example": "b563feb7b2b84b6test"
+                },
+                "partitions": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "target": {
+                    "type": "string",
+                    "enum": [
+                        "orders",
+                        "retry"
+                    ],
+                    "example": "orders"
+                },
+                "to": {
+                    "type": "string",
+                    "example": "2025-10-02T00:00:00Z"
+                },
+                "to_offset": {
+                    "type": "integer",
+                    "example": 100
+                }
+            }
+        },
+        "dto.DLQReplayResponse": {
+            "type": "object",
+            "properties": {
+                "dry_run": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "replayed": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.DLQReplayedMessage"
+                    }
+                },
+                "scanned": {
+                    "type": "integer",
+                    "example": 120
+                },
+                "truncated": {
+                    "type": "boolean",
+                    "example": false
+                }
+            }
+        },
+        "dto.DLQReplayedMessage": {
+            "type": "object",
+            "properties": {
+                "error_class": {
+                    "type": "string",
+                    "example": "internal"
+                },
+                "offset": {
+                    "type": "integer",
+                    "example": 42
+                },
+                "order_uid": {
+                    "type": "string",
+                    "example": "b563feb7b2b84b6test"
+                },
+                "partition": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "topic": {
+                    "type": "string",
+                    "example": "orders"
+                }
+            }
+        },
         "dto.DeliveryResponse": {
             "type": "object",
             "properties": {
@@ -71,6 +1256,10 @@ const docTemplate = `{
                     "type": "string",
                     "example": "Kiryat Mozkin"
                 },
+                "country": {
+                    "type": "string",
+                    "example": "IL"
+                },
                 "email": {
                     "type": "string",
                     "example": "test@gmail.com"
@@ -100,6 +1289,19 @@ const docTemplate = `{
                     "type": "integer",
                     "example": 404
                 },
+                "details": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.ViolationResponse"
+                    }
+                },
+                "diff": {
+                    "description": "Diff lists the fields in which a conflicting order differs from the stored one.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.FieldDiff"
+                    }
+                },
                 "message": {
                     "type": "string",
                     "example": "order not found"
@@ -125,6 +1327,10 @@ const docTemplate = `{
                     "type": "integer",
                     "example": 453
                 },
+                "price_formatted": {
+                    "type": "string",
+                    "example": "4.53 USD"
+                },
                 "sale": {
                     "type": "integer",
                     "example": 30
@@ -141,12 +1347,44 @@ const docTemplate = `{
                     "type": "integer",
                     "example": 317
                 },
+                "total_price_formatted": {
+                    "type": "string",
+                    "example": "3.17 USD"
+                },
                 "track_number": {
                     "type": "string",
                     "example": "WBILMTESTTRACK"
                 }
             }
         },
+        "dto.OrderAcceptedResponse": {
+            "type": "object",
+            "properties": {
+                "order_uid": {
+                    "type": "string",
+                    "example": "b563feb7b2b84b6test"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "queued"
+                }
+            }
+        },
+        "dto.OrderListResponse": {
+            "type": "object",
+            "properties": {
+                "next_cursor": {
+                    "type": "string",
+                    "example": "eyJkIjoiMjAyMS0xMS0yNlQwNjoyMjoxOVoiLCJpIjoxfQ"
+                },
+                "orders": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.OrderResponse"
+                    }
+                }
+            }
+        },
         "dto.OrderResponse": {
             "type": "object",
             "properties": {
@@ -158,6 +1396,10 @@ const docTemplate = `{
                     "type": "string",
                     "example": "2021-11-26T06:22:19Z"
                 },
+                "date_updated": {
+                    "type": "string",
+                    "example": "2021-11-26T06:22:19Z"
+                },
                 "delivery": {
                     "$ref": "#/definitions/dto.DeliveryResponse"
                 },
@@ -178,12 +1420,31 @@ const docTemplate = `{
                 "payment": {
                     "$ref": "#/definitions/dto.PaymentResponse"
                 },
+                "status": {
+                    "type": "string",
+                    "example": "created"
+                },
+                "status_history": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.StatusResponse"
+                    }
+                },
                 "track_number": {
                     "type": "string",
                     "example": "WBILMTESTTRACK"
                 }
             }
         },
+        "dto.OrderStatusRequest": {
+            "type": "object",
+            "properties": {
+                "status": {
+                    "type": "string",
+                    "example": "paid"
+                }
+            }
+        },
         "dto.PaymentResponse": {
             "type": "object",
             "properties": {
@@ -191,6 +1452,10 @@ const docTemplate = `{
                     "type": "integer",
                     "example": 1817
                 },
+                "amount_formatted": {
+                    "type": "string",
+                    "example": "18.17 USD"
+                },
                 "bank": {
                     "type": "string",
                     "example": "alpha"
@@ -199,18 +1464,34 @@ const docTemplate = `{
                     "type": "string",
                     "example": "USD"
                 },
+                "currency_exponent": {
+                    "type": "integer",
+                    "example": 2
+                },
                 "custom_fee": {
                     "type": "integer",
                     "example": 0
                 },
+                "custom_fee_formatted": {
+                    "type": "string",
+                    "example": "0.00 USD"
+                },
                 "delivery_cost": {
                     "type": "integer",
                     "example": 1500
                 },
+                "delivery_cost_formatted": {
+                    "type": "string",
+                    "example": "15.00 USD"
+                },
                 "goods_total": {
                     "type": "integer",
                     "example": 317
                 },
+                "goods_total_formatted": {
+                    "type": "string",
+                    "example": "3.17 USD"
+                },
                 "provider": {
                     "type": "string",
                     "example": "wbpay"
@@ -224,17 +1505,47 @@ const docTemplate = `{
                     "example": "b563feb7b2b84b6test"
                 }
             }
+        },
+        "dto.StatusResponse": {
+            "type": "object",
+            "properties": {
+                "changed_at": {
+                    "type": "string",
+                    "example": "2021-11-26T06:22:19Z"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "created"
+                }
+            }
+        },
+        "dto.ViolationResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "below_zero"
+                },
+                "field": {
+                    "type": "string",
+                    "example": "items[2].price"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "items[2].price is below zero"
+                }
+            }
         }
     }
 }`
 
 // SwaggerInfo holds exported Swagger Info so clients can modify it
 var SwaggerInfo = &swag.Spec{
-	Version:          "",
+	Version:          "1.0.0",
 	Host:             "",
-	BasePath:         "",
+	BasePath:         "/api/v1",
 	Schemes:          []string{},
-	Title:            "",
+	Title:            "Order Service API",
 	Description:      "",
 	InfoInstanceName: "swagger",
 	SwaggerTemplate:  docTemplate,
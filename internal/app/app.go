@@ -3,24 +3,37 @@ package app
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"expvar"
 	"fmt"
+	"io/fs"
 	"log/slog"
 	"order-service/internal/config"
 	server "order-service/internal/controller/http"
+	"order-service/internal/domain"
 	"order-service/internal/infra/broker"
 	"order-service/internal/infra/broker/handler"
 	"order-service/internal/infra/broker/kafka"
+	"order-service/internal/infra/broker/memory"
 	"order-service/internal/infra/broker/retry"
 	"order-service/internal/infra/cache"
+	"order-service/internal/infra/outbox"
 	"order-service/internal/infra/repo/postgres"
 	"order-service/internal/lib/logger"
 	"order-service/internal/usecase"
+	"time"
 )
 
 type App struct {
 	httpServer *server.Server
 	broker     *broker.Broker
+	memory     *memory.Consumer
+	relay      *outbox.Relay
+	replayer   *kafka.Replayer
+	producer   *kafka.OrderProducer
 	db         *postgres.PostgresDB
+	cache      *cache.LRUCache
+	cacheCfg   config.CacheConfig
 	usecase    *usecase.OrderUseCase
 	logger     *slog.Logger
 }
@@ -39,19 +52,52 @@ func BuildApp(cfg *config.Config) (*App, error) {
 	if err != nil {
 		return nil, err
 	}
-	usecase := buildUseCase(db, cache)
-	broker := buildBroker(&cfg.Kafka, usecase, logger)
-	httpServer := buildHTTP(&cfg.HTTP, usecase, logger)
+	usecase := buildUseCase(db, cache, &cfg.Validation, &cfg.Cache)
+
+	if cfg.Broker == config.BrokerMemory {
+		consumer := buildMemoryConsumer(&cfg.Kafka, usecase, logger)
+		httpServer := server.NewServer(&cfg.HTTP, usecase, logger,
+			server.WithOrderQueue(consumer), server.WithCacheAdmin(cache, usecase))
+		return &App{
+			httpServer: httpServer,
+			broker:     broker.NewBroker(consumer, logger),
+			memory:     consumer,
+			relay:      buildRelay(&cfg.Outbox, db, memory.NewEventPublisher(logger), logger),
+			db:         db,
+			cache:      cache,
+			cacheCfg:   cfg.Cache,
+			usecase:    usecase,
+			logger:     logger,
+		}, nil
+	}
+
+	broker := buildBroker(&cfg.Kafka, usecase, db, logger)
+	relay := buildRelay(&cfg.Outbox, db, kafka.NewEventPublisher(cfg.Kafka.Broker, cfg.Outbox.KafkaTopic), logger)
+	replayer := BuildReplayer(&cfg.Kafka, logger)
+	browser := kafka.NewDLQBrowser(kafka.NewDLQReader(cfg.Kafka.Broker, cfg.Kafka.DLQTopicCfg.KafkaTopic))
+	producer := kafka.NewOrderProducer(cfg.Kafka.Broker, cfg.Kafka.OrderTopicCfg.KafkaTopic)
+	httpServer := buildHTTP(&cfg.HTTP, usecase, cache, replayer, browser, producer, logger)
 
 	return &App{
 		httpServer: httpServer,
 		broker:     broker,
+		relay:      relay,
+		replayer:   replayer,
+		producer:   producer,
 		db:         db,
+		cache:      cache,
+		cacheCfg:   cfg.Cache,
 		usecase:    usecase,
 		logger:     logger,
 	}, nil
 }
 
+// MemoryBroker is the consumer messages are published to when the app runs
+// with the memory broker, nil otherwise.
+func (a *App) MemoryBroker() *memory.Consumer {
+	return a.memory
+}
+
 func buildLogger(env string) (*slog.Logger, error) {
 	return logger.InitLogger(env)
 }
@@ -65,29 +111,74 @@ func buildDB(cfg *config.DBConfig) (*postgres.PostgresDB, error) {
 }
 
 func buildCache(cfg *config.CacheConfig) (*cache.LRUCache, error) {
-	return cache.NewLRUCache(cfg.Limit)
+	c, err := cache.NewLRUCache(cfg.Limit,
+		cache.WithMaxBytes(cfg.MaxBytes),
+		cache.WithTTL(time.Duration(cfg.TTL)*time.Second, time.Duration(cfg.TTLJitter)*time.Second),
+		cache.WithExpiryInterval(time.Duration(cfg.ExpiryInterval)*time.Second),
+	)
+	if err != nil {
+		return nil, err
+	}
+	expvar.Publish("cache", expvar.Func(func() any { return c.Stats() }))
+	return c, nil
 }
 
-func buildUseCase(db *postgres.PostgresDB, cache *cache.LRUCache) *usecase.OrderUseCase {
-	return usecase.NewOrderUseCase(db, cache)
+func buildUseCase(db *postgres.PostgresDB, cache *cache.LRUCache, cfg *config.ValidationConfig,
+	cacheCfg *config.CacheConfig) *usecase.OrderUseCase {
+	return usecase.NewOrderUseCase(db, cache,
+		usecase.WithOrderOptions(
+			domain.WithMoneyTolerance(cfg.MoneyTolerance),
+			domain.WithDefaultCountry(cfg.DefaultCountry),
+		),
+		usecase.WithNotFoundTTL(time.Duration(cacheCfg.NotFoundTTL)*time.Millisecond),
+	)
 }
 
-func buildBroker(cfg *config.KafkaConfig, uc *usecase.OrderUseCase, logger *slog.Logger) *broker.Broker {
+func buildBroker(cfg *config.KafkaConfig, uc *usecase.OrderUseCase, db *postgres.PostgresDB, logger *slog.Logger) *broker.Broker {
 	processor := handler.NewMessageProcessor(uc, logger)
 	retry := retry.NewRetry(*cfg)
-	consumer := kafka.NewKafkaConsumer(cfg, processor, retry, logger)
+	var opts []kafka.ConsumerOption
+	if cfg.OffsetStore == config.OffsetStorePostgres {
+		opts = append(opts, kafka.WithOffsetStore(db))
+	}
+	consumer := kafka.NewKafkaConsumer(cfg, processor, retry, logger, opts...)
+	expvar.Publish("retry_tiers", expvar.Func(func() any { return consumer.RetryStats() }))
 	return broker.NewBroker(consumer, logger)
 }
 
-func buildHTTP(cfg *config.HTTPConfig, uc *usecase.OrderUseCase, logger *slog.Logger) *server.Server {
-	return server.NewServer(cfg, uc, logger)
+// buildMemoryConsumer consumes from in-process queues instead of Kafka. The
+// DLQ admin endpoints read Kafka and are not served.
+func buildMemoryConsumer(cfg *config.KafkaConfig, uc *usecase.OrderUseCase, logger *slog.Logger) *memory.Consumer {
+	processor := handler.NewMessageProcessor(uc, logger)
+	return memory.NewConsumer(processor, retry.NewRetry(*cfg), logger)
+}
+
+func buildRelay(cfg *config.OutboxConfig, db *postgres.PostgresDB, publisher outbox.Publisher, logger *slog.Logger) *outbox.Relay {
+	relay := outbox.NewRelay(*cfg, db, publisher, logger)
+	expvar.Publish("outbox", expvar.Func(func() any { return relay.Stats() }))
+	return relay
+}
+
+// BuildReplayer replays the DLQ to the order topics or the first retry tier.
+func BuildReplayer(cfg *config.KafkaConfig, logger *slog.Logger) *kafka.Replayer {
+	tiers := retry.NewRetry(*cfg).Tiers()
+	return kafka.NewReplayer(cfg, tiers[0].Topic, logger)
+}
+
+func buildHTTP(cfg *config.HTTPConfig, uc *usecase.OrderUseCase, cache *cache.LRUCache, replayer *kafka.Replayer,
+	browser *kafka.DLQBrowser, producer *kafka.OrderProducer, logger *slog.Logger) *server.Server {
+	return server.NewServer(cfg, uc, logger, server.WithAdmin(replayer, browser), server.WithOrderQueue(producer),
+		server.WithCacheAdmin(cache, uc))
 }
 
 func (a *App) Run(ctx context.Context) error {
-	if err := a.usecase.LoadOrdersCache(ctx, 1000); err != nil {
+	if err := a.warmUpCache(ctx); err != nil {
 		return err
 	}
-	a.logger.Info("orders cache loaded")
+
+	go func() {
+		a.cache.Run(ctx)
+	}()
 
 	go func() {
 		a.httpServer.Run()
@@ -95,10 +186,50 @@ func (a *App) Run(ctx context.Context) error {
 	go func() {
 		a.broker.Run(ctx)
 	}()
+	go func() {
+		a.relay.Run(ctx)
+	}()
+
+	return nil
+}
+
+// warmUpCache restores the cache from the snapshot saved on the last
+// shutdown, or loads the latest orders when there is none to restore.
+func (a *App) warmUpCache(ctx context.Context) error {
+	if path := a.cacheCfg.SnapshotPath; path != "" {
+		restored, err := a.restoreCache(ctx, path)
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+			a.logger.Info("no cache snapshot", "path", path)
+		case err != nil:
+			a.logger.Warn("failed to restore cache snapshot", "path", path, "error", err)
+		case restored > 0:
+			a.logger.Info("orders cache restored", "path", path, "orders", restored)
+			return nil
+		}
+	}
+
+	if err := a.usecase.LoadOrdersCache(ctx, a.cacheCfg.Limit); err != nil {
+		return err
+	}
+	a.logger.Info("orders cache loaded")
 
 	return nil
 }
 
+func (a *App) restoreCache(ctx context.Context, path string) (int, error) {
+	snap, err := cache.LoadSnapshot(path)
+	if err != nil {
+		return 0, err
+	}
+
+	entries := make([]usecase.CacheEntry, 0, len(snap.Entries))
+	for _, e := range snap.Entries {
+		entries = append(entries, usecase.CacheEntry{OrderUID: e.OrderUID, Order: e.Order})
+	}
+	return a.usecase.RestoreOrdersCache(ctx, entries, a.cacheCfg.Limit)
+}
+
 func (a *App) Shutdown(ctx context.Context) error {
 	var errList []error
 
@@ -107,11 +238,37 @@ func (a *App) Shutdown(ctx context.Context) error {
 	}
 	a.logger.Info("http server shutdown")
 
+	if a.replayer != nil {
+		if err := a.replayer.Close(); err != nil {
+			errList = append(errList, err)
+		}
+	}
+
+	if a.producer != nil {
+		if err := a.producer.Close(); err != nil {
+			errList = append(errList, err)
+		}
+	}
+
 	if err := a.broker.Shutdown(); err != nil {
 		errList = append(errList, err)
 	}
 	a.logger.Info("broker shutdown")
 
+	if err := a.relay.Shutdown(); err != nil {
+		errList = append(errList, err)
+	}
+	a.logger.Info("outbox relay shutdown")
+
+	if a.cacheCfg.SnapshotPath != "" {
+		snap := a.cache.Snapshot(a.cacheCfg.SnapshotValues)
+		if err := cache.SaveSnapshot(a.cacheCfg.SnapshotPath, snap); err != nil {
+			errList = append(errList, err)
+		} else {
+			a.logger.Info("cache snapshot saved", "path", a.cacheCfg.SnapshotPath, "orders", len(snap.Entries))
+		}
+	}
+
 	if err := a.db.Close(); err != nil {
 		errList = append(errList, err)
 	}
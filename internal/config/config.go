@@ -3,6 +3,8 @@ package config
 import (
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/ilyakaznacheev/cleanenv"
 	"github.com/joho/godotenv"
@@ -11,11 +13,35 @@ import (
 var ErrCfgInvalid = errors.New("invalid configuration")
 
 type Config struct {
-	Env   string `env:"APP_ENV"`
-	Kafka KafkaConfig
-	DB    DBConfig
-	HTTP  HTTPConfig
-	Cache CacheConfig
+	Env        string     `env:"APP_ENV"`
+	Broker     BrokerKind `env:"APP_BROKER" env-default:"kafka"`
+	Kafka      KafkaConfig
+	DB         DBConfig
+	HTTP       HTTPConfig
+	Cache      CacheConfig
+	Validation ValidationConfig
+	Outbox     OutboxConfig
+}
+
+// BrokerKind is the message broker the service consumes from.
+type BrokerKind string
+
+const (
+	BrokerKafka BrokerKind = "kafka"
+	// BrokerMemory keeps the queues in process, for local runs without Kafka.
+	BrokerMemory BrokerKind = "memory"
+)
+
+func (b *BrokerKind) SetValue(v string) error {
+	switch BrokerKind(v) {
+	case "", BrokerKafka:
+		*b = BrokerKafka
+	case BrokerMemory:
+		*b = BrokerMemory
+	default:
+		return fmt.Errorf("broker %q is not kafka or memory: %w", v, ErrCfgInvalid)
+	}
+	return nil
 }
 
 type DBConfig struct {
@@ -44,14 +70,89 @@ type RetryTopicConfig struct {
 	GroupID    string `env:"KAFKA_RETRY_GROUP_ID"`
 }
 
+// RetryTier is a retry topic whose messages are processed Delay after they
+// failed. Failed messages move one tier up on every attempt and go to the DLQ
+// after the last tier.
+type RetryTier struct {
+	Topic   string
+	GroupID string
+	Delay   time.Duration
+}
+
+// RetryTiers is read from a comma separated list of topic:group:delay, e.g.
+// orders-retry-10s:retry-10s-group:10s,orders-retry-1m:retry-1m-group:1m.
+type RetryTiers []RetryTier
+
+func (t *RetryTiers) SetValue(s string) error {
+	*t = nil
+	for _, spec := range strings.Split(s, ",") {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+
+		parts := strings.Split(spec, ":")
+		if len(parts) != 3 || parts[0] == "" || parts[1] == "" {
+			return fmt.Errorf("retry tier %q is not topic:group:delay: %w", spec, ErrCfgInvalid)
+		}
+		delay, err := time.ParseDuration(parts[2])
+		if err != nil || delay < 0 {
+			return fmt.Errorf("retry tier %q has invalid delay: %w", spec, ErrCfgInvalid)
+		}
+		*t = append(*t, RetryTier{Topic: parts[0], GroupID: parts[1], Delay: delay})
+	}
+	return nil
+}
+
+// OffsetStore is where the consumed offsets are committed.
+type OffsetStore string
+
+const (
+	// OffsetStoreKafka commits offsets to the consumer groups only.
+	OffsetStoreKafka OffsetStore = "kafka"
+	// OffsetStorePostgres also records every applied message in the
+	// transaction of its order and resumes the readers from the offsets
+	// stored in Postgres.
+	OffsetStorePostgres OffsetStore = "postgres"
+)
+
+func (s *OffsetStore) SetValue(v string) error {
+	switch OffsetStore(v) {
+	case "", OffsetStoreKafka:
+		*s = OffsetStoreKafka
+	case OffsetStorePostgres:
+		*s = OffsetStorePostgres
+	default:
+		return fmt.Errorf("offset store %q is not kafka or postgres: %w", v, ErrCfgInvalid)
+	}
+	return nil
+}
+
+type UpdateTopicConfig struct {
+	KafkaTopic string `env:"KAFKA_UPDATE_TOPIC" env-default:"order-updates"`
+	GroupID    string `env:"KAFKA_UPDATE_GROUP_ID" env-default:"order-updates-group"`
+}
+
 type KafkaConfig struct {
 	OrderTopicCfg      OrderTopicConfig
+	UpdateTopicCfg     UpdateTopicConfig
 	DLQTopicCfg        DLQTopicConfig
 	RetryTopicCfg      RetryTopicConfig
 	Broker             string `env:"KAFKA_BROKER"`
 	RetryMaxAttempts   int    `env:"KAFKA_RETRY_MAX"`
 	BackoffDurationMin int    `env:"KAFKA_BACKOFF_MIN"` // in seconds
 	BackoffDurationMax int    `env:"KAFKA_BACKOFF_MAX"` // in seconds
+	Workers            int    `env:"KAFKA_WORKERS" env-default:"4"`
+	WorkerQueueSize    int    `env:"KAFKA_WORKER_QUEUE" env-default:"64"`
+	CommitInterval     int    `env:"KAFKA_COMMIT_INTERVAL" env-default:"1000"` // in milliseconds
+	BatchSize          int    `env:"KAFKA_BATCH_SIZE" env-default:"0"`         // orders per transaction, batching is off below 2
+	BatchTimeout       int    `env:"KAFKA_BATCH_TIMEOUT" env-default:"200"`    // in milliseconds
+	RetryMaxHeld       int    `env:"KAFKA_RETRY_MAX_HELD" env-default:"1000"`  // retry messages waiting for their due time, per tier
+	// RetryTiers replaces the single retry topic and its backoff when set.
+	RetryTiers RetryTiers `env:"KAFKA_RETRY_TIERS"`
+	// ServiceVersion is reported in the headers of dead-lettered messages.
+	ServiceVersion string      `env:"APP_VERSION" env-default:"dev"`
+	OffsetStore    OffsetStore `env:"KAFKA_OFFSET_STORE" env-default:"kafka"`
 }
 
 type HTTPConfig struct {
@@ -60,10 +161,34 @@ type HTTPConfig struct {
 	ReadTimeout  int    `env:"HTTP_READ_TIMEOUT" env-default:"5"`
 	WriteTimeout int    `env:"HTTP_WRITE_TIMEOUT" env-default:"10"`
 	IdleTimeout  int    `env:"HTTP_IDLE_TIMEOUT" env-default:"120"`
+	AdminToken   string `env:"HTTP_ADMIN_TOKEN"` // bearer token of /api/v1/admin, admin endpoints are off when empty
 }
 
 type CacheConfig struct {
-	Limit int `env:"CACHE_LIMIT" env-default:"1000"`
+	Limit          int   `env:"CACHE_LIMIT" env-default:"1000"`
+	MaxBytes       int64 `env:"CACHE_MAX_BYTES" env-default:"0"`        // estimated size of the cached orders, unbounded when 0
+	TTL            int   `env:"CACHE_TTL" env-default:"0"`              // in seconds, orders do not expire when 0
+	TTLJitter      int   `env:"CACHE_TTL_JITTER" env-default:"0"`       // in seconds, random delay added to the TTL of each order
+	ExpiryInterval int   `env:"CACHE_EXPIRY_INTERVAL" env-default:"60"` // in seconds
+	NotFoundTTL    int   `env:"CACHE_NOT_FOUND_TTL" env-default:"0"`    // in milliseconds, missing orders are not remembered when 0
+	// SnapshotPath is the file the cache is saved to on shutdown and restored
+	// from on start. The cache is warmed up with the latest orders when empty.
+	SnapshotPath   string `env:"CACHE_SNAPSHOT_PATH"`
+	SnapshotValues bool   `env:"CACHE_SNAPSHOT_VALUES" env-default:"false"` // save the orders too, not only their uids
+}
+
+type ValidationConfig struct {
+	MoneyTolerance int    `env:"VALIDATION_MONEY_TOLERANCE" env-default:"0"` // in minor currency units
+	DefaultCountry string `env:"VALIDATION_DEFAULT_COUNTRY"`                 // ISO 3166-1 alpha-2, for phones without "+" and deliveries without a country
+}
+
+type OutboxConfig struct {
+	KafkaTopic   string `env:"OUTBOX_KAFKA_TOPIC" env-default:"order-events"`
+	BatchSize    int    `env:"OUTBOX_BATCH_SIZE" env-default:"100"`
+	PollInterval int    `env:"OUTBOX_POLL_INTERVAL" env-default:"500"` // in milliseconds
+	Lease        int    `env:"OUTBOX_LEASE" env-default:"30"`          // in seconds
+	BackoffMin   int    `env:"OUTBOX_BACKOFF_MIN" env-default:"1"`     // in seconds
+	BackoffMax   int    `env:"OUTBOX_BACKOFF_MAX" env-default:"60"`    // in seconds
 }
 
 func (dc *DBConfig) DSN() string {
@@ -91,6 +216,10 @@ func mapStructs() (*Config, error) {
 	if err := cleanenv.ReadEnv(&cfg); err != nil {
 		return nil, err
 	}
+	// a batch is stored in one transaction and cannot record the offset of each message
+	if cfg.Kafka.OffsetStore == OffsetStorePostgres && cfg.Kafka.BatchSize > 1 {
+		return nil, fmt.Errorf("KAFKA_BATCH_SIZE cannot be used with KAFKA_OFFSET_STORE=postgres: %w", ErrCfgInvalid)
+	}
 	return &cfg, nil
 
 }
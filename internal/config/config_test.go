@@ -0,0 +1,63 @@
+package config
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRetryTiersSetValue(t *testing.T) {
+	var tiers RetryTiers
+	if err := tiers.SetValue("orders-retry-10s:retry-10s-group:10s, orders-retry-10m:retry-10m-group:10m"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := RetryTiers{
+		{Topic: "orders-retry-10s", GroupID: "retry-10s-group", Delay: 10 * time.Second},
+		{Topic: "orders-retry-10m", GroupID: "retry-10m-group", Delay: 10 * time.Minute},
+	}
+	if len(tiers) != len(want) {
+		t.Fatalf("got %d tiers, want %d", len(tiers), len(want))
+	}
+	for i := range want {
+		if tiers[i] != want[i] {
+			t.Fatalf("tier %d: got %+v, want %+v", i, tiers[i], want[i])
+		}
+	}
+
+	for _, spec := range []string{"orders-retry", "orders-retry:group", "orders-retry:group:soon", ":group:10s"} {
+		if err := tiers.SetValue(spec); !errors.Is(err, ErrCfgInvalid) {
+			t.Fatalf("SetValue(%q): got %v, want ErrCfgInvalid", spec, err)
+		}
+	}
+}
+
+func TestOffsetStore(t *testing.T) {
+	var store OffsetStore
+	for v, want := range map[string]OffsetStore{"": OffsetStoreKafka, "kafka": OffsetStoreKafka, "postgres": OffsetStorePostgres} {
+		if err := store.SetValue(v); err != nil || store != want {
+			t.Fatalf("SetValue(%q): got %q, %v, want %q", v, store, err, want)
+		}
+	}
+	if err := store.SetValue("redis"); !errors.Is(err, ErrCfgInvalid) {
+		t.Fatalf("got %v, want ErrCfgInvalid", err)
+	}
+
+	t.Setenv("KAFKA_OFFSET_STORE", "postgres")
+	t.Setenv("KAFKA_BATCH_SIZE", "100")
+	if _, err := mapStructs(); !errors.Is(err, ErrCfgInvalid) {
+		t.Fatalf("batching with postgres offsets: got %v, want ErrCfgInvalid", err)
+	}
+}
+
+func TestBrokerKind(t *testing.T) {
+	var kind BrokerKind
+	for v, want := range map[string]BrokerKind{"": BrokerKafka, "kafka": BrokerKafka, "memory": BrokerMemory} {
+		if err := kind.SetValue(v); err != nil || kind != want {
+			t.Fatalf("SetValue(%q): got %q, %v, want %q", v, kind, err, want)
+		}
+	}
+	if err := kind.SetValue("rabbitmq"); !errors.Is(err, ErrCfgInvalid) {
+		t.Fatalf("got %v, want ErrCfgInvalid", err)
+	}
+}
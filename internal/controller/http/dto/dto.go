@@ -1,6 +1,9 @@
 package dto
 
-import "time"
+import (
+	"order-service/internal/domain"
+	"time"
+)
 
 type OrderResponse struct {
 	OrderUID        string           `json:"order_uid" example:"b563feb7b2b84b6test"`
@@ -11,6 +14,19 @@ type OrderResponse struct {
 	CustomerID      string           `json:"customer_id" example:"test"`
 	DeliveryService string           `json:"delivery_service" example:"meest"`
 	DateCreated     time.Time        `json:"date_created" example:"2021-11-26T06:22:19Z"`
+	DateUpdated     time.Time        `json:"date_updated" example:"2021-11-26T06:22:19Z"`
+	Status          string           `json:"status" example:"created"`
+	StatusHistory   []StatusResponse `json:"status_history"`
+}
+
+type OrderListResponse struct {
+	Orders     []OrderResponse `json:"orders"`
+	NextCursor string          `json:"next_cursor,omitempty" example:"eyJkIjoiMjAyMS0xMS0yNlQwNjoyMjoxOVoiLCJpIjoxfQ"`
+}
+
+type StatusResponse struct {
+	Status    string    `json:"status" example:"created"`
+	ChangedAt time.Time `json:"changed_at" example:"2021-11-26T06:22:19Z"`
 }
 
 type DeliveryResponse struct {
@@ -20,34 +36,154 @@ type DeliveryResponse struct {
 	City    string `json:"city" example:"Kiryat Mozkin"`
 	Address string `json:"address" example:"Ploshad Mira 15"`
 	Region  string `json:"region" example:"Kraiot"`
+	Country string `json:"country,omitempty" example:"IL"`
 	Email   string `json:"email" example:"test@gmail.com"`
 }
 
 type PaymentResponse struct {
-	Transaction  string `json:"transaction" example:"b563feb7b2b84b6test"`
-	RequestID    string `json:"request_id" example:""`
-	Currency     string `json:"currency" example:"USD"`
-	Provider     string `json:"provider" example:"wbpay"`
-	Amount       int    `json:"amount" example:"1817"`
-	Bank         string `json:"bank" example:"alpha"`
-	DeliveryCost int    `json:"delivery_cost" example:"1500"`
-	GoodsTotal   int    `json:"goods_total" example:"317"`
-	CustomFee    int    `json:"custom_fee" example:"0"`
+	Transaction           string `json:"transaction" example:"b563feb7b2b84b6test"`
+	RequestID             string `json:"request_id" example:""`
+	Currency              string `json:"currency" example:"USD"`
+	CurrencyExponent      int    `json:"currency_exponent" example:"2"`
+	Provider              string `json:"provider" example:"wbpay"`
+	Amount                int64  `json:"amount" example:"1817"`
+	AmountFormatted       string `json:"amount_formatted" example:"18.17 USD"`
+	Bank                  string `json:"bank" example:"alpha"`
+	DeliveryCost          int64  `json:"delivery_cost" example:"1500"`
+	DeliveryCostFormatted string `json:"delivery_cost_formatted" example:"15.00 USD"`
+	GoodsTotal            int64  `json:"goods_total" example:"317"`
+	GoodsTotalFormatted   string `json:"goods_total_formatted" example:"3.17 USD"`
+	CustomFee             int64  `json:"custom_fee" example:"0"`
+	CustomFeeFormatted    string `json:"custom_fee_formatted" example:"0.00 USD"`
 }
 
 type ItemResponse struct {
-	ChrtID      int    `json:"chrt_id" example:"9934930"`
-	TrackNumber string `json:"track_number" example:"WBILMTESTTRACK"`
-	Price       int    `json:"price" example:"453"`
-	Name        string `json:"name" example:"Mascaras"`
-	Sale        int    `json:"sale" example:"30"`
-	Size        string `json:"size" example:"0"`
-	TotalPrice  int    `json:"total_price" example:"317"`
-	Brand       string `json:"brand" example:"Vivienne Sabo"`
-	Status      int    `json:"status" example:"202"`
+	ChrtID              int    `json:"chrt_id" example:"9934930"`
+	TrackNumber         string `json:"track_number" example:"WBILMTESTTRACK"`
+	Price               int64  `json:"price" example:"453"`
+	PriceFormatted      string `json:"price_formatted" example:"4.53 USD"`
+	Name                string `json:"name" example:"Mascaras"`
+	Sale                int    `json:"sale" example:"30"`
+	Size                string `json:"size" example:"0"`
+	TotalPrice          int64  `json:"total_price" example:"317"`
+	TotalPriceFormatted string `json:"total_price_formatted" example:"3.17 USD"`
+	Brand               string `json:"brand" example:"Vivienne Sabo"`
+	Status              int    `json:"status" example:"202"`
 }
 
 type ErrorResponse struct {
-	Code    int    `json:"code" example:"404"`
-	Message string `json:"message" example:"order not found"`
+	Code    int                 `json:"code" example:"404"`
+	Message string              `json:"message" example:"order not found"`
+	Details []ViolationResponse `json:"details,omitempty"`
+	// Diff lists the fields in which a conflicting order differs from the stored one.
+	Diff []domain.FieldDiff `json:"diff,omitempty"`
+}
+
+type OrderAcceptedResponse struct {
+	OrderUID string `json:"order_uid" example:"b563feb7b2b84b6test"`
+	Status   string `json:"status" example:"queued"`
+}
+
+type OrderStatusRequest struct {
+	Status string `json:"status" example:"paid"`
+}
+
+type ViolationResponse struct {
+	Field   string `json:"field" example:"items[2].price"`
+	Code    string `json:"code" example:"below_zero"`
+	Message string `json:"message" example:"items[2].price is below zero"`
+}
+
+type DLQReplayRequest struct {
+	Partitions []int      `json:"partitions,omitempty"`
+	FromOffset int64      `json:"from_offset,omitempty" example:"0"`
+	ToOffset   int64      `json:"to_offset,omitempty" example:"100"`
+	From       *time.Time `json:"from,omitempty" example:"2025-10-01T00:00:00Z"`
+	To         *time.Time `json:"to,omitempty" example:"2025-10-02T00:00:00Z"`
+	OrderUID   string     `json:"order_uid,omitempty" example:"b563feb7b2b84b6test"`
+	ErrorClass string     `json:"error_class,omitempty" example:"internal"`
+	Target     string     `json:"target" example:"orders" enums:"orders,retry"`
+	DryRun     bool       `json:"dry_run" example:"true"`
+	Limit      int        `json:"limit,omitempty" example:"1000"`
+}
+
+type DLQReplayedMessage struct {
+	Partition  int    `json:"partition" example:"0"`
+	Offset     int64  `json:"offset" example:"42"`
+	OrderUID   string `json:"order_uid" example:"b563feb7b2b84b6test"`
+	ErrorClass string `json:"error_class" example:"internal"`
+	Topic      string `json:"topic" example:"orders"`
+}
+
+type DLQReplayResponse struct {
+	DryRun    bool                 `json:"dry_run" example:"true"`
+	Scanned   int                  `json:"scanned" example:"120"`
+	Truncated bool                 `json:"truncated" example:"false"`
+	Replayed  []DLQReplayedMessage `json:"replayed"`
+}
+
+type DLQFailureResponse struct {
+	ErrorClass      string     `json:"error_class" example:"validation"`
+	ErrorMessage    string     `json:"error_message" example:"invalid domain state"`
+	Reason          string     `json:"reason" example:"dlq"`
+	SourceTopic     string     `json:"source_topic" example:"orders"`
+	SourcePartition int        `json:"source_partition" example:"0"`
+	SourceOffset    int64      `json:"source_offset" example:"42"`
+	Attempts        int        `json:"attempts" example:"1"`
+	ConsumerGroup   string     `json:"consumer_group" example:"orders-group"`
+	ServiceVersion  string     `json:"service_version" example:"dev"`
+	DeadLetteredAt  *time.Time `json:"dead_lettered_at,omitempty" example:"2021-11-26T06:22:19Z"`
+}
+
+type DLQMessageResponse struct {
+	Partition int       `json:"partition" example:"0"`
+	Offset    int64     `json:"offset" example:"17"`
+	Time      time.Time `json:"time" example:"2021-11-26T06:22:19Z"`
+	Key       string    `json:"key" example:"b563feb7b2b84b6test"`
+	// Order is the decoded payload; Payload holds the raw one when it does
+	// not decode as an order.
+	Order        *domain.OrderParams `json:"order,omitempty"`
+	Payload      string              `json:"payload,omitempty"`
+	DecodeError  string              `json:"decode_error,omitempty" example:"unexpected end of JSON input"`
+	Failure      DLQFailureResponse  `json:"failure"`
+	Violations   []ViolationResponse `json:"violations,omitempty"`
+	ConflictDiff []domain.FieldDiff  `json:"conflict_diff,omitempty"`
+	Headers      map[string]string   `json:"headers"`
+}
+
+type DLQListResponse struct {
+	Messages   []DLQMessageResponse `json:"messages"`
+	NextCursor string               `json:"next_cursor,omitempty" example:"eyJwIjowLCJvIjoyMH0"`
+}
+
+type DLQCountsResponse struct {
+	From         time.Time      `json:"from" example:"2021-11-25T06:22:19Z"`
+	To           time.Time      `json:"to" example:"2021-11-26T06:22:19Z"`
+	Total        int            `json:"total" example:"12"`
+	ByErrorClass map[string]int `json:"by_error_class"`
+	ByReason     map[string]int `json:"by_reason"`
+	Truncated    bool           `json:"truncated" example:"false"`
+}
+
+type CacheStatsResponse struct {
+	Entries     int                  `json:"entries" example:"812"`
+	Capacity    int                  `json:"capacity" example:"1000"`
+	Bytes       int64                `json:"bytes" example:"1843200"`
+	MaxBytes    int64                `json:"max_bytes" example:"0"`
+	Hits        uint64               `json:"hits" example:"10452"`
+	Misses      uint64               `json:"misses" example:"318"`
+	Evictions   uint64               `json:"evictions" example:"27"`
+	Expirations uint64               `json:"expirations" example:"0"`
+	Hottest     []CacheEntryResponse `json:"hottest"`
+}
+
+type CacheEntryResponse struct {
+	OrderUID  string     `json:"order_uid" example:"b563feb7b2b84b6test"`
+	Hits      uint64     `json:"hits" example:"42"`
+	SizeBytes int64      `json:"size_bytes" example:"2264"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty" example:"2021-11-26T06:22:19Z"`
+}
+
+type CacheFlushResponse struct {
+	Removed int `json:"removed" example:"812"`
 }
@@ -0,0 +1,288 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"order-service/internal/controller/http/dto"
+	"order-service/internal/domain"
+	"order-service/internal/infra/broker/kafka"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type DLQReplayer interface {
+	Replay(ctx context.Context, req kafka.ReplayRequest) (kafka.ReplayReport, error)
+}
+
+type DLQBrowser interface {
+	Page(ctx context.Context, q kafka.DLQPageQuery) (kafka.DLQPage, error)
+	Counts(ctx context.Context, from, to time.Time) (kafka.DLQCounts, error)
+}
+
+// defaultCountsWindow is the window of DLQ counts when the request has no from.
+const defaultCountsWindow = 24 * time.Hour
+
+// AdminHandler serves the operational endpoints under /api/v1/admin. They
+// are registered behind authentication.
+type AdminHandler struct {
+	replayer DLQReplayer
+	browser  DLQBrowser
+}
+
+func NewAdminHandler(replayer DLQReplayer, browser DLQBrowser) *AdminHandler {
+	return &AdminHandler{replayer: replayer, browser: browser}
+}
+
+func (h *AdminHandler) RegisterRoutes(r chi.Router) {
+	r.Get("/api/v1/admin/dlq", h.ListDLQHandler)
+	r.Get("/api/v1/admin/dlq/counts", h.DLQCountsHandler)
+	r.Post("/api/v1/admin/dlq/replay", h.ReplayDLQHandler)
+}
+
+// ListDLQHandler @Summary List DLQ messages
+// @Description Page through the DLQ topic by partition and offset. Payloads are decoded as orders where possible. A page reads at most 10000 messages, so a filtered page may be short or empty and still have a next_cursor
+// @Tags admin
+// @Param from query string false "Dead-lettered at or after, RFC 3339"
+// @Param to query string false "Dead-lettered before, RFC 3339"
+// @Param order_uid query string false "Order UID"
+// @Param error_class query string false "Error class" Enums(malformed, validation, conflict, invalid_state, internal)
+// @Param limit query int false "Page size, 1-100" default(20)
+// @Param cursor query string false "next_cursor of the previous page"
+// @Success 200 {object} dto.DLQListResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {string} string
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/dlq [get]
+func (h *AdminHandler) ListDLQHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	q := kafka.DLQPageQuery{
+		Filter: kafka.DLQFilter{OrderUID: query.Get("order_uid"), ErrorClass: kafka.ErrorClass(query.Get("error_class"))},
+		Limit:  kafka.DefaultDLQPageLimit,
+	}
+
+	details := parseTimeWindow(query, &q.From, &q.To)
+	invalid := func(field, message string) {
+		details = append(details, dto.ViolationResponse{Field: field, Code: string(domain.CodeInvalidFormat), Message: message})
+	}
+	if v := query.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > kafka.MaxDLQPageLimit {
+			invalid("limit", "limit must be an integer between 1 and "+strconv.Itoa(kafka.MaxDLQPageLimit))
+		}
+		q.Limit = limit
+	}
+	if v := query.Get("cursor"); v != "" {
+		start, err := kafka.ParseDLQPosition(v)
+		if err != nil {
+			invalid("cursor", "cursor is malformed")
+		}
+		q.Start = &start
+	}
+	if len(details) > 0 {
+		writeError(w, http.StatusBadRequest, "invalid query", details)
+
+		return
+	}
+
+	page, err := h.browser.Page(r.Context(), q)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal server error", nil)
+
+		return
+	}
+
+	resp := dto.DLQListResponse{Messages: make([]dto.DLQMessageResponse, len(page.Letters))}
+	for i, d := range page.Letters {
+		resp.Messages[i] = deadLetterToResponse(d)
+	}
+	if page.Next != nil {
+		resp.NextCursor = page.Next.String()
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// DLQCountsHandler @Summary Count DLQ messages
+// @Description Count the messages dead-lettered in a time window by error class and by reason
+// @Tags admin
+// @Param from query string false "Dead-lettered at or after, RFC 3339, 24 hours before to by default"
+// @Param to query string false "Dead-lettered before, RFC 3339, now by default"
+// @Success 200 {object} dto.DLQCountsResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {string} string
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/dlq/counts [get]
+func (h *AdminHandler) DLQCountsHandler(w http.ResponseWriter, r *http.Request) {
+	var from, to time.Time
+	if details := parseTimeWindow(r.URL.Query(), &from, &to); len(details) > 0 {
+		writeError(w, http.StatusBadRequest, "invalid query", details)
+
+		return
+	}
+	if to.IsZero() {
+		to = time.Now().UTC()
+	}
+	if from.IsZero() {
+		from = to.Add(-defaultCountsWindow)
+	}
+
+	counts, err := h.browser.Counts(r.Context(), from, to)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal server error", nil)
+
+		return
+	}
+
+	byClass := make(map[string]int, len(counts.ByErrorClass))
+	for class, n := range counts.ByErrorClass {
+		byClass[string(class)] = n
+	}
+	writeJSON(w, http.StatusOK, dto.DLQCountsResponse{
+		From:         from,
+		To:           to,
+		Total:        counts.Total,
+		ByErrorClass: byClass,
+		ByReason:     counts.ByReason,
+		Truncated:    counts.Truncated,
+	})
+}
+
+// parseTimeWindow reads the RFC 3339 query parameters from and to, which
+// have to be in order when both are given.
+func parseTimeWindow(query url.Values, from, to *time.Time) []dto.ViolationResponse {
+	var details []dto.ViolationResponse
+	for _, bound := range []struct {
+		field string
+		dst   *time.Time
+	}{{"from", from}, {"to", to}} {
+		if v := query.Get(bound.field); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				details = append(details, dto.ViolationResponse{Field: bound.field, Code: string(domain.CodeInvalidFormat),
+					Message: bound.field + " must be an RFC 3339 timestamp"})
+			}
+			*bound.dst = t
+		}
+	}
+	if len(details) == 0 && !from.IsZero() && !to.IsZero() && !from.Before(*to) {
+		details = append(details, dto.ViolationResponse{Field: "to", Code: string(domain.CodeOutOfRange),
+			Message: "to must be after from"})
+	}
+	return details
+}
+
+func deadLetterToResponse(d kafka.DeadLetter) dto.DLQMessageResponse {
+	f := d.Failure()
+	resp := dto.DLQMessageResponse{
+		Partition: d.Partition,
+		Offset:    d.Offset,
+		Time:      d.Time,
+		Key:       string(d.Key),
+		Failure: dto.DLQFailureResponse{
+			ErrorClass:      string(f.ErrorClass),
+			ErrorMessage:    f.ErrorMessage,
+			Reason:          f.Reason,
+			SourceTopic:     f.SourceTopic,
+			SourcePartition: f.SourcePartition,
+			SourceOffset:    f.SourceOffset,
+			Attempts:        f.Attempts,
+			ConsumerGroup:   f.ConsumerGroup,
+			ServiceVersion:  f.ServiceVersion,
+		},
+		Headers: make(map[string]string, len(d.Headers)),
+	}
+	if !f.DeadLetteredAt.IsZero() {
+		resp.Failure.DeadLetteredAt = &f.DeadLetteredAt
+	}
+	for _, h := range d.Headers {
+		resp.Headers[h.Key] = string(h.Value)
+	}
+
+	var order domain.OrderParams
+	if err := json.Unmarshal(d.Value, &order); err != nil {
+		resp.Payload = string(d.Value)
+		resp.DecodeError = err.Error()
+	} else {
+		resp.Order = &order
+	}
+
+	var violations []domain.Violation
+	if err := json.Unmarshal([]byte(d.Header(kafka.HeaderValidationErrors)), &violations); err == nil {
+		for _, v := range violations {
+			resp.Violations = append(resp.Violations, dto.ViolationResponse{Field: v.Field, Code: string(v.Code), Message: v.Message})
+		}
+	}
+	_ = json.Unmarshal([]byte(d.Header(kafka.HeaderConflictDiff)), &resp.ConflictDiff)
+
+	return resp
+}
+
+// ReplayDLQHandler @Summary Replay DLQ messages
+// @Description Republish the DLQ messages of an offset or time range, optionally filtered by order_uid or error class, to the order topics or the retry topic
+// @Tags admin
+// @Accept json
+// @Param request body dto.DLQReplayRequest true "Replay request"
+// @Success 200 {object} dto.DLQReplayResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {string} string
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/dlq/replay [post]
+func (h *AdminHandler) ReplayDLQHandler(w http.ResponseWriter, r *http.Request) {
+	var body dto.DLQReplayRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body", nil)
+
+		return
+	}
+
+	req := kafka.ReplayRequest{
+		Range: kafka.DLQRange{
+			Partitions: body.Partitions,
+			FromOffset: body.FromOffset,
+			ToOffset:   body.ToOffset,
+		},
+		Filter: kafka.DLQFilter{OrderUID: body.OrderUID, ErrorClass: kafka.ErrorClass(body.ErrorClass)},
+		Target: kafka.ReplayTarget(body.Target),
+		DryRun: body.DryRun,
+		Limit:  body.Limit,
+	}
+	if body.From != nil {
+		req.Range.From = *body.From
+	}
+	if body.To != nil {
+		req.Range.To = *body.To
+	}
+
+	report, err := h.replayer.Replay(r.Context(), req)
+	if err != nil {
+		if errors.Is(err, kafka.ErrInvalidReplay) {
+			writeError(w, http.StatusBadRequest, err.Error(), nil)
+
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "internal server error", nil)
+
+		return
+	}
+
+	replayed := make([]dto.DLQReplayedMessage, len(report.Replayed))
+	for i, m := range report.Replayed {
+		replayed[i] = dto.DLQReplayedMessage{
+			Partition:  m.Partition,
+			Offset:     m.Offset,
+			OrderUID:   m.OrderUID,
+			ErrorClass: string(m.ErrorClass),
+			Topic:      m.Topic,
+		}
+	}
+	writeJSON(w, http.StatusOK, dto.DLQReplayResponse{
+		DryRun:    report.DryRun,
+		Scanned:   report.Scanned,
+		Truncated: report.Truncated,
+		Replayed:  replayed,
+	})
+}
@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"order-service/internal/infra/broker/kafka"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+type MockDLQ struct {
+	replayErr error
+}
+
+func (m *MockDLQ) Page(ctx context.Context, q kafka.DLQPageQuery) (kafka.DLQPage, error) {
+	return kafka.DLQPage{}, nil
+}
+
+func (m *MockDLQ) Counts(ctx context.Context, from, to time.Time) (kafka.DLQCounts, error) {
+	return kafka.DLQCounts{}, nil
+}
+
+func (m *MockDLQ) Replay(ctx context.Context, req kafka.ReplayRequest) (kafka.ReplayReport, error) {
+	return kafka.ReplayReport{}, m.replayErr
+}
+
+func newAdminRouter(dlq *MockDLQ) *chi.Mux {
+	r := chi.NewRouter()
+	NewAdminHandler(dlq, dlq).RegisterRoutes(r)
+	return r
+}
+
+func TestReplayDLQHandlerErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		code int
+	}{
+		{"invalid request", fmt.Errorf("unknown target %q: %w", "x", kafka.ErrInvalidReplay), http.StatusBadRequest},
+		{"replay failure", errors.New("broker down"), http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newAdminRouter(&MockDLQ{replayErr: tt.err})
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/v1/admin/dlq/replay", strings.NewReader(`{"target":"x"}`)))
+			assert.Equal(t, tt.code, w.Code)
+		})
+	}
+}
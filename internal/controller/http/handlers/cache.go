@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"order-service/internal/controller/http/dto"
+	"order-service/internal/domain"
+	"order-service/internal/infra/cache"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type OrderCache interface {
+	Stats() cache.Stats
+	Hottest(n int) []cache.EntryInfo
+	Info(uid string) (cache.EntryInfo, bool)
+	Remove(uid string) bool
+	Purge() int
+}
+
+type CacheWarmer interface {
+	LoadOrdersCache(ctx context.Context, limit int) error
+}
+
+const (
+	defaultHottestKeys = 10
+	maxHottestKeys     = 100
+)
+
+// CacheAdminHandler serves the order cache endpoints under /api/v1/admin.
+// They are registered behind authentication.
+type CacheAdminHandler struct {
+	cache  OrderCache
+	warmer CacheWarmer
+}
+
+func NewCacheAdminHandler(cache OrderCache, warmer CacheWarmer) *CacheAdminHandler {
+	return &CacheAdminHandler{cache: cache, warmer: warmer}
+}
+
+func (h *CacheAdminHandler) RegisterRoutes(r chi.Router) {
+	r.Get("/api/v1/admin/cache", h.CacheStatsHandler)
+	r.Delete("/api/v1/admin/cache", h.FlushCacheHandler)
+	r.Post("/api/v1/admin/cache/warm", h.WarmCacheHandler)
+	r.Get("/api/v1/admin/cache/{uid}", h.CacheEntryHandler)
+	r.Delete("/api/v1/admin/cache/{uid}", h.EvictCacheEntryHandler)
+}
+
+// CacheStatsHandler @Summary Order cache stats
+// @Description Report the size, capacity and counters of the order cache and the orders with the most hits
+// @Tags admin
+// @Param top query int false "Number of hottest orders, 0-100" default(10)
+// @Success 200 {object} dto.CacheStatsResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {string} string
+// @Router /admin/cache [get]
+func (h *CacheAdminHandler) CacheStatsHandler(w http.ResponseWriter, r *http.Request) {
+	top := defaultHottestKeys
+	if v := r.URL.Query().Get("top"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 || n > maxHottestKeys {
+			writeError(w, http.StatusBadRequest, "invalid query", []dto.ViolationResponse{
+				{Field: "top", Code: string(domain.CodeInvalidFormat), Message: "top must be an integer between 0 and " + strconv.Itoa(maxHottestKeys)},
+			})
+
+			return
+		}
+		top = n
+	}
+
+	writeJSON(w, http.StatusOK, h.stats(top))
+}
+
+// CacheEntryHandler @Summary Check cached order
+// @Description Tell whether an order is cached, without counting as a use of it
+// @Tags admin
+// @Param uid path string true "Order UID"
+// @Success 200 {object} dto.CacheEntryResponse
+// @Failure 401 {string} string
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /admin/cache/{uid} [get]
+func (h *CacheAdminHandler) CacheEntryHandler(w http.ResponseWriter, r *http.Request) {
+	info, ok := h.cache.Info(chi.URLParam(r, "uid"))
+	if !ok {
+		writeError(w, http.StatusNotFound, "order is not cached", nil)
+
+		return
+	}
+	writeJSON(w, http.StatusOK, cacheEntryToResponse(info))
+}
+
+// EvictCacheEntryHandler @Summary Evict cached order
+// @Description Drop an order from the cache, the next read loads it from the database
+// @Tags admin
+// @Param uid path string true "Order UID"
+// @Success 204
+// @Failure 401 {string} string
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /admin/cache/{uid} [delete]
+func (h *CacheAdminHandler) EvictCacheEntryHandler(w http.ResponseWriter, r *http.Request) {
+	if !h.cache.Remove(chi.URLParam(r, "uid")) {
+		writeError(w, http.StatusNotFound, "order is not cached", nil)
+
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// FlushCacheHandler @Summary Flush order cache
+// @Description Drop every order from the cache
+// @Tags admin
+// @Success 200 {object} dto.CacheFlushResponse
+// @Failure 401 {string} string
+// @Router /admin/cache [delete]
+func (h *CacheAdminHandler) FlushCacheHandler(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, dto.CacheFlushResponse{Removed: h.cache.Purge()})
+}
+
+// WarmCacheHandler @Summary Warm up order cache
+// @Description Load the latest orders into the cache, up to its capacity
+// @Tags admin
+// @Success 200 {object} dto.CacheStatsResponse
+// @Failure 401 {string} string
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /admin/cache/warm [post]
+func (h *CacheAdminHandler) WarmCacheHandler(w http.ResponseWriter, r *http.Request) {
+	if err := h.warmer.LoadOrdersCache(r.Context(), h.cache.Stats().Capacity); err != nil {
+		writeError(w, http.StatusInternalServerError, "internal server error", nil)
+
+		return
+	}
+	writeJSON(w, http.StatusOK, h.stats(defaultHottestKeys))
+}
+
+func (h *CacheAdminHandler) stats(top int) dto.CacheStatsResponse {
+	stats := h.cache.Stats()
+	resp := dto.CacheStatsResponse{
+		Entries:     stats.Entries,
+		Capacity:    stats.Capacity,
+		Bytes:       stats.Bytes,
+		MaxBytes:    stats.MaxBytes,
+		Hits:        stats.Hits,
+		Misses:      stats.Misses,
+		Evictions:   stats.Evictions,
+		Expirations: stats.Expirations,
+		Hottest:     []dto.CacheEntryResponse{},
+	}
+	if top > 0 {
+		for _, info := range h.cache.Hottest(top) {
+			resp.Hottest = append(resp.Hottest, cacheEntryToResponse(info))
+		}
+	}
+	return resp
+}
+
+func cacheEntryToResponse(info cache.EntryInfo) dto.CacheEntryResponse {
+	resp := dto.CacheEntryResponse{OrderUID: info.OrderUID, Hits: info.Hits, SizeBytes: info.Size}
+	if !info.ExpiresAt.IsZero() {
+		resp.ExpiresAt = &info.ExpiresAt
+	}
+	return resp
+}
@@ -1,31 +1,53 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"io"
 	"net/http"
 	_ "order-service/docs"
 	"order-service/internal/controller/http/dto"
 	"order-service/internal/domain"
 	"order-service/internal/infra/repo"
 	"order-service/internal/usecase"
+	"strconv"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	httpSwagger "github.com/swaggo/http-swagger"
 )
 
+// OrderQueue queues order messages for the consumer, keyed by order uid.
+type OrderQueue interface {
+	EnqueueOrder(ctx context.Context, key, value []byte) error
+}
+
 type HTTPHandler struct {
 	service *usecase.OrderUseCase
+	queue   OrderQueue
 }
 
-func NewHTTPHandler(service *usecase.OrderUseCase) *HTTPHandler {
-	return &HTTPHandler{service: service}
+// NewHTTPHandler serves the order endpoints. Without a queue the
+// asynchronous order creation is not available.
+func NewHTTPHandler(service *usecase.OrderUseCase, queue OrderQueue) *HTTPHandler {
+	return &HTTPHandler{service: service, queue: queue}
 }
 
 func (h *HTTPHandler) RegisterRoutes(r *chi.Mux) {
+	r.Get("/api/v1/orders", h.ListOrdersHandler)
 	r.Get("/api/v1/order/{uid}", h.GetOrderHandler)
 }
 
+// RegisterProtectedRoutes registers the order endpoints served only behind
+// the admin token: the ones that create or change orders.
+func (h *HTTPHandler) RegisterProtectedRoutes(r chi.Router) {
+	r.Post("/api/v1/orders", h.CreateOrderHandler)
+	r.Put("/api/v1/order/{uid}", h.PutOrderHandler)
+	r.Patch("/api/v1/order/{uid}", h.PatchOrderHandler)
+	r.Post("/api/v1/order/{uid}/status", h.ChangeOrderStatusHandler)
+}
+
 func (h *HTTPHandler) RegisterStaticRoutes(r *chi.Mux) {
 	fileServer := http.FileServer(http.Dir("public"))
 	r.Handle("/api/v1/swagger/*", httpSwagger.WrapHandler)
@@ -54,26 +76,13 @@ func (h *HTTPHandler) GetOrderHandler(w http.ResponseWriter, r *http.Request) {
 
 	order, err := h.service.GetOrder(r.Context(), uid)
 	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
 		switch {
 		case errors.Is(err, domain.ErrInvalidState):
-			w.WriteHeader(http.StatusBadRequest)
-			err := json.NewEncoder(w).Encode(dto.ErrorResponse{Code: http.StatusBadRequest, Message: "invalid state"})
-			if err != nil {
-				return
-			}
+			writeError(w, http.StatusBadRequest, "invalid state", violationsToResponse(err))
 		case errors.Is(err, repo.ErrNotFound):
-			w.WriteHeader(http.StatusNotFound)
-			err := json.NewEncoder(w).Encode(dto.ErrorResponse{Code: http.StatusNotFound, Message: err.Error()})
-			if err != nil {
-				return
-			}
+			writeError(w, http.StatusNotFound, err.Error(), nil)
 		default:
-			w.WriteHeader(http.StatusInternalServerError)
-			err := json.NewEncoder(w).Encode(dto.ErrorResponse{Code: http.StatusInternalServerError, Message: "internal server error"})
-			if err != nil {
-				return
-			}
+			writeError(w, http.StatusInternalServerError, "internal server error", nil)
 		}
 
 		return
@@ -88,6 +97,376 @@ func (h *HTTPHandler) GetOrderHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// ListOrdersHandler @Summary List orders
+// @Description Search orders by filters. Results are sorted by creation date and paged with an opaque cursor
+// @Tags orders
+// @Param customer_id query string false "Customer ID"
+// @Param track_number query string false "Track number"
+// @Param delivery_service query string false "Delivery service"
+// @Param provider query string false "Payment provider"
+// @Param currency query string false "Payment currency, ISO 4217"
+// @Param created_from query string false "Created at or after, RFC 3339"
+// @Param created_to query string false "Created before, RFC 3339"
+// @Param sort query string false "Sort by date_created" Enums(asc, desc)
+// @Param limit query int false "Page size, 1-100" default(20)
+// @Param cursor query string false "next_cursor of the previous page"
+// @Success 200 {object} dto.OrderListResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /orders [get]
+func (h *HTTPHandler) ListOrdersHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	params := domain.OrderFilterParams{
+		CustomerID:      query.Get("customer_id"),
+		TrackNumber:     query.Get("track_number"),
+		DeliveryService: query.Get("delivery_service"),
+		Provider:        query.Get("provider"),
+		Currency:        query.Get("currency"),
+		Sort:            query.Get("sort"),
+		Cursor:          query.Get("cursor"),
+	}
+
+	var details []dto.ViolationResponse
+	invalid := func(field, message string) {
+		details = append(details, dto.ViolationResponse{Field: field, Code: string(domain.CodeInvalidFormat), Message: message})
+	}
+	if v := query.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil {
+			invalid("limit", "limit must be an integer")
+		}
+		params.Limit = limit
+	}
+	for _, bound := range []struct {
+		field string
+		dst   *time.Time
+	}{{"created_from", &params.CreatedFrom}, {"created_to", &params.CreatedTo}} {
+		if v := query.Get(bound.field); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				invalid(bound.field, bound.field+" must be an RFC 3339 timestamp")
+			}
+			*bound.dst = t
+		}
+	}
+	if len(details) > 0 {
+		writeError(w, http.StatusBadRequest, "invalid query", details)
+
+		return
+	}
+
+	filter, err := domain.NewOrderFilter(params)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid query", violationsToResponse(err))
+
+		return
+	}
+
+	page, err := h.service.SearchOrders(r.Context(), filter)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal server error", nil)
+
+		return
+	}
+
+	orders := make([]dto.OrderResponse, len(page.Orders))
+	for i, order := range page.Orders {
+		orders[i] = orderToResponse(order)
+	}
+	writeJSON(w, http.StatusOK, dto.OrderListResponse{Orders: orders, NextCursor: page.NextCursor})
+}
+
+// CreateOrderHandler @Summary Create order
+// @Description Create an order with the validation and idempotency of the Kafka consumer. An exact replay of a stored order returns it with 200. With async=true the order is validated and queued to the orders topic instead of being stored
+// @Tags orders
+// @Accept json
+// @Param order body domain.OrderParams true "Order"
+// @Param async query bool false "Queue the order for the consumer" default(false)
+// @Success 200 {object} dto.OrderResponse
+// @Success 201 {object} dto.OrderResponse
+// @Success 202 {object} dto.OrderAcceptedResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {string} string
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 422 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /orders [post]
+func (h *HTTPHandler) CreateOrderHandler(w http.ResponseWriter, r *http.Request) {
+	var params domain.OrderParams
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body", nil)
+
+		return
+	}
+
+	var async bool
+	if v := r.URL.Query().Get("async"); v != "" {
+		var err error
+		if async, err = strconv.ParseBool(v); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid query", []dto.ViolationResponse{
+				{Field: "async", Code: string(domain.CodeInvalidFormat), Message: "async must be a boolean"},
+			})
+
+			return
+		}
+	}
+	if async {
+		h.enqueueOrder(w, r, params)
+
+		return
+	}
+
+	code := http.StatusCreated
+	if err := h.service.CreateOrder(r.Context(), params); err != nil {
+		if !errors.Is(err, usecase.ErrDuplicateOrder) {
+			writeWriteError(w, err)
+
+			return
+		}
+		code = http.StatusOK
+	}
+
+	order, err := h.service.GetOrder(r.Context(), params.OrderUID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal server error", nil)
+
+		return
+	}
+	writeJSON(w, code, orderToResponse(order))
+}
+
+// enqueueOrder queues a valid order for the consumer, which stores it like
+// any order message. Duplicates and conflicts are found by the consumer.
+func (h *HTTPHandler) enqueueOrder(w http.ResponseWriter, r *http.Request, params domain.OrderParams) {
+	if h.queue == nil {
+		writeError(w, http.StatusBadRequest, "async order creation is not available", nil)
+
+		return
+	}
+	if err := h.service.ValidateOrder(params); err != nil {
+		writeWriteError(w, err)
+
+		return
+	}
+
+	value, err := json.Marshal(params)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal server error", nil)
+
+		return
+	}
+	if err := h.queue.EnqueueOrder(r.Context(), []byte(params.OrderUID), value); err != nil {
+		writeError(w, http.StatusInternalServerError, "internal server error", nil)
+
+		return
+	}
+	writeJSON(w, http.StatusAccepted, dto.OrderAcceptedResponse{OrderUID: params.OrderUID, Status: "queued"})
+}
+
+// PutOrderHandler @Summary Create or replace order
+// @Description Replace order data, delivery, payment and items, creating the order if it does not exist
+// @Tags orders
+// @Accept json
+// @Param uid path string true "Order UID"
+// @Param order body domain.OrderParams true "Order"
+// @Success 200 {object} dto.OrderResponse
+// @Success 201 {object} dto.OrderResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {string} string
+// @Failure 422 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /order/{uid} [put]
+func (h *HTTPHandler) PutOrderHandler(w http.ResponseWriter, r *http.Request) {
+	uid := chi.URLParam(r, "uid")
+
+	var params domain.OrderParams
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body", nil)
+
+		return
+	}
+	if params.OrderUID == "" {
+		params.OrderUID = uid
+	}
+	if params.OrderUID != uid {
+		writeError(w, http.StatusBadRequest, "order_uid does not match path", nil)
+
+		return
+	}
+
+	order, created, err := h.service.UpsertOrder(r.Context(), params)
+	if err != nil {
+		writeWriteError(w, err)
+
+		return
+	}
+
+	code := http.StatusOK
+	if created {
+		code = http.StatusCreated
+	}
+	writeJSON(w, code, orderToResponse(order))
+}
+
+// PatchOrderHandler @Summary Update order
+// @Description Update the given fields of an existing order; arrays such as items are replaced as a whole
+// @Tags orders
+// @Accept json
+// @Param uid path string true "Order UID"
+// @Param order body domain.OrderParams true "Fields to update"
+// @Success 200 {object} dto.OrderResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {string} string
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 422 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /order/{uid} [patch]
+func (h *HTTPHandler) PatchOrderHandler(w http.ResponseWriter, r *http.Request) {
+	uid := chi.URLParam(r, "uid")
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body", nil)
+
+		return
+	}
+	var patch map[string]json.RawMessage
+	if err := json.Unmarshal(body, &patch); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body", nil)
+
+		return
+	}
+
+	current, err := h.service.GetOrder(r.Context(), uid)
+	if err != nil {
+		writeWriteError(w, err)
+
+		return
+	}
+
+	params := current.Params()
+	if _, ok := patch["items"]; ok {
+		params.Items = nil
+	}
+	if err := json.Unmarshal(body, &params); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body", nil)
+
+		return
+	}
+	if params.OrderUID != uid {
+		writeError(w, http.StatusBadRequest, "order_uid does not match path", nil)
+
+		return
+	}
+
+	order, err := h.service.UpdateOrder(r.Context(), params)
+	if err != nil {
+		writeWriteError(w, err)
+
+		return
+	}
+
+	writeJSON(w, http.StatusOK, orderToResponse(order))
+}
+
+// ChangeOrderStatusHandler @Summary Change order status
+// @Description Move the order to the next status of its lifecycle: created -> paid -> assembling -> shipped -> delivered, cancelled before shipping, returned after it
+// @Tags orders
+// @Accept json
+// @Param uid path string true "Order UID"
+// @Param request body dto.OrderStatusRequest true "New status"
+// @Success 200 {object} dto.OrderResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {string} string
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Router /order/{uid}/status [post]
+func (h *HTTPHandler) ChangeOrderStatusHandler(w http.ResponseWriter, r *http.Request) {
+	var body dto.OrderStatusRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body", nil)
+
+		return
+	}
+	status, err := domain.ParseStatus(body.Status)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error(), nil)
+
+		return
+	}
+
+	order, err := h.service.ChangeOrderStatus(r.Context(), chi.URLParam(r, "uid"), status)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidState):
+			// the transition is not allowed from the stored status, or the
+			// status changed concurrently
+			writeError(w, http.StatusConflict, err.Error(), nil)
+		case errors.Is(err, repo.ErrNotFound):
+			writeError(w, http.StatusNotFound, err.Error(), nil)
+		default:
+			writeError(w, http.StatusInternalServerError, "internal server error", nil)
+		}
+
+		return
+	}
+
+	writeJSON(w, http.StatusOK, orderToResponse(order))
+}
+
+// writeWriteError maps use case errors of write endpoints to HTTP statuses.
+func writeWriteError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidState):
+		writeError(w, http.StatusUnprocessableEntity, "invalid order", violationsToResponse(err))
+	case errors.Is(err, repo.ErrNotFound):
+		writeError(w, http.StatusNotFound, err.Error(), nil)
+	case errors.Is(err, usecase.ErrIdempotencyKeyExists):
+		resp := dto.ErrorResponse{Code: http.StatusConflict, Message: err.Error()}
+		if cErr, ok := usecase.AsConflictError(err); ok {
+			resp.Diff = cErr.Diff
+		}
+		writeJSON(w, http.StatusConflict, resp)
+	default:
+		writeError(w, http.StatusInternalServerError, "internal server error", nil)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	err := json.NewEncoder(w).Encode(v)
+	if err != nil {
+		return
+	}
+}
+
+func writeError(w http.ResponseWriter, code int, message string, details []dto.ViolationResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	err := json.NewEncoder(w).Encode(dto.ErrorResponse{Code: code, Message: message, Details: details})
+	if err != nil {
+		return
+	}
+}
+
+func violationsToResponse(err error) []dto.ViolationResponse {
+	vErr, ok := domain.AsValidationError(err)
+	if !ok {
+		return nil
+	}
+	details := make([]dto.ViolationResponse, len(vErr.Violations))
+	for i, v := range vErr.Violations {
+		details[i] = dto.ViolationResponse{
+			Field:   v.Field,
+			Code:    string(v.Code),
+			Message: v.Message,
+		}
+	}
+	return details
+}
+
 func orderToResponse(order *domain.Order) dto.OrderResponse {
 	if order == nil {
 		return dto.OrderResponse{} // или можно возвращать ошибку
@@ -95,15 +474,17 @@ func orderToResponse(order *domain.Order) dto.OrderResponse {
 	items := make([]dto.ItemResponse, len(order.Items))
 	for i, item := range order.Items {
 		items[i] = dto.ItemResponse{
-			ChrtID:      item.ChrtID,
-			TrackNumber: item.TrackNumber,
-			Name:        item.Name,
-			Price:       item.Price,
-			Sale:        item.Sale,
-			Size:        item.Size,
-			TotalPrice:  item.TotalPrice,
-			Brand:       item.Brand,
-			Status:      item.Status,
+			ChrtID:              item.ChrtID,
+			TrackNumber:         item.TrackNumber,
+			Name:                item.Name,
+			Price:               item.Price.Amount(),
+			PriceFormatted:      item.Price.String(),
+			Sale:                item.Sale,
+			Size:                item.Size,
+			TotalPrice:          item.TotalPrice.Amount(),
+			TotalPriceFormatted: item.TotalPrice.String(),
+			Brand:               item.Brand,
+			Status:              item.Status,
 		}
 	}
 
@@ -116,6 +497,7 @@ func orderToResponse(order *domain.Order) dto.OrderResponse {
 			City:    order.Delivery.City,
 			Address: order.Delivery.Address,
 			Region:  order.Delivery.Region,
+			Country: order.Delivery.Country,
 			Email:   order.Delivery.Email,
 		}
 	}
@@ -123,15 +505,28 @@ func orderToResponse(order *domain.Order) dto.OrderResponse {
 	var payment dto.PaymentResponse
 	if order.Payment != nil {
 		payment = dto.PaymentResponse{
-			Transaction:  order.Payment.Transaction,
-			RequestID:    order.Payment.RequestID,
-			Currency:     order.Payment.Currency,
-			Provider:     order.Payment.Provider,
-			Amount:       order.Payment.Amount,
-			Bank:         order.Payment.Bank,
-			DeliveryCost: order.Payment.DeliveryCost,
-			GoodsTotal:   order.Payment.GoodsTotal,
-			CustomFee:    order.Payment.CustomFee,
+			Transaction:           order.Payment.Transaction,
+			RequestID:             order.Payment.RequestID,
+			Currency:              order.Payment.Currency.String(),
+			CurrencyExponent:      order.Payment.Currency.Exponent(),
+			Provider:              order.Payment.Provider,
+			Amount:                order.Payment.Amount.Amount(),
+			AmountFormatted:       order.Payment.Amount.String(),
+			Bank:                  order.Payment.Bank,
+			DeliveryCost:          order.Payment.DeliveryCost.Amount(),
+			DeliveryCostFormatted: order.Payment.DeliveryCost.String(),
+			GoodsTotal:            order.Payment.GoodsTotal.Amount(),
+			GoodsTotalFormatted:   order.Payment.GoodsTotal.String(),
+			CustomFee:             order.Payment.CustomFee.Amount(),
+			CustomFeeFormatted:    order.Payment.CustomFee.String(),
+		}
+	}
+
+	statusHistory := make([]dto.StatusResponse, len(order.StatusHistory))
+	for i, transition := range order.StatusHistory {
+		statusHistory[i] = dto.StatusResponse{
+			Status:    string(transition.Status),
+			ChangedAt: transition.ChangedAt,
 		}
 	}
 
@@ -141,8 +536,11 @@ func orderToResponse(order *domain.Order) dto.OrderResponse {
 		DeliveryService: order.DeliveryService,
 		CustomerID:      order.CustomerID,
 		DateCreated:     order.DateCreated,
+		DateUpdated:     order.DateUpdated,
 		Delivery:        delivery,
 		Payment:         payment,
 		Items:           items,
+		Status:          string(order.Status),
+		StatusHistory:   statusHistory,
 	}
 }
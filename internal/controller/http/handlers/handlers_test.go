@@ -0,0 +1,258 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"order-service/internal/controller/http/dto"
+	"order-service/internal/domain"
+	"order-service/internal/infra/repo"
+	"order-service/internal/usecase"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// MockOrderRepo keeps orders in memory and rejects taken uids the way the
+// database does.
+type MockOrderRepo struct {
+	mu     sync.Mutex
+	orders map[string]*domain.Order
+}
+
+func (m *MockOrderRepo) SaveOrder(ctx context.Context, order *domain.Order) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.orders[order.OrderUID]; ok {
+		return repo.ErrAlreadyExists
+	}
+	if m.orders == nil {
+		m.orders = make(map[string]*domain.Order)
+	}
+	m.orders[order.OrderUID] = order.Clone()
+	return nil
+}
+
+func (m *MockOrderRepo) SaveOrders(ctx context.Context, orders []*domain.Order) ([]error, error) {
+	errs := make([]error, len(orders))
+	for i, order := range orders {
+		errs[i] = m.SaveOrder(ctx, order)
+	}
+	return errs, nil
+}
+
+func (m *MockOrderRepo) GetOrderByUid(ctx context.Context, uid string) (*domain.Order, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	order, ok := m.orders[uid]
+	if !ok {
+		return nil, repo.ErrNotFound
+	}
+	return order.Clone(), nil
+}
+
+func (m *MockOrderRepo) GetContentHash(ctx context.Context, uid string) (string, error) {
+	order, err := m.GetOrderByUid(ctx, uid)
+	if err != nil {
+		return "", err
+	}
+	return order.ContentHash(), nil
+}
+
+func (m *MockOrderRepo) GetLastOrders(ctx context.Context, limit int) ([]*domain.Order, error) {
+	return nil, nil
+}
+
+func (m *MockOrderRepo) GetOrdersByUids(ctx context.Context, uids []string) ([]*domain.Order, error) {
+	return nil, nil
+}
+
+func (m *MockOrderRepo) GetOrderVersions(ctx context.Context, uids []string) (map[string]time.Time, error) {
+	return nil, nil
+}
+
+func (m *MockOrderRepo) UpdateOrder(ctx context.Context, order *domain.Order) error {
+	return nil
+}
+
+func (m *MockOrderRepo) UpdateOrderStatus(ctx context.Context, order *domain.Order) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.orders[order.OrderUID]; !ok {
+		return repo.ErrNotFound
+	}
+	m.orders[order.OrderUID] = order.Clone()
+	return nil
+}
+
+func (m *MockOrderRepo) SearchOrders(ctx context.Context, filter domain.OrderFilter) ([]*domain.Order, error) {
+	return nil, nil
+}
+
+// MockCache is an unbounded usecase.Cache.
+type MockCache struct {
+	orders sync.Map
+}
+
+func (m *MockCache) Set(order *domain.Order) {
+	m.orders.Store(order.OrderUID, order.Clone())
+}
+
+func (m *MockCache) Get(uid string) (*domain.Order, bool) {
+	v, ok := m.orders.Load(uid)
+	if !ok {
+		return nil, false
+	}
+	return v.(*domain.Order).Clone(), true
+}
+
+type MockOrderQueue struct {
+	keys   []string
+	values [][]byte
+}
+
+func (m *MockOrderQueue) EnqueueOrder(ctx context.Context, key, value []byte) error {
+	m.keys = append(m.keys, string(key))
+	m.values = append(m.values, value)
+	return nil
+}
+
+const testOrderJSON = `{
+	"order_uid": "b563feb7b2b84b6test",
+	"track_number": "WBILMTESTTRACK",
+	"entry": "WBIL",
+	"delivery": {"name": "Test Testov", "phone": "+9720000000", "zip": "2639809", "city": "Kiryat Mozkin",
+		"address": "Ploshad Mira 15", "region": "Kraiot", "email": "test@gmail.com"},
+	"payment": {"transaction": "b563feb7b2b84b6test", "currency": "USD", "provider": "wbpay", "amount": 1817,
+		"payment_dt": 1637907727, "bank": "alpha", "delivery_cost": 1500, "goods_total": 317, "custom_fee": 0},
+	"items": [{"chrt_id": 9934930, "track_number": "WBILMTESTTRACK", "price": 453, "rid": "ab4219087a764ae0btest",
+		"name": "Mascaras", "sale": 30, "size": "0", "total_price": 317, "nm_id": 2389212, "brand": "Vivienne Sabo", "status": 202}],
+	"locale": "en",
+	"customer_id": "test",
+	"delivery_service": "meest",
+	"shardkey": "9",
+	"sm_id": 99,
+	"date_created": "2021-11-26T06:22:19Z",
+	"oof_shard": "1"
+}`
+
+func newOrderRouter(queue OrderQueue) *chi.Mux {
+	uc := usecase.NewOrderUseCase(&MockOrderRepo{}, &MockCache{})
+	r := chi.NewRouter()
+	h := NewHTTPHandler(uc, queue)
+	h.RegisterRoutes(r)
+	h.RegisterProtectedRoutes(r)
+	return r
+}
+
+func postOrder(r http.Handler, target, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, target, strings.NewReader(body)))
+	return w
+}
+
+func TestCreateOrderHandler(t *testing.T) {
+	r := newOrderRouter(nil)
+
+	w := postOrder(r, "/api/v1/orders", testOrderJSON)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var created dto.OrderResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&created))
+	assert.Equal(t, "b563feb7b2b84b6test", created.OrderUID)
+
+	w = postOrder(r, "/api/v1/orders", testOrderJSON)
+	assert.Equal(t, http.StatusOK, w.Code, "an exact replay returns the stored order")
+
+	w = postOrder(r, "/api/v1/orders", strings.Replace(testOrderJSON, `"entry": "WBIL"`, `"entry": "WBNEW"`, 1))
+	require.Equal(t, http.StatusConflict, w.Code, w.Body.String())
+	var conflict dto.ErrorResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&conflict))
+	assert.Equal(t, []domain.FieldDiff{{Field: "entry", Stored: "WBIL", Incoming: "WBNEW"}}, conflict.Diff)
+}
+
+func TestCreateOrderHandlerErrors(t *testing.T) {
+	invalid := strings.Replace(testOrderJSON, `"order_uid": "b563feb7b2b84b6test"`, `"order_uid": ""`, 1)
+	tests := []struct {
+		name   string
+		target string
+		body   string
+		code   int
+	}{
+		{"malformed body", "/api/v1/orders", `{"order_uid":`, http.StatusBadRequest},
+		{"invalid order", "/api/v1/orders", invalid, http.StatusUnprocessableEntity},
+		{"invalid async", "/api/v1/orders?async=maybe", testOrderJSON, http.StatusBadRequest},
+		{"async without queue", "/api/v1/orders?async=true", testOrderJSON, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := postOrder(newOrderRouter(nil), tt.target, tt.body)
+			require.Equal(t, tt.code, w.Code, w.Body.String())
+
+			var resp dto.ErrorResponse
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+			if tt.code == http.StatusUnprocessableEntity {
+				assert.NotEmpty(t, resp.Details)
+			}
+		})
+	}
+}
+
+func TestCreateOrderHandlerAsync(t *testing.T) {
+	queue := &MockOrderQueue{}
+	r := newOrderRouter(queue)
+
+	w := postOrder(r, "/api/v1/orders?async=true", testOrderJSON)
+	require.Equal(t, http.StatusAccepted, w.Code, w.Body.String())
+	var resp dto.OrderAcceptedResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+	assert.Equal(t, "b563feb7b2b84b6test", resp.OrderUID)
+	assert.Equal(t, []string{"b563feb7b2b84b6test"}, queue.keys)
+
+	invalid := strings.Replace(testOrderJSON, `"order_uid": "b563feb7b2b84b6test"`, `"order_uid": ""`, 1)
+	w = postOrder(r, "/api/v1/orders?async=true", invalid)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code, "invalid orders are not queued")
+	assert.Len(t, queue.keys, 1)
+}
+
+func TestChangeOrderStatusHandler(t *testing.T) {
+	r := newOrderRouter(nil)
+	require.Equal(t, http.StatusCreated, postOrder(r, "/api/v1/orders", testOrderJSON).Code)
+
+	w := postOrder(r, "/api/v1/order/b563feb7b2b84b6test/status", `{"status":"paid"}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var resp dto.OrderResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+	assert.Equal(t, "paid", resp.Status)
+	require.Len(t, resp.StatusHistory, 2)
+	assert.Equal(t, "paid", resp.StatusHistory[1].Status)
+
+	tests := []struct {
+		name   string
+		target string
+		body   string
+		code   int
+	}{
+		{"transition not allowed", "/api/v1/order/b563feb7b2b84b6test/status", `{"status":"delivered"}`, http.StatusConflict},
+		{"same status", "/api/v1/order/b563feb7b2b84b6test/status", `{"status":"paid"}`, http.StatusConflict},
+		{"unknown status", "/api/v1/order/b563feb7b2b84b6test/status", `{"status":"lost"}`, http.StatusBadRequest},
+		{"malformed body", "/api/v1/order/b563feb7b2b84b6test/status", `{"status":`, http.StatusBadRequest},
+		{"unknown order", "/api/v1/order/missing/status", `{"status":"paid"}`, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := postOrder(r, tt.target, tt.body)
+			assert.Equal(t, tt.code, w.Code, w.Body.String())
+		})
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/order/b563feb7b2b84b6test", nil))
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+	assert.Equal(t, "paid", resp.Status, "rejected transitions leave the order as it was")
+}
@@ -3,6 +3,7 @@ package http
 import (
 	"context"
 	"errors"
+	"expvar"
 	"fmt"
 	"log/slog"
 	"net/http"
@@ -17,17 +18,44 @@ import (
 )
 
 type Server struct {
-	cfg         *config.HTTPConfig
-	httpHandler *handlers.HTTPHandler
-	logger      *slog.Logger
-	httpServer  *http.Server
+	cfg          *config.HTTPConfig
+	httpHandler  *handlers.HTTPHandler
+	adminHandler *handlers.AdminHandler
+	cacheHandler *handlers.CacheAdminHandler
+	orderQueue   handlers.OrderQueue
+	logger       *slog.Logger
+	httpServer   *http.Server
 }
 
-func NewServer(cfg *config.HTTPConfig, uc *usecase.OrderUseCase, l *slog.Logger) *Server {
-	return &Server{
-		cfg:         cfg,
-		httpHandler: handlers.NewHTTPHandler(uc),
-		logger:      l,
+type Option func(*Server)
+
+// WithAdmin serves the admin endpoints. They are registered only when
+// HTTP_ADMIN_TOKEN is set.
+func WithAdmin(replayer handlers.DLQReplayer, browser handlers.DLQBrowser) Option {
+	return func(s *Server) {
+		s.adminHandler = handlers.NewAdminHandler(replayer, browser)
+	}
+}
+
+// WithCacheAdmin serves the order cache admin endpoints. Like the other
+// admin endpoints, they are registered only when HTTP_ADMIN_TOKEN is set.
+func WithCacheAdmin(cache handlers.OrderCache, warmer handlers.CacheWarmer) Option {
+	return func(s *Server) {
+		s.cacheHandler = handlers.NewCacheAdminHandler(cache, warmer)
+	}
+}
+
+// WithOrderQueue lets POST /api/v1/orders queue orders for the consumer.
+func WithOrderQueue(queue handlers.OrderQueue) Option {
+	return func(s *Server) {
+		s.orderQueue = queue
+	}
+}
+
+func NewServer(cfg *config.HTTPConfig, uc *usecase.OrderUseCase, l *slog.Logger, opts ...Option) *Server {
+	s := &Server{
+		cfg:    cfg,
+		logger: l,
 		httpServer: &http.Server{
 			Addr:         fmt.Sprintf("%s:%s", cfg.Host, cfg.Port),
 			ReadTimeout:  time.Duration(cfg.ReadTimeout) * time.Second,
@@ -35,6 +63,12 @@ func NewServer(cfg *config.HTTPConfig, uc *usecase.OrderUseCase, l *slog.Logger)
 			IdleTimeout:  time.Duration(cfg.IdleTimeout) * time.Second,
 		},
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.httpHandler = handlers.NewHTTPHandler(uc, s.orderQueue)
+
+	return s
 }
 
 func (s *Server) Run() {
@@ -61,5 +95,20 @@ func (s *Server) Routes() http.Handler {
 	
 	s.httpHandler.RegisterRoutes(r)
 
+	// admin routes and the runtime stats are served only with a token
+	if s.cfg.AdminToken != "" {
+		r.Group(func(r chi.Router) {
+			r.Use(mid.AdminAuth(s.cfg.AdminToken))
+			r.Handle("/debug/vars", expvar.Handler())
+			s.httpHandler.RegisterProtectedRoutes(r)
+			if s.adminHandler != nil {
+				s.adminHandler.RegisterRoutes(r)
+			}
+			if s.cacheHandler != nil {
+				s.cacheHandler.RegisterRoutes(r)
+			}
+		})
+	}
+
 	return r
 }
@@ -0,0 +1,108 @@
+package http
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"order-service/internal/config"
+	"order-service/internal/domain"
+	"order-service/internal/infra/broker/kafka"
+	"order-service/internal/infra/cache"
+	"order-service/internal/usecase"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type MockDLQ struct{}
+
+func (MockDLQ) Page(ctx context.Context, q kafka.DLQPageQuery) (kafka.DLQPage, error) {
+	return kafka.DLQPage{}, nil
+}
+
+func (MockDLQ) Counts(ctx context.Context, from, to time.Time) (kafka.DLQCounts, error) {
+	return kafka.DLQCounts{}, nil
+}
+
+func (MockDLQ) Replay(ctx context.Context, req kafka.ReplayRequest) (kafka.ReplayReport, error) {
+	return kafka.ReplayReport{}, nil
+}
+
+func newTestServer(t *testing.T, token string) http.Handler {
+	c, err := cache.NewLRUCache(10)
+	require.NoError(t, err)
+	c.Set(&domain.Order{OrderUID: "b563feb7b2b84b6test"})
+	cfg := &config.HTTPConfig{AdminToken: token}
+	uc := usecase.NewOrderUseCase(nil, nil)
+	l := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	return NewServer(cfg, uc, l, WithAdmin(MockDLQ{}, MockDLQ{}), WithCacheAdmin(c, uc)).Routes()
+}
+
+// adminPaths are the routes served only behind the admin token.
+var adminPaths = []string{
+	"/debug/vars",
+	"/api/v1/admin/dlq",
+	"/api/v1/admin/dlq/counts",
+	"/api/v1/admin/cache",
+	"/api/v1/admin/cache/b563feb7b2b84b6test",
+}
+
+func TestRoutesAdminAuth(t *testing.T) {
+	tests := []struct {
+		name  string
+		token string
+		auth  string
+		code  int
+	}{
+		{"admin disabled", "", "", http.StatusNotFound},
+		{"admin disabled with header", "", "Bearer ", http.StatusNotFound},
+		{"no token", "secret", "", http.StatusUnauthorized},
+		{"wrong token", "secret", "Bearer wrong", http.StatusUnauthorized},
+		{"valid token", "secret", "Bearer secret", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newTestServer(t, tt.token)
+			for _, path := range adminPaths {
+				req := httptest.NewRequest(http.MethodGet, path, nil)
+				if tt.auth != "" {
+					req.Header.Set("Authorization", tt.auth)
+				}
+				w := httptest.NewRecorder()
+				r.ServeHTTP(w, req)
+				assert.Equal(t, tt.code, w.Code, path)
+			}
+		})
+	}
+}
+
+// orderWrites are the order endpoints that create or change orders, served
+// only behind the admin token.
+var orderWrites = []struct{ method, path string }{
+	{http.MethodPost, "/api/v1/orders"},
+	{http.MethodPut, "/api/v1/order/b563feb7b2b84b6test"},
+	{http.MethodPatch, "/api/v1/order/b563feb7b2b84b6test"},
+	{http.MethodPost, "/api/v1/order/b563feb7b2b84b6test/status"},
+}
+
+func TestRoutesOrderWritesAuth(t *testing.T) {
+	for _, token := range []string{"", "secret"} {
+		r := newTestServer(t, token)
+		for _, route := range orderWrites {
+			req := httptest.NewRequest(route.method, route.path, strings.NewReader(`{}`))
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			if token == "" {
+				assert.Contains(t, []int{http.StatusNotFound, http.StatusMethodNotAllowed}, w.Code, "%s %s", route.method, route.path)
+			} else {
+				assert.Equal(t, http.StatusUnauthorized, w.Code, "%s %s", route.method, route.path)
+			}
+		}
+	}
+}
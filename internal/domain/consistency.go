@@ -0,0 +1,39 @@
+package domain
+
+import "fmt"
+
+var ErrInconsistentAmounts = fmt.Errorf("inconsistent amounts: %w", ErrInvalidState)
+
+func validateConsistency(v *validator, payment *Payment, items []*Item, tolerance int) {
+	tol := int64(tolerance)
+
+	totals := make([]Money, 0, len(items))
+	for i, item := range items {
+		expected := item.Price.Discount(item.Sale)
+		if ok, err := item.TotalPrice.Within(expected, tol); err != nil || !ok {
+			v.add(fmt.Sprintf("items[%d].total_price", i), CodeInconsistent,
+				"total_price %s, expected %s from price %s and sale %d", item.TotalPrice, expected, item.Price, item.Sale)
+		}
+		totals = append(totals, item.TotalPrice)
+	}
+
+	goodsTotal, err := SumMoney(payment.Currency, totals...)
+	if err != nil {
+		v.add("items", CodeInconsistent, "%s", err)
+		return
+	}
+	if ok, err := payment.GoodsTotal.Within(goodsTotal, tol); err != nil || !ok {
+		v.add("payment.goods_total", CodeInconsistent,
+			"goods_total %s, expected sum of items total_price %s", payment.GoodsTotal, goodsTotal)
+	}
+
+	amount, err := SumMoney(payment.Currency, payment.GoodsTotal, payment.DeliveryCost, payment.CustomFee)
+	if err != nil {
+		v.add("payment", CodeInconsistent, "%s", err)
+		return
+	}
+	if ok, err := payment.Amount.Within(amount, tol); err != nil || !ok {
+		v.add("payment.amount", CodeInconsistent,
+			"amount %s, expected goods_total + delivery_cost + custom_fee %s", payment.Amount, amount)
+	}
+}
@@ -0,0 +1,109 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func consistentOrderParams() OrderParams {
+	return OrderParams{
+		OrderUID:    "b563feb7b2b84b6test",
+		TrackNumber: "WBILMTESTTRACK",
+		Entry:       "WBIL",
+		Delivery: DeliveryParams{
+			Name:    "Test Testov",
+			Phone:   "+9720000000",
+			Zip:     "2639809",
+			City:    "Kiryat Mozkin",
+			Address: "Ploshad Mira 15",
+			Region:  "Kraiot",
+			Email:   "test@gmail.com",
+		},
+		Payment: PaymentParams{
+			Transaction:  "b563feb7b2b84b6test",
+			Currency:     "USD",
+			Provider:     "wbpay",
+			Amount:       2027,
+			PaymentDt:    1637907727,
+			Bank:         "alpha",
+			DeliveryCost: 1500,
+			GoodsTotal:   527,
+			CustomFee:    0,
+		},
+		Items: []ItemParams{
+			{ChrtID: 9934930, TrackNumber: "WBILMTESTTRACK", Price: 453, Sale: 30, TotalPrice: 317, Name: "Mascaras"},
+			{ChrtID: 9934931, TrackNumber: "WBILMTESTTRACK", Price: 210, Sale: 0, TotalPrice: 210, Name: "Lipstick"},
+		},
+		Locale:      "en",
+		CustomerID:  "test",
+		DateCreated: time.Date(2021, 11, 26, 6, 22, 0, 0, time.UTC),
+	}
+}
+
+func TestNewOrderConsistentAmounts(t *testing.T) {
+	order, err := NewOrder(consistentOrderParams())
+	if err != nil {
+		t.Fatalf("expected err nil, got %v", err)
+	}
+	if order == nil {
+		t.Fatalf("expected not nil, got nil")
+	}
+}
+
+func TestNewOrderInconsistentAmounts(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(p *OrderParams)
+	}{
+		{
+			name:   "amount differs from components",
+			modify: func(p *OrderParams) { p.Payment.Amount = 2000 },
+		},
+		{
+			name:   "goods total differs from items",
+			modify: func(p *OrderParams) { p.Payment.GoodsTotal = 500; p.Payment.Amount = 2000 },
+		},
+		{
+			name:   "item total ignores sale",
+			modify: func(p *OrderParams) { p.Items[0].TotalPrice = 453 },
+		},
+		{
+			name:   "sale above 100",
+			modify: func(p *OrderParams) { p.Items[0].Sale = 130 },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := consistentOrderParams()
+			tt.modify(&params)
+
+			order, err := NewOrder(params)
+			if order != nil {
+				t.Fatalf("expected nil, got %v", order)
+			}
+			if !errors.Is(err, ErrInvalidState) {
+				t.Fatalf("expected error to be ErrInvalidState, got %v", err)
+			}
+		})
+	}
+}
+
+func TestNewOrderMoneyTolerance(t *testing.T) {
+	params := consistentOrderParams()
+	params.Items[0].TotalPrice = 318
+	params.Payment.Amount = 2029
+
+	if _, err := NewOrder(params); !errors.Is(err, ErrInconsistentAmounts) {
+		t.Fatalf("expected error to be ErrInconsistentAmounts, got %v", err)
+	}
+
+	order, err := NewOrder(params, WithMoneyTolerance(2))
+	if err != nil {
+		t.Fatalf("expected err nil, got %v", err)
+	}
+	if order == nil {
+		t.Fatalf("expected not nil, got nil")
+	}
+}
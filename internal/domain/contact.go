@@ -0,0 +1,141 @@
+package domain
+
+import (
+	"net/mail"
+	"regexp"
+	"strings"
+)
+
+// CountryRules describes how phone numbers and postal codes of one country
+// are written. Register extra countries with WithCountryRules.
+type CountryRules struct {
+	Country        string // ISO 3166-1 alpha-2
+	CallingCode    string // without the leading "+"
+	TrunkPrefix    string // dropped from national numbers, e.g. "8" in Russia
+	MinNationalLen int
+	MaxNationalLen int
+	PostalCode     *regexp.Regexp
+}
+
+func DefaultCountryRules() []CountryRules {
+	return []CountryRules{
+		{Country: "RU", CallingCode: "7", TrunkPrefix: "8", MinNationalLen: 10, MaxNationalLen: 10, PostalCode: regexp.MustCompile(`^\d{6}$`)},
+		{Country: "US", CallingCode: "1", TrunkPrefix: "1", MinNationalLen: 10, MaxNationalLen: 10, PostalCode: regexp.MustCompile(`^\d{5}(-\d{4})?$`)},
+		{Country: "DE", CallingCode: "49", TrunkPrefix: "0", MinNationalLen: 6, MaxNationalLen: 13, PostalCode: regexp.MustCompile(`^\d{5}$`)},
+		{Country: "GB", CallingCode: "44", TrunkPrefix: "0", MinNationalLen: 9, MaxNationalLen: 10, PostalCode: regexp.MustCompile(`^[A-Z]{1,2}\d[A-Z\d]? ?\d[A-Z]{2}$`)},
+		{Country: "BY", CallingCode: "375", TrunkPrefix: "80", MinNationalLen: 9, MaxNationalLen: 9, PostalCode: regexp.MustCompile(`^\d{6}$`)},
+	}
+}
+
+const (
+	minE164Digits = 8
+	maxE164Digits = 15
+)
+
+type contactRules struct {
+	countries      []CountryRules
+	defaultCountry string
+}
+
+func (c contactRules) byCountry(country string) (CountryRules, bool) {
+	for _, r := range c.countries {
+		if r.Country == country {
+			return r, true
+		}
+	}
+	return CountryRules{}, false
+}
+
+func (c contactRules) byCallingCode(digits string) (CountryRules, bool) {
+	var best CountryRules
+	found := false
+	for _, r := range c.countries {
+		if strings.HasPrefix(digits, r.CallingCode) && len(r.CallingCode) > len(best.CallingCode) {
+			best, found = r, true
+		}
+	}
+	return best, found
+}
+
+// normalizePhone returns the phone in E.164 form together with the rules of
+// the country it belongs to, if that country is known.
+func (c contactRules) normalizePhone(raw string) (string, *CountryRules, bool) {
+	digits, international := phoneDigits(raw)
+	if digits == "" {
+		return "", nil, false
+	}
+
+	if !international {
+		rules, ok := c.byCountry(c.defaultCountry)
+		if !ok {
+			return "", nil, false
+		}
+		national := strings.TrimPrefix(digits, rules.TrunkPrefix)
+		if !rules.validNational(national) {
+			return "", nil, false
+		}
+		return "+" + rules.CallingCode + national, &rules, true
+	}
+
+	if rules, ok := c.byCallingCode(digits); ok {
+		national := digits[len(rules.CallingCode):]
+		if !rules.validNational(national) {
+			return "", nil, false
+		}
+		return "+" + digits, &rules, true
+	}
+
+	if len(digits) < minE164Digits || len(digits) > maxE164Digits {
+		return "", nil, false
+	}
+	return "+" + digits, nil, true
+}
+
+func (r CountryRules) validNational(national string) bool {
+	return len(national) >= r.MinNationalLen && len(national) <= r.MaxNationalLen
+}
+
+// phoneDigits strips formatting characters and reports whether the number was
+// written with an international prefix ("+" or "00").
+func phoneDigits(raw string) (string, bool) {
+	raw = strings.TrimSpace(raw)
+	international := false
+	switch {
+	case strings.HasPrefix(raw, "+"):
+		international = true
+		raw = raw[1:]
+	case strings.HasPrefix(raw, "00"):
+		international = true
+		raw = raw[2:]
+	}
+
+	var b strings.Builder
+	for _, r := range raw {
+		switch {
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case r == ' ' || r == '-' || r == '(' || r == ')' || r == '.':
+		default:
+			return "", false
+		}
+	}
+	return b.String(), international
+}
+
+func normalizePostalCode(raw string) string {
+	return strings.ToUpper(strings.Join(strings.Fields(raw), " "))
+}
+
+func normalizeEmail(raw string) (string, bool) {
+	raw = strings.TrimSpace(raw)
+	addr, err := mail.ParseAddress(raw)
+	if err != nil || addr.Address != raw {
+		return "", false
+	}
+	at := strings.LastIndex(raw, "@")
+	domain := strings.ToLower(raw[at+1:])
+	if !strings.Contains(domain, ".") || strings.HasPrefix(domain, ".") || strings.HasSuffix(domain, ".") {
+		return "", false
+	}
+	return raw[:at+1] + domain, true
+}
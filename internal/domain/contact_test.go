@@ -0,0 +1,158 @@
+package domain
+
+import (
+	"regexp"
+	"testing"
+)
+
+func validDeliveryParams() DeliveryParams {
+	return DeliveryParams{
+		Name:    "Ivan Ivanov",
+		Phone:   "+7 (916) 123-45-67",
+		Zip:     " 101000 ",
+		City:    "Moscow",
+		Address: "Tverskaya 1",
+		Region:  "Moscow",
+		Country: "RU",
+		Email:   "Ivan@Example.COM",
+	}
+}
+
+func TestNewDeliveryNormalizesContacts(t *testing.T) {
+	delivery, err := NewDelivery(validDeliveryParams())
+	if err != nil {
+		t.Fatalf("expected err nil, got %v", err)
+	}
+	if delivery.Phone != "+79161234567" {
+		t.Fatalf("expected phone +79161234567, got %s", delivery.Phone)
+	}
+	if delivery.Zip != "101000" {
+		t.Fatalf("expected zip 101000, got %q", delivery.Zip)
+	}
+	if delivery.Email != "Ivan@example.com" {
+		t.Fatalf("expected email Ivan@example.com, got %s", delivery.Email)
+	}
+}
+
+func TestNewDeliveryDefaultCountry(t *testing.T) {
+	params := validDeliveryParams()
+	params.Phone = "8 916 123 45 67"
+
+	if _, err := NewDelivery(params); err == nil {
+		t.Fatalf("expected error for national number without default country")
+	}
+
+	delivery, err := NewDelivery(params, WithDefaultCountry("RU"))
+	if err != nil {
+		t.Fatalf("expected err nil, got %v", err)
+	}
+	if delivery.Phone != "+79161234567" {
+		t.Fatalf("expected phone +79161234567, got %s", delivery.Phone)
+	}
+}
+
+func TestNewDeliveryInvalidContacts(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(p *DeliveryParams)
+		field  string
+	}{
+		{name: "phone with letters", modify: func(p *DeliveryParams) { p.Phone = "+7 916 CALL-ME" }, field: "phone"},
+		{name: "phone too short for country", modify: func(p *DeliveryParams) { p.Phone = "+7916123" }, field: "phone"},
+		{name: "phone too long for E.164", modify: func(p *DeliveryParams) { p.Phone = "+9991234567890123" }, field: "phone"},
+		{name: "zip does not match country", modify: func(p *DeliveryParams) { p.Zip = "10100" }, field: "zip"},
+		{name: "country not a code", modify: func(p *DeliveryParams) { p.Country = "Russia" }, field: "country"},
+		{name: "email without domain", modify: func(p *DeliveryParams) { p.Email = "ivan@" }, field: "email"},
+		{name: "email without tld", modify: func(p *DeliveryParams) { p.Email = "ivan@localhost" }, field: "email"},
+		{name: "email with display name", modify: func(p *DeliveryParams) { p.Email = "Ivan <ivan@example.com>" }, field: "email"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := validDeliveryParams()
+			tt.modify(&params)
+
+			_, err := NewDelivery(params)
+			vErr, ok := AsValidationError(err)
+			if !ok {
+				t.Fatalf("expected ValidationError, got %v", err)
+			}
+			if len(vErr.Violations) != 1 {
+				t.Fatalf("expected 1 violation, got %v", vErr.Violations)
+			}
+			if vErr.Violations[0].Field != tt.field || vErr.Violations[0].Code != CodeInvalidFormat {
+				t.Fatalf("expected %s/%s, got %s/%s", tt.field, CodeInvalidFormat, vErr.Violations[0].Field, vErr.Violations[0].Code)
+			}
+		})
+	}
+}
+
+func TestNewDeliveryUnknownCountryFallsBackToE164(t *testing.T) {
+	params := validDeliveryParams()
+	params.Phone = "+972 000 0000"
+	params.Zip = "2639809"
+	params.Country = ""
+
+	delivery, err := NewDelivery(params)
+	if err != nil {
+		t.Fatalf("expected err nil, got %v", err)
+	}
+	if delivery.Phone != "+9720000000" {
+		t.Fatalf("expected phone +9720000000, got %s", delivery.Phone)
+	}
+}
+
+func TestNewDeliveryCustomCountryRules(t *testing.T) {
+	params := validDeliveryParams()
+	params.Phone = "+972 50 123 4567"
+	params.Zip = "26398"
+	params.Country = "IL"
+
+	il := CountryRules{Country: "IL", CallingCode: "972", TrunkPrefix: "0", MinNationalLen: 8, MaxNationalLen: 9, PostalCode: regexp.MustCompile(`^\d{7}$`)}
+
+	_, err := NewDelivery(params, WithCountryRules(il))
+	vErr, ok := AsValidationError(err)
+	if !ok || vErr.Violations[0].Field != "zip" {
+		t.Fatalf("expected zip violation, got %v", err)
+	}
+
+	params.Zip = "2639809"
+	delivery, err := NewDelivery(params, WithCountryRules(il))
+	if err != nil {
+		t.Fatalf("expected err nil, got %v", err)
+	}
+	if delivery.Phone != "+972501234567" {
+		t.Fatalf("expected phone +972501234567, got %s", delivery.Phone)
+	}
+}
+
+func TestNewDeliveryPostalCountry(t *testing.T) {
+	params := validDeliveryParams()
+	params.Zip = "10001"
+	params.Country = " us "
+
+	// the zip follows the delivery country, not the phone
+	delivery, err := NewDelivery(params)
+	if err != nil {
+		t.Fatalf("expected err nil, got %v", err)
+	}
+	if delivery.Country != "US" {
+		t.Fatalf("expected country US, got %q", delivery.Country)
+	}
+
+	params.Country = ""
+	if _, err := NewDelivery(params); err != nil {
+		t.Fatalf("expected zip not checked without a country, got %v", err)
+	}
+
+	_, err = NewDelivery(params, WithDefaultCountry("RU"))
+	vErr, ok := AsValidationError(err)
+	if !ok || vErr.Violations[0].Field != "zip" {
+		t.Fatalf("expected zip checked against the default country, got %v", err)
+	}
+
+	params.Country = "IL"
+	if _, err := NewDelivery(params, WithDefaultCountry("RU")); err != nil {
+		t.Fatalf("expected zip not checked for a country without rules, got %v", err)
+	}
+}
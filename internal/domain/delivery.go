@@ -1,6 +1,11 @@
 package domain
 
-import "fmt"
+import (
+	"regexp"
+	"strings"
+)
+
+var countryCode = regexp.MustCompile(`^[A-Z]{2}$`)
 
 type Delivery struct {
 	Name    string
@@ -9,6 +14,7 @@ type Delivery struct {
 	City    string
 	Address string
 	Region  string
+	Country string // ISO 3166-1 alpha-2, empty when not given
 	Email   string
 }
 
@@ -19,13 +25,21 @@ type DeliveryParams struct {
 	City    string `json:"city"`
 	Address string `json:"address"`
 	Region  string `json:"region"`
+	Country string `json:"country,omitempty"`
 	Email   string `json:"email"`
 }
 
-func NewDelivery(params DeliveryParams) (*Delivery, error) {
-	if err := validateDeliveryNotEmpty(params); err != nil {
+func NewDelivery(params DeliveryParams, opts ...OrderOption) (*Delivery, error) {
+	options := newOrderOptions(opts)
+	v := &validator{}
+	normalized := validateDelivery(v, "", params, options.contacts)
+	if err := v.err(); err != nil {
 		return nil, err
 	}
+	return newDelivery(normalized), nil
+}
+
+func newDelivery(params DeliveryParams) *Delivery {
 	return &Delivery{
 		Name:    params.Name,
 		Phone:   params.Phone,
@@ -33,26 +47,59 @@ func NewDelivery(params DeliveryParams) (*Delivery, error) {
 		City:    params.City,
 		Address: params.Address,
 		Region:  params.Region,
+		Country: params.Country,
 		Email:   params.Email,
-	}, nil
+	}
 }
 
-func validateDeliveryNotEmpty(params DeliveryParams) error {
-	if params.Phone == "" {
-		return fmt.Errorf("phone is empty: %w", ErrInvalidState)
-	}
-	if params.Zip == "" {
-		return fmt.Errorf("zip is empty: %w", ErrInvalidState)
+// validateDelivery checks contact data and returns it normalized: the phone
+// in E.164 form, the postal code and the country trimmed and upper-cased and
+// the email domain lower-cased. Postal codes are checked against the rules of
+// the delivery country, or of the default country when the delivery has
+// none, and are not checked when that country is unknown.
+func validateDelivery(v *validator, prefix string, params DeliveryParams, contacts contactRules) DeliveryParams {
+	v.required(fieldPath(prefix, "phone"), params.Phone)
+	v.required(fieldPath(prefix, "zip"), params.Zip)
+	v.required(fieldPath(prefix, "city"), params.City)
+	v.required(fieldPath(prefix, "address"), params.Address)
+	v.required(fieldPath(prefix, "region"), params.Region)
+
+	normalized := params
+
+	if params.Phone != "" {
+		phone, _, ok := contacts.normalizePhone(params.Phone)
+		if ok {
+			normalized.Phone = phone
+		} else {
+			v.add(fieldPath(prefix, "phone"), CodeInvalidFormat, "phone %q is not a valid international number", params.Phone)
+		}
 	}
-	if params.City == "" {
-		return fmt.Errorf("city is empty: %w", ErrInvalidState)
+
+	country := contacts.defaultCountry
+	if params.Country != "" {
+		normalized.Country = strings.ToUpper(strings.TrimSpace(params.Country))
+		country = normalized.Country
+		if !countryCode.MatchString(country) {
+			v.add(fieldPath(prefix, "country"), CodeInvalidFormat, "country %q is not an ISO 3166-1 alpha-2 code", params.Country)
+		}
 	}
-	if params.Address == "" {
-		return fmt.Errorf("address is empty: %w", ErrInvalidState)
+
+	if params.Zip != "" {
+		normalized.Zip = normalizePostalCode(params.Zip)
+		rules, ok := contacts.byCountry(country)
+		if ok && rules.PostalCode != nil && !rules.PostalCode.MatchString(normalized.Zip) {
+			v.add(fieldPath(prefix, "zip"), CodeInvalidFormat, "zip %q is not a valid %s postal code", params.Zip, rules.Country)
+		}
 	}
-	if params.Region == "" {
-		return fmt.Errorf("region is empty: %w", ErrInvalidState)
+
+	if params.Email != "" {
+		email, ok := normalizeEmail(params.Email)
+		if ok {
+			normalized.Email = email
+		} else {
+			v.add(fieldPath(prefix, "email"), CodeInvalidFormat, "email %q is not a valid address", params.Email)
+		}
 	}
 
-	return nil
+	return normalized
 }
@@ -0,0 +1,35 @@
+package domain
+
+import "time"
+
+type EventType string
+
+const (
+	EventOrderCreated       EventType = "OrderCreated"
+	EventOrderUpdated       EventType = "OrderUpdated"
+	EventOrderStatusChanged EventType = "OrderStatusChanged"
+)
+
+// OrderEvent is published to downstream consumers whenever a stored order
+// changes. Status changes carry no order body.
+type OrderEvent struct {
+	Type       EventType    `json:"type"`
+	OrderUID   string       `json:"order_uid"`
+	Status     Status       `json:"status"`
+	OccurredAt time.Time    `json:"occurred_at"`
+	Order      *OrderParams `json:"order,omitempty"`
+}
+
+func NewOrderEvent(eventType EventType, o *Order, at time.Time) OrderEvent {
+	e := OrderEvent{
+		Type:       eventType,
+		OrderUID:   o.OrderUID,
+		Status:     o.Status,
+		OccurredAt: at,
+	}
+	if eventType != EventOrderStatusChanged {
+		params := o.Params()
+		e.Order = &params
+	}
+	return e
+}
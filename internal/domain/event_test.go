@@ -0,0 +1,33 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewOrderEvent(t *testing.T) {
+	order, err := NewOrder(consistentOrderParams())
+	if err != nil {
+		t.Fatalf("expected err nil, got %v", err)
+	}
+	at := time.Now()
+
+	created := NewOrderEvent(EventOrderCreated, order, at)
+	if created.Order == nil || created.Order.OrderUID != order.OrderUID {
+		t.Fatalf("expected OrderCreated to carry the order, got %+v", created)
+	}
+	if created.Status != StatusCreated {
+		t.Fatalf("expected status %s, got %s", StatusCreated, created.Status)
+	}
+
+	if err := order.Pay(at); err != nil {
+		t.Fatalf("expected err nil, got %v", err)
+	}
+	changed := NewOrderEvent(EventOrderStatusChanged, order, at)
+	if changed.Order != nil {
+		t.Fatalf("expected OrderStatusChanged without order body, got %+v", changed.Order)
+	}
+	if changed.Status != StatusPaid {
+		t.Fatalf("expected status %s, got %s", StatusPaid, changed.Status)
+	}
+}
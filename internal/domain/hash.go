@@ -0,0 +1,79 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// ContentHash identifies the order payload. Two messages that produce the
+// same normalized order have the same hash; status and storage metadata are
+// not part of it.
+func (o *Order) ContentHash() string {
+	p := o.Params()
+	p.DateCreated = p.DateCreated.UTC()
+	data, _ := json.Marshal(p)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+type FieldDiff struct {
+	Field    string `json:"field"`
+	Stored   any    `json:"stored"`
+	Incoming any    `json:"incoming"`
+}
+
+// DiffParams lists the JSON fields whose values differ between two payloads,
+// using the same paths as validation violations, e.g. items[0].price.
+func DiffParams(stored, incoming OrderParams) []FieldDiff {
+	stored.DateCreated = stored.DateCreated.UTC()
+	incoming.DateCreated = incoming.DateCreated.UTC()
+
+	a, b := flattenParams(stored), flattenParams(incoming)
+
+	fields := make([]string, 0, len(a)+len(b))
+	for f := range a {
+		fields = append(fields, f)
+	}
+	for f := range b {
+		if _, ok := a[f]; !ok {
+			fields = append(fields, f)
+		}
+	}
+	sort.Strings(fields)
+
+	var diff []FieldDiff
+	for _, f := range fields {
+		if fmt.Sprint(a[f]) != fmt.Sprint(b[f]) {
+			diff = append(diff, FieldDiff{Field: f, Stored: a[f], Incoming: b[f]})
+		}
+	}
+	return diff
+}
+
+func flattenParams(p OrderParams) map[string]any {
+	var tree any
+	data, _ := json.Marshal(p)
+	_ = json.Unmarshal(data, &tree)
+
+	out := make(map[string]any)
+	flatten("", tree, out)
+	return out
+}
+
+func flatten(prefix string, v any, out map[string]any) {
+	switch val := v.(type) {
+	case map[string]any:
+		for k, child := range val {
+			flatten(fieldPath(prefix, k), child, out)
+		}
+	case []any:
+		for i, child := range val {
+			flatten(fmt.Sprintf("%s[%d]", prefix, i), child, out)
+		}
+	default:
+		out[prefix] = val
+	}
+}
@@ -0,0 +1,67 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestOrderContentHash(t *testing.T) {
+	params := consistentOrderParams()
+	a, err := NewOrder(params)
+	if err != nil {
+		t.Fatalf("expected err nil, got %v", err)
+	}
+
+	params.DateCreated = params.DateCreated.In(time.FixedZone("MSK", 3*60*60))
+	params.Delivery.Phone = "+972 000 0000"
+	b, err := NewOrder(params)
+	if err != nil {
+		t.Fatalf("expected err nil, got %v", err)
+	}
+	if a.ContentHash() != b.ContentHash() {
+		t.Fatalf("expected equal hashes for equal normalized orders")
+	}
+
+	b.Status = StatusPaid
+	b.DateUpdated = time.Now()
+	if a.ContentHash() != b.ContentHash() {
+		t.Fatalf("expected status and date_updated to be ignored")
+	}
+
+	params.Items[1].Name = "Lip gloss"
+	c, err := NewOrder(params)
+	if err != nil {
+		t.Fatalf("expected err nil, got %v", err)
+	}
+	if a.ContentHash() == c.ContentHash() {
+		t.Fatalf("expected different hashes for different items")
+	}
+}
+
+func TestDiffParams(t *testing.T) {
+	stored := consistentOrderParams()
+	incoming := consistentOrderParams()
+	incoming.Payment.Bank = "sber"
+	incoming.Items = incoming.Items[:1]
+
+	diff := DiffParams(stored, incoming)
+
+	// every field of the dropped item, then the changed bank
+	if len(diff) != 12 {
+		t.Fatalf("expected 12 diffs, got %v", diff)
+	}
+
+	last := diff[len(diff)-1]
+	if last.Field != "payment.bank" || last.Stored != "alpha" || last.Incoming != "sber" {
+		t.Fatalf("expected payment.bank alpha -> sber, got %+v", last)
+	}
+	for _, d := range diff[:len(diff)-1] {
+		if d.Incoming != nil {
+			t.Fatalf("expected removed item field %s to have nil incoming value, got %v", d.Field, d.Incoming)
+		}
+	}
+
+	if diff := DiffParams(stored, consistentOrderParams()); len(diff) != 0 {
+		t.Fatalf("expected no diff, got %v", diff)
+	}
+}
@@ -5,12 +5,12 @@ import "fmt"
 type Item struct {
 	ChrtID      int
 	TrackNumber string
-	Price       int
+	Price       Money
 	Rid         string
 	Name        string
 	Sale        int
 	Size        string
-	TotalPrice  int
+	TotalPrice  Money
 	NmID        int
 	Brand       string
 	Status      int
@@ -30,54 +30,62 @@ type ItemParams struct {
 	Status      int    `json:"status"`
 }
 
-func NewItem(p ItemParams) (*Item, error) {
-	if err := validateItemParams(p); err != nil {
+func NewItem(p ItemParams, currency Currency) (*Item, error) {
+	v := &validator{}
+	validateItem(v, "", p)
+	if err := v.err(); err != nil {
 		return nil, err
 	}
+	return newItem(p, currency), nil
+}
+
+func NewItemList(p []ItemParams, currency Currency) ([]*Item, error) {
+	v := &validator{}
+	validateItems(v, "items", p)
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+	return newItemList(p, currency), nil
+}
+
+func newItem(p ItemParams, currency Currency) *Item {
 	return &Item{
 		ChrtID:      p.ChrtID,
 		TrackNumber: p.TrackNumber,
-		Price:       p.Price,
+		Price:       NewMoney(int64(p.Price), currency),
 		Brand:       p.Brand,
 		Rid:         p.Rid,
 		Name:        p.Name,
 		Sale:        p.Sale,
 		Size:        p.Size,
-		TotalPrice:  p.TotalPrice,
+		TotalPrice:  NewMoney(int64(p.TotalPrice), currency),
 		NmID:        p.NmID,
 		Status:      p.Status,
-	}, nil
+	}
 }
 
-func NewItemList(p []ItemParams) ([]*Item, error) {
-	err := validateItemLength(p)
-	if err != nil {
-		return nil, err
-	}
-	var items []*Item
+func newItemList(p []ItemParams, currency Currency) []*Item {
+	items := make([]*Item, 0, len(p))
 	for _, item := range p {
-		i, err := NewItem(item)
-		if err != nil {
-			return nil, err
-		}
-		items = append(items, i)
+		items = append(items, newItem(item, currency))
 	}
-	return items, nil
+	return items
 }
 
-func validateItemLength(p []ItemParams) error {
+func validateItems(v *validator, prefix string, p []ItemParams) {
 	if len(p) == 0 {
-		return fmt.Errorf("no items : %w", ErrInvalidState)
+		v.add(prefix, CodeRequired, "no items")
+		return
+	}
+	for i, item := range p {
+		validateItem(v, fmt.Sprintf("%s[%d]", prefix, i), item)
 	}
-	return nil
 }
 
-func validateItemParams(p ItemParams) error {
-	if p.Price < 0 {
-		return fmt.Errorf("price is below zero: %w", ErrInvalidState)
-	}
-	if p.TotalPrice < 0 {
-		return fmt.Errorf("price is below zero: %w", ErrInvalidState)
+func validateItem(v *validator, prefix string, p ItemParams) {
+	v.notNegative(fieldPath(prefix, "price"), p.Price)
+	v.notNegative(fieldPath(prefix, "total_price"), p.TotalPrice)
+	if p.Sale < 0 || p.Sale > 100 {
+		v.add(fieldPath(prefix, "sale"), CodeOutOfRange, "sale is out of range 0..100")
 	}
-	return nil
 }
@@ -27,7 +27,7 @@ func TestNewItemListInvalid(t *testing.T) {
 			Sale:        10,
 		},
 	}
-	items, err := NewItemList(itemParams)
+	items, err := NewItemList(itemParams, "USD")
 	if items != nil {
 		t.Fatalf("expected nil, got %v", items)
 	}
@@ -56,7 +56,7 @@ func TestNewItemListValid(t *testing.T) {
 			Status:      202,
 		},
 	}
-	items, err := NewItemList(itemParams)
+	items, err := NewItemList(itemParams, "USD")
 	if items == nil {
 		t.Fatalf("expected not nil, got %v", items)
 	}
@@ -68,7 +68,7 @@ func TestNewItemListValid(t *testing.T) {
 
 func TestNewItemListEmpty(t *testing.T) {
 	var itemParams []ItemParams
-	items, err := NewItemList(itemParams)
+	items, err := NewItemList(itemParams, "USD")
 	if items != nil {
 		t.Fatalf("expected nil, got %v", items)
 	}
@@ -0,0 +1,74 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseCurrency(t *testing.T) {
+	c, err := ParseCurrency(" usd ")
+	if err != nil {
+		t.Fatalf("expected err nil, got %v", err)
+	}
+	if c != "USD" {
+		t.Fatalf("expected USD, got %s", c)
+	}
+
+	for _, code := range []string{"", "US", "XYZ", "dollar"} {
+		if _, err := ParseCurrency(code); !errors.Is(err, ErrInvalidState) {
+			t.Fatalf("%q: expected error to be ErrInvalidState, got %v", code, err)
+		}
+	}
+}
+
+func TestMoneyArithmetic(t *testing.T) {
+	a := NewMoney(1500, "USD")
+	b := NewMoney(317, "USD")
+
+	sum, err := a.Add(b)
+	if err != nil {
+		t.Fatalf("expected err nil, got %v", err)
+	}
+	if sum != NewMoney(1817, "USD") {
+		t.Fatalf("expected 1817 USD, got %v", sum)
+	}
+
+	diff, err := b.Sub(a)
+	if err != nil {
+		t.Fatalf("expected err nil, got %v", err)
+	}
+	if !diff.IsNegative() {
+		t.Fatalf("expected negative, got %v", diff)
+	}
+
+	if _, err := a.Add(NewMoney(1, "EUR")); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Fatalf("expected error to be ErrCurrencyMismatch, got %v", err)
+	}
+	if _, err := SumMoney("USD", a, NewMoney(1, "RUB")); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Fatalf("expected error to be ErrCurrencyMismatch, got %v", err)
+	}
+
+	if got := NewMoney(453, "USD").Discount(30); got != NewMoney(317, "USD") {
+		t.Fatalf("expected 317 USD, got %v", got)
+	}
+}
+
+func TestMoneyFormat(t *testing.T) {
+	tests := []struct {
+		money Money
+		want  string
+	}{
+		{NewMoney(1817, "USD"), "18.17 USD"},
+		{NewMoney(5, "EUR"), "0.05 EUR"},
+		{NewMoney(-120, "RUB"), "-1.20 RUB"},
+		{NewMoney(1817, "JPY"), "1817 JPY"},
+		{NewMoney(1500, "KWD"), "1.500 KWD"},
+		{NewMoney(0, "USD"), "0.00 USD"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.money.String(); got != tt.want {
+			t.Fatalf("expected %s, got %s", tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package domain
+
+type OrderOption func(*orderOptions)
+
+type orderOptions struct {
+	moneyTolerance int
+	contacts       contactRules
+}
+
+// WithMoneyTolerance sets the maximum absolute difference, in the payment's
+// minor units, accepted between a declared amount and the computed one.
+func WithMoneyTolerance(tolerance int) OrderOption {
+	return func(o *orderOptions) {
+		if tolerance > 0 {
+			o.moneyTolerance = tolerance
+		}
+	}
+}
+
+// WithCountryRules adds phone and postal code rules, replacing the built-in
+// rules of the same country.
+func WithCountryRules(rules ...CountryRules) OrderOption {
+	return func(o *orderOptions) {
+		for _, r := range rules {
+			replaced := false
+			for i := range o.contacts.countries {
+				if o.contacts.countries[i].Country == r.Country {
+					o.contacts.countries[i] = r
+					replaced = true
+				}
+			}
+			if !replaced {
+				o.contacts.countries = append(o.contacts.countries, r)
+			}
+		}
+	}
+}
+
+// WithDefaultCountry sets the country assumed for phone numbers written
+// without an international prefix.
+func WithDefaultCountry(country string) OrderOption {
+	return func(o *orderOptions) {
+		o.contacts.defaultCountry = country
+	}
+}
+
+func newOrderOptions(opts []OrderOption) orderOptions {
+	o := orderOptions{
+		contacts: contactRules{countries: DefaultCountryRules()},
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
@@ -2,7 +2,6 @@ package domain
 
 import (
 	"errors"
-	"fmt"
 	"time"
 )
 
@@ -25,7 +24,10 @@ type Order struct {
 	Shardkey          string
 	SmID              int
 	DateCreated       time.Time
+	DateUpdated       time.Time
 	OofShard          string
+	Status            Status
+	StatusHistory     []StatusTransition
 }
 
 type OrderParams struct {
@@ -45,28 +47,32 @@ type OrderParams struct {
 	OofShard          string         `json:"oof_shard"`
 }
 
-func NewOrder(p OrderParams) (*Order, error) {
-	err := validateOrder(p)
-	if err != nil {
-		return nil, err
-	}
-	delivery, err := NewDelivery(p.Delivery)
-	if err != nil {
-		return nil, err
-	}
-	payment, err := NewPayment(p.Payment)
-	if err != nil {
-		return nil, err
+func NewOrder(p OrderParams, opts ...OrderOption) (*Order, error) {
+	options := newOrderOptions(opts)
+
+	v := &validator{}
+	validateOrder(v, p)
+	delivery := validateDelivery(v, "delivery", p.Delivery, options.contacts)
+	validatePayment(v, "payment", p.Payment)
+	validateItems(v, "items", p.Items)
+	var (
+		payment *Payment
+		items   []*Item
+	)
+	if !v.hasPrefix("payment", "items") {
+		payment = newPayment(p.Payment)
+		items = newItemList(p.Items, payment.Currency)
+		validateConsistency(v, payment, items, options.moneyTolerance)
 	}
-	items, err := NewItemList(p.Items)
-	if err != nil {
+	if err := v.err(); err != nil {
 		return nil, err
 	}
+
 	return &Order{
 		OrderUID:          p.OrderUID,
 		TrackNumber:       p.TrackNumber,
 		Entry:             p.Entry,
-		Delivery:          delivery,
+		Delivery:          newDelivery(delivery),
 		Payment:           payment,
 		Items:             items,
 		Locale:            p.Locale,
@@ -77,18 +83,106 @@ func NewOrder(p OrderParams) (*Order, error) {
 		SmID:              p.SmID,
 		DateCreated:       p.DateCreated,
 		OofShard:          p.OofShard,
+		Status:            StatusCreated,
+		StatusHistory:     []StatusTransition{{Status: StatusCreated, ChangedAt: p.DateCreated}},
 	}, nil
 }
 
-func validateOrder(p OrderParams) error {
-	if p.OrderUID == "" {
-		return fmt.Errorf("orderId is missing: %w", ErrInvalidState)
+func validateOrder(v *validator, p OrderParams) {
+	v.required("order_uid", p.OrderUID)
+	v.required("track_number", p.TrackNumber)
+	v.required("customer_id", p.CustomerID)
+}
+
+// Params converts the order back to its input form, e.g. to apply a partial
+// update on top of it.
+func (o *Order) Params() OrderParams {
+	p := OrderParams{
+		OrderUID:          o.OrderUID,
+		TrackNumber:       o.TrackNumber,
+		Entry:             o.Entry,
+		Locale:            o.Locale,
+		InternalSignature: o.InternalSignature,
+		CustomerID:        o.CustomerID,
+		DeliveryService:   o.DeliveryService,
+		Shardkey:          o.Shardkey,
+		SmID:              o.SmID,
+		DateCreated:       o.DateCreated,
+		OofShard:          o.OofShard,
+	}
+	if o.Delivery != nil {
+		p.Delivery = DeliveryParams{
+			Name:    o.Delivery.Name,
+			Phone:   o.Delivery.Phone,
+			Zip:     o.Delivery.Zip,
+			City:    o.Delivery.City,
+			Address: o.Delivery.Address,
+			Region:  o.Delivery.Region,
+			Country: o.Delivery.Country,
+			Email:   o.Delivery.Email,
+		}
+	}
+	if o.Payment != nil {
+		p.Payment = PaymentParams{
+			Transaction:  o.Payment.Transaction,
+			RequestID:    o.Payment.RequestID,
+			Currency:     o.Payment.Currency.String(),
+			Provider:     o.Payment.Provider,
+			Amount:       int(o.Payment.Amount.Amount()),
+			PaymentDt:    o.Payment.PaymentDt,
+			Bank:         o.Payment.Bank,
+			DeliveryCost: int(o.Payment.DeliveryCost.Amount()),
+			GoodsTotal:   int(o.Payment.GoodsTotal.Amount()),
+			CustomFee:    int(o.Payment.CustomFee.Amount()),
+		}
+	}
+	p.Items = make([]ItemParams, 0, len(o.Items))
+	for _, item := range o.Items {
+		p.Items = append(p.Items, ItemParams{
+			ChrtID:      item.ChrtID,
+			TrackNumber: item.TrackNumber,
+			Price:       int(item.Price.Amount()),
+			Rid:         item.Rid,
+			Name:        item.Name,
+			Sale:        item.Sale,
+			Size:        item.Size,
+			TotalPrice:  int(item.TotalPrice.Amount()),
+			NmID:        item.NmID,
+			Brand:       item.Brand,
+			Status:      item.Status,
+		})
+	}
+	return p
+}
+
+// Clone returns a deep copy of the order, which can be changed without
+// affecting o.
+func (o *Order) Clone() *Order {
+	if o == nil {
+		return nil
+	}
+
+	c := *o
+	if o.Delivery != nil {
+		delivery := *o.Delivery
+		c.Delivery = &delivery
+	}
+	if o.Payment != nil {
+		payment := *o.Payment
+		c.Payment = &payment
 	}
-	if p.TrackNumber == "" {
-		return fmt.Errorf("tracknumber is missing: %w", ErrInvalidState)
+	if o.Items != nil {
+		c.Items = make([]*Item, len(o.Items))
+		for i, item := range o.Items {
+			if item != nil {
+				copied := *item
+				c.Items[i] = &copied
+			}
+		}
 	}
-	if p.CustomerID == "" {
-		return fmt.Errorf("customerID is missing: %w", ErrInvalidState)
+	if o.StatusHistory != nil {
+		c.StatusHistory = make([]StatusTransition, len(o.StatusHistory))
+		copy(c.StatusHistory, o.StatusHistory)
 	}
-	return nil
+	return &c
 }
@@ -2,6 +2,7 @@ package domain
 
 import (
 	"errors"
+	"reflect"
 	"testing"
 	"time"
 )
@@ -120,3 +121,49 @@ func TestNewOrderEmptyStructs(t *testing.T) {
 		t.Fatalf("expected error to be ErrInvalidState, got %v", err)
 	}
 }
+
+func TestOrderParamsRoundTrip(t *testing.T) {
+	params := consistentOrderParams()
+	params.Delivery.Phone = "+79161234567"
+	params.Delivery.Zip = "101000"
+
+	order, err := NewOrder(params)
+	if err != nil {
+		t.Fatalf("expected err nil, got %v", err)
+	}
+
+	got := order.Params()
+	if !reflect.DeepEqual(params, got) {
+		t.Fatalf("expected %+v, got %+v", params, got)
+	}
+}
+
+func TestOrderClone(t *testing.T) {
+	order, err := NewOrder(consistentOrderParams())
+	if err != nil {
+		t.Fatalf("expected err nil, got %v", err)
+	}
+	if err := order.Pay(time.Now()); err != nil {
+		t.Fatalf("expected err nil, got %v", err)
+	}
+
+	c := order.Clone()
+	if !reflect.DeepEqual(order, c) {
+		t.Fatalf("expected %+v, got %+v", order, c)
+	}
+
+	c.TrackNumber = "CHANGED"
+	c.Delivery.Name = "Changed"
+	c.Payment.Bank = "changed"
+	c.Items[0].Name = "Changed"
+	c.StatusHistory[0].Status = StatusCancelled
+	if order.TrackNumber == c.TrackNumber || order.Delivery.Name == c.Delivery.Name ||
+		order.Payment.Bank == c.Payment.Bank || order.Items[0].Name == c.Items[0].Name ||
+		order.StatusHistory[0].Status == StatusCancelled {
+		t.Fatalf("changing the clone changed the order: %+v", order)
+	}
+
+	if (*Order)(nil).Clone() != nil {
+		t.Fatalf("expected nil clone of nil order")
+	}
+}
@@ -1,18 +1,16 @@
 package domain
 
-import "fmt"
-
 type Payment struct {
 	Transaction  string
 	RequestID    string
-	Currency     string
+	Currency     Currency
 	Provider     string
-	Amount       int
+	Amount       Money
 	PaymentDt    int
 	Bank         string
-	DeliveryCost int
-	GoodsTotal   int
-	CustomFee    int
+	DeliveryCost Money
+	GoodsTotal   Money
+	CustomFee    Money
 }
 
 type PaymentParams struct {
@@ -29,43 +27,45 @@ type PaymentParams struct {
 }
 
 func NewPayment(params PaymentParams) (*Payment, error) {
-	err := validatePaymentParams(params)
-	if err != nil {
+	v := &validator{}
+	validatePayment(v, "", params)
+	if err := v.err(); err != nil {
 		return nil, err
 	}
+	return newPayment(params), nil
+}
+
+func newPayment(params PaymentParams) *Payment {
+	currency, _ := ParseCurrency(params.Currency)
 	return &Payment{
 		Transaction:  params.Transaction,
 		RequestID:    params.RequestID,
-		Currency:     params.Currency,
+		Currency:     currency,
 		Provider:     params.Provider,
-		Amount:       params.Amount,
+		Amount:       NewMoney(int64(params.Amount), currency),
 		PaymentDt:    params.PaymentDt,
 		Bank:         params.Bank,
-		DeliveryCost: params.DeliveryCost,
-		GoodsTotal:   params.GoodsTotal,
-		CustomFee:    params.CustomFee,
-	}, nil
+		DeliveryCost: NewMoney(int64(params.DeliveryCost), currency),
+		GoodsTotal:   NewMoney(int64(params.GoodsTotal), currency),
+		CustomFee:    NewMoney(int64(params.CustomFee), currency),
+	}
 }
 
-func validatePaymentParams(p PaymentParams) error {
-	if p.Transaction == "" {
-		return fmt.Errorf("transaction %w", ErrInvalidState)
-	}
-	if p.Currency == "" {
-		return fmt.Errorf("currency %w", ErrInvalidState)
-	}
-	if p.Amount < 0 {
-		return fmt.Errorf("amount is below zero: %w", ErrInvalidState)
-	}
-	if p.DeliveryCost < 0 {
-		return fmt.Errorf("deliveryCost is below zero: %w", ErrInvalidState)
-	}
-	if p.GoodsTotal < 0 {
-		return fmt.Errorf("goodsTotal is below zero: %w", ErrInvalidState)
+func validatePayment(v *validator, prefix string, p PaymentParams) {
+	v.required(fieldPath(prefix, "transaction"), p.Transaction)
+	validateCurrency(v, fieldPath(prefix, "currency"), p.Currency)
+	v.notNegative(fieldPath(prefix, "amount"), p.Amount)
+	v.notNegative(fieldPath(prefix, "delivery_cost"), p.DeliveryCost)
+	v.notNegative(fieldPath(prefix, "goods_total"), p.GoodsTotal)
+	v.notNegative(fieldPath(prefix, "custom_fee"), p.CustomFee)
+}
+
+func validateCurrency(v *validator, field, code string) {
+	if code == "" {
+		v.required(field, code)
+		return
 	}
-	if p.CustomFee < 0 {
-		return fmt.Errorf("customFee is below zero: %w", ErrInvalidState)
+	if _, err := ParseCurrency(code); err != nil {
+		v.add(field, CodeInvalidFormat, "%s %q is not an ISO 4217 code", field, code)
 	}
-
-	return nil
 }
@@ -51,3 +51,38 @@ func TestNewPaymentValidParams(t *testing.T) {
 		t.Fatalf("expected err nil, got %v", err)
 	}
 }
+
+func TestNewPaymentUnknownCurrency(t *testing.T) {
+	params := PaymentParams{
+		Transaction: "12345",
+		Currency:    "DOGE",
+		Amount:      100,
+	}
+	payment, err := NewPayment(params)
+	if payment != nil {
+		t.Fatalf("expected nil, got %v", payment)
+	}
+	vErr, ok := AsValidationError(err)
+	if !ok {
+		t.Fatalf("expected ValidationError, got %v", err)
+	}
+	if vErr.Violations[0].Code != CodeInvalidFormat {
+		t.Fatalf("expected code %s, got %s", CodeInvalidFormat, vErr.Violations[0].Code)
+	}
+}
+
+func TestNewPaymentMoney(t *testing.T) {
+	payment, err := NewPayment(PaymentParams{Transaction: "12345", Currency: "eur", Amount: 1050, DeliveryCost: 50})
+	if err != nil {
+		t.Fatalf("expected err nil, got %v", err)
+	}
+	if payment.Currency != "EUR" {
+		t.Fatalf("expected EUR, got %s", payment.Currency)
+	}
+	if payment.Amount != NewMoney(1050, "EUR") {
+		t.Fatalf("expected 1050 EUR, got %v", payment.Amount)
+	}
+	if payment.DeliveryCost.String() != "0.50 EUR" {
+		t.Fatalf("expected 0.50 EUR, got %s", payment.DeliveryCost)
+	}
+}
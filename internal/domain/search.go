@@ -0,0 +1,135 @@
+package domain
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+type SortOrder string
+
+const (
+	SortDesc SortOrder = "desc"
+	SortAsc  SortOrder = "asc"
+)
+
+const (
+	DefaultSearchLimit = 20
+	MaxSearchLimit     = 100
+)
+
+// OrderCursor points at the last order of a page. Orders are paged by
+// (date_created, id), so the cursor stays valid while new orders arrive.
+type OrderCursor struct {
+	DateCreated time.Time `json:"d"`
+	Id          int       `json:"i"`
+}
+
+func (c OrderCursor) String() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func ParseOrderCursor(s string) (OrderCursor, error) {
+	var c OrderCursor
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, fmt.Errorf("invalid cursor: %w", ErrInvalidState)
+	}
+	if err := json.Unmarshal(data, &c); err != nil {
+		return c, fmt.Errorf("invalid cursor: %w", ErrInvalidState)
+	}
+	return c, nil
+}
+
+type OrderFilterParams struct {
+	CustomerID      string
+	TrackNumber     string
+	DeliveryService string
+	Provider        string
+	Currency        string
+	CreatedFrom     time.Time
+	CreatedTo       time.Time
+	Sort            string
+	Limit           int
+	Cursor          string
+}
+
+// OrderFilter selects orders for listing. Empty fields do not filter;
+// CreatedFrom is inclusive and CreatedTo is exclusive. Both are in UTC, like
+// the stored creation dates.
+type OrderFilter struct {
+	CustomerID      string
+	TrackNumber     string
+	DeliveryService string
+	Provider        string
+	Currency        Currency
+	CreatedFrom     time.Time
+	CreatedTo       time.Time
+	Sort            SortOrder
+	Limit           int
+	After           *OrderCursor
+}
+
+// NewOrderFilter validates p. Violations are reported against the query
+// parameter names.
+func NewOrderFilter(p OrderFilterParams) (OrderFilter, error) {
+	v := &validator{}
+	f := OrderFilter{
+		CustomerID:      p.CustomerID,
+		TrackNumber:     p.TrackNumber,
+		DeliveryService: p.DeliveryService,
+		Provider:        p.Provider,
+		CreatedFrom:     p.CreatedFrom.UTC(),
+		CreatedTo:       p.CreatedTo.UTC(),
+		Sort:            SortOrder(p.Sort),
+		Limit:           p.Limit,
+	}
+
+	if p.Currency != "" {
+		currency, err := ParseCurrency(p.Currency)
+		if err != nil {
+			v.add("currency", CodeInvalidFormat, "unknown currency %q", p.Currency)
+		}
+		f.Currency = currency
+	}
+
+	if !p.CreatedFrom.IsZero() && !p.CreatedTo.IsZero() && !p.CreatedFrom.Before(p.CreatedTo) {
+		v.add("created_to", CodeOutOfRange, "created_to must be after created_from")
+	}
+
+	switch f.Sort {
+	case "":
+		f.Sort = SortDesc
+	case SortAsc, SortDesc:
+	default:
+		v.add("sort", CodeInvalidFormat, "sort must be %s or %s", SortAsc, SortDesc)
+	}
+
+	switch {
+	case f.Limit == 0:
+		f.Limit = DefaultSearchLimit
+	case f.Limit < 0 || f.Limit > MaxSearchLimit:
+		v.add("limit", CodeOutOfRange, "limit must be between 1 and %d", MaxSearchLimit)
+	}
+
+	if p.Cursor != "" {
+		cursor, err := ParseOrderCursor(p.Cursor)
+		if err != nil {
+			v.add("cursor", CodeInvalidFormat, "cursor is malformed")
+		}
+		f.After = &cursor
+	}
+
+	if err := v.err(); err != nil {
+		return OrderFilter{}, err
+	}
+	return f, nil
+}
+
+type OrderPage struct {
+	Orders []*Order
+	// NextCursor is empty on the last page.
+	NextCursor string
+}
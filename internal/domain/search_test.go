@@ -0,0 +1,72 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestOrderCursorRoundTrip(t *testing.T) {
+	c := OrderCursor{DateCreated: time.Date(2021, 11, 26, 6, 22, 19, 0, time.UTC), Id: 42}
+
+	got, err := ParseOrderCursor(c.String())
+	if err != nil {
+		t.Fatalf("expected err nil, got %v", err)
+	}
+	if got.Id != c.Id || !got.DateCreated.Equal(c.DateCreated) {
+		t.Fatalf("expected %+v, got %+v", c, got)
+	}
+
+	if _, err := ParseOrderCursor("not a cursor"); err == nil {
+		t.Fatalf("expected error for malformed cursor")
+	}
+}
+
+func TestNewOrderFilterDefaults(t *testing.T) {
+	f, err := NewOrderFilter(OrderFilterParams{Currency: "usd"})
+	if err != nil {
+		t.Fatalf("expected err nil, got %v", err)
+	}
+	if f.Sort != SortDesc || f.Limit != DefaultSearchLimit || f.Currency != "USD" || f.After != nil {
+		t.Fatalf("unexpected filter %+v", f)
+	}
+}
+
+func TestNewOrderFilterUTC(t *testing.T) {
+	from := time.Date(2025, 10, 1, 12, 0, 0, 0, time.FixedZone("MSK", 3*60*60))
+	f, err := NewOrderFilter(OrderFilterParams{CreatedFrom: from})
+	if err != nil {
+		t.Fatalf("expected err nil, got %v", err)
+	}
+	if f.CreatedFrom != time.Date(2025, 10, 1, 9, 0, 0, 0, time.UTC) {
+		t.Fatalf("expected created_from in UTC, got %v", f.CreatedFrom)
+	}
+	if !f.CreatedTo.IsZero() {
+		t.Fatalf("expected empty created_to, got %v", f.CreatedTo)
+	}
+}
+
+func TestNewOrderFilterInvalid(t *testing.T) {
+	now := time.Now()
+	_, err := NewOrderFilter(OrderFilterParams{
+		Currency:    "XYZ",
+		CreatedFrom: now,
+		CreatedTo:   now.Add(-time.Hour),
+		Sort:        "up",
+		Limit:       MaxSearchLimit + 1,
+		Cursor:      "%%%",
+	})
+
+	vErr, ok := AsValidationError(err)
+	if !ok {
+		t.Fatalf("expected ValidationError, got %v", err)
+	}
+	want := []string{"currency", "created_to", "sort", "limit", "cursor"}
+	if len(vErr.Violations) != len(want) {
+		t.Fatalf("expected %d violations, got %v", len(want), vErr.Violations)
+	}
+	for i, field := range want {
+		if vErr.Violations[i].Field != field {
+			t.Fatalf("expected violation %d on %s, got %s", i, field, vErr.Violations[i].Field)
+		}
+	}
+}
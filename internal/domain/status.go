@@ -0,0 +1,96 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+type Status string
+
+const (
+	StatusCreated    Status = "created"
+	StatusPaid       Status = "paid"
+	StatusAssembling Status = "assembling"
+	StatusShipped    Status = "shipped"
+	StatusDelivered  Status = "delivered"
+	StatusCancelled  Status = "cancelled"
+	StatusReturned   Status = "returned"
+)
+
+var statusTransitions = map[Status][]Status{
+	StatusCreated:    {StatusPaid, StatusCancelled},
+	StatusPaid:       {StatusAssembling, StatusCancelled},
+	StatusAssembling: {StatusShipped, StatusCancelled},
+	StatusShipped:    {StatusDelivered, StatusReturned},
+	StatusDelivered:  {StatusReturned},
+	StatusCancelled:  {},
+	StatusReturned:   {},
+}
+
+type StatusTransition struct {
+	Status    Status
+	ChangedAt time.Time
+}
+
+func ParseStatus(s string) (Status, error) {
+	status := Status(s)
+	if _, ok := statusTransitions[status]; !ok {
+		return "", fmt.Errorf("unknown status %q: %w", s, ErrInvalidState)
+	}
+	return status, nil
+}
+
+func (s Status) CanTransitionTo(next Status) bool {
+	for _, allowed := range statusTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+func (s Status) IsFinal() bool {
+	return len(statusTransitions[s]) == 0
+}
+
+func (o *Order) TransitionTo(next Status, at time.Time) error {
+	if !o.Status.CanTransitionTo(next) {
+		return fmt.Errorf("transition %s -> %s is not allowed: %w", o.Status, next, ErrInvalidState)
+	}
+	o.Status = next
+	o.StatusHistory = append(o.StatusHistory, StatusTransition{Status: next, ChangedAt: at})
+	return nil
+}
+
+func (o *Order) Pay(at time.Time) error {
+	return o.TransitionTo(StatusPaid, at)
+}
+
+func (o *Order) StartAssembly(at time.Time) error {
+	return o.TransitionTo(StatusAssembling, at)
+}
+
+func (o *Order) Ship(at time.Time) error {
+	return o.TransitionTo(StatusShipped, at)
+}
+
+func (o *Order) Deliver(at time.Time) error {
+	return o.TransitionTo(StatusDelivered, at)
+}
+
+func (o *Order) Cancel(at time.Time) error {
+	return o.TransitionTo(StatusCancelled, at)
+}
+
+func (o *Order) Return(at time.Time) error {
+	return o.TransitionTo(StatusReturned, at)
+}
+
+func (o *Order) StatusChangedAt(status Status) (time.Time, bool) {
+	for i := len(o.StatusHistory) - 1; i >= 0; i-- {
+		if o.StatusHistory[i].Status == status {
+			return o.StatusHistory[i].ChangedAt, true
+		}
+	}
+	return time.Time{}, false
+}
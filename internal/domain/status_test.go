@@ -0,0 +1,78 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestOrderTransitionHappyPath(t *testing.T) {
+	created := time.Date(2021, 11, 26, 6, 22, 0, 0, time.UTC)
+	order := &Order{
+		Status:        StatusCreated,
+		StatusHistory: []StatusTransition{{Status: StatusCreated, ChangedAt: created}},
+	}
+
+	steps := []func(time.Time) error{order.Pay, order.StartAssembly, order.Ship, order.Deliver, order.Return}
+	for i, step := range steps {
+		if err := step(created.Add(time.Duration(i+1) * time.Hour)); err != nil {
+			t.Fatalf("step %d: expected err nil, got %v", i, err)
+		}
+	}
+
+	if order.Status != StatusReturned {
+		t.Fatalf("expected status %s, got %s", StatusReturned, order.Status)
+	}
+	if len(order.StatusHistory) != 6 {
+		t.Fatalf("expected 6 transitions, got %d", len(order.StatusHistory))
+	}
+	shippedAt, ok := order.StatusChangedAt(StatusShipped)
+	if !ok || !shippedAt.Equal(created.Add(3*time.Hour)) {
+		t.Fatalf("expected shipped at %v, got %v", created.Add(3*time.Hour), shippedAt)
+	}
+}
+
+func TestOrderTransitionInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		from Status
+		to   Status
+	}{
+		{name: "created to shipped", from: StatusCreated, to: StatusShipped},
+		{name: "paid to delivered", from: StatusPaid, to: StatusDelivered},
+		{name: "shipped to cancelled", from: StatusShipped, to: StatusCancelled},
+		{name: "cancelled is final", from: StatusCancelled, to: StatusPaid},
+		{name: "returned is final", from: StatusReturned, to: StatusShipped},
+		{name: "same status", from: StatusPaid, to: StatusPaid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order := &Order{Status: tt.from}
+			err := order.TransitionTo(tt.to, time.Now())
+			if !errors.Is(err, ErrInvalidState) {
+				t.Fatalf("expected error to be ErrInvalidState, got %v", err)
+			}
+			if order.Status != tt.from {
+				t.Fatalf("expected status to stay %s, got %s", tt.from, order.Status)
+			}
+			if len(order.StatusHistory) != 0 {
+				t.Fatalf("expected no transitions recorded, got %d", len(order.StatusHistory))
+			}
+		})
+	}
+}
+
+func TestParseStatus(t *testing.T) {
+	status, err := ParseStatus("assembling")
+	if err != nil {
+		t.Fatalf("expected err nil, got %v", err)
+	}
+	if status != StatusAssembling {
+		t.Fatalf("expected %s, got %s", StatusAssembling, status)
+	}
+
+	if _, err := ParseStatus("lost"); !errors.Is(err, ErrInvalidState) {
+		t.Fatalf("expected error to be ErrInvalidState, got %v", err)
+	}
+}
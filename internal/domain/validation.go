@@ -0,0 +1,109 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+type ViolationCode string
+
+const (
+	CodeRequired      ViolationCode = "required"
+	CodeBelowZero     ViolationCode = "below_zero"
+	CodeOutOfRange    ViolationCode = "out_of_range"
+	CodeInconsistent  ViolationCode = "inconsistent"
+	CodeInvalidFormat ViolationCode = "invalid_format"
+)
+
+type Violation struct {
+	Field   string        `json:"field"`
+	Code    ViolationCode `json:"code"`
+	Message string        `json:"message"`
+}
+
+// ValidationError reports every rule an input breaks. It matches
+// ErrInvalidState with errors.Is, and ErrInconsistentAmounts when one of the
+// violations is about money math.
+type ValidationError struct {
+	Violations []Violation
+}
+
+func (e *ValidationError) Error() string {
+	parts := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		parts = append(parts, fmt.Sprintf("%s: %s", v.Field, v.Message))
+	}
+	return fmt.Sprintf("%s: %s", ErrInvalidState, strings.Join(parts, "; "))
+}
+
+func (e *ValidationError) Is(target error) bool {
+	switch target {
+	case ErrInvalidState:
+		return true
+	case ErrInconsistentAmounts:
+		for _, v := range e.Violations {
+			if v.Code == CodeInconsistent {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func AsValidationError(err error) (*ValidationError, bool) {
+	var vErr *ValidationError
+	if errors.As(err, &vErr) {
+		return vErr, true
+	}
+	return nil, false
+}
+
+type validator struct {
+	violations []Violation
+}
+
+func (v *validator) add(field string, code ViolationCode, format string, args ...any) {
+	v.violations = append(v.violations, Violation{
+		Field:   field,
+		Code:    code,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+func (v *validator) required(field, value string) {
+	if value == "" {
+		v.add(field, CodeRequired, "%s is empty", field)
+	}
+}
+
+func (v *validator) notNegative(field string, value int) {
+	if value < 0 {
+		v.add(field, CodeBelowZero, "%s is below zero", field)
+	}
+}
+
+func (v *validator) hasPrefix(prefixes ...string) bool {
+	for _, violation := range v.violations {
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(violation.Field, prefix) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (v *validator) err() error {
+	if len(v.violations) == 0 {
+		return nil
+	}
+	return &ValidationError{Violations: v.violations}
+}
+
+func fieldPath(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
@@ -0,0 +1,87 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNewOrderCollectsAllViolations(t *testing.T) {
+	params := consistentOrderParams()
+	params.CustomerID = ""
+	params.Delivery.Zip = ""
+	params.Payment.Currency = ""
+	params.Items[1].Price = -10
+	params.Items[1].Sale = 120
+
+	order, err := NewOrder(params)
+	if order != nil {
+		t.Fatalf("expected nil, got %v", order)
+	}
+	if !errors.Is(err, ErrInvalidState) {
+		t.Fatalf("expected error to be ErrInvalidState, got %v", err)
+	}
+
+	vErr, ok := AsValidationError(err)
+	if !ok {
+		t.Fatalf("expected ValidationError, got %T", err)
+	}
+
+	want := []Violation{
+		{Field: "customer_id", Code: CodeRequired},
+		{Field: "delivery.zip", Code: CodeRequired},
+		{Field: "payment.currency", Code: CodeRequired},
+		{Field: "items[1].price", Code: CodeBelowZero},
+		{Field: "items[1].sale", Code: CodeOutOfRange},
+	}
+	if len(vErr.Violations) != len(want) {
+		t.Fatalf("expected %d violations, got %d: %v", len(want), len(vErr.Violations), vErr.Violations)
+	}
+	for i, w := range want {
+		got := vErr.Violations[i]
+		if got.Field != w.Field || got.Code != w.Code {
+			t.Fatalf("violation %d: expected %s/%s, got %s/%s", i, w.Field, w.Code, got.Field, got.Code)
+		}
+	}
+	if errors.Is(err, ErrInconsistentAmounts) {
+		t.Fatalf("expected no consistency check while payment and items are invalid")
+	}
+}
+
+func TestNewOrderReportsAllInconsistencies(t *testing.T) {
+	params := consistentOrderParams()
+	params.Items[0].TotalPrice = 453
+	params.Payment.Amount = 1
+
+	_, err := NewOrder(params)
+	vErr, ok := AsValidationError(err)
+	if !ok {
+		t.Fatalf("expected ValidationError, got %T", err)
+	}
+
+	fields := map[string]bool{}
+	for _, v := range vErr.Violations {
+		if v.Code != CodeInconsistent {
+			t.Fatalf("expected code %s, got %s", CodeInconsistent, v.Code)
+		}
+		fields[v.Field] = true
+	}
+	for _, f := range []string{"items[0].total_price", "payment.goods_total", "payment.amount"} {
+		if !fields[f] {
+			t.Fatalf("expected violation for %s, got %v", f, vErr.Violations)
+		}
+	}
+}
+
+func TestNewDeliveryFieldPaths(t *testing.T) {
+	_, err := NewDelivery(DeliveryParams{Phone: "+9720000000", City: "Kiryat Mozkin"})
+	vErr, ok := AsValidationError(err)
+	if !ok {
+		t.Fatalf("expected ValidationError, got %T", err)
+	}
+	if len(vErr.Violations) != 3 {
+		t.Fatalf("expected 3 violations, got %v", vErr.Violations)
+	}
+	if vErr.Violations[0].Field != "zip" {
+		t.Fatalf("expected field zip, got %s", vErr.Violations[0].Field)
+	}
+}
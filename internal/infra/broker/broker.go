@@ -29,7 +29,12 @@ func (b *Broker) Run(ctx context.Context) {
 
 	b.wg.Add(2)
 	go b.runOrders(ctx)
-	go b.runRetries(ctx)
+	go b.runUpdates(ctx)
+
+	for tier := range b.consumer.RetryTiers() {
+		b.wg.Add(1)
+		go b.runRetries(ctx, tier)
+	}
 
 }
 
@@ -42,36 +47,24 @@ func (b *Broker) Shutdown() error {
 func (b *Broker) runOrders(ctx context.Context) {
 	defer b.wg.Done()
 
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		default:
-		}
-
-		err := b.consumer.ReadOrderMsg(ctx)
-		if err == nil {
-			continue
-		}
-
-		if b.retryableErr(err) {
-			select {
-			case <-time.After(time.Second):
-			case <-ctx.Done():
-				return
-			}
-			b.logger.Error("retry error", "err", err)
+	b.consume(ctx, b.consumer.ReadOrderMsg)
+}
 
-			continue
-		}
+func (b *Broker) runUpdates(ctx context.Context) {
+	defer b.wg.Done()
 
-		b.logger.Error("not kafka temporary error", "err", err)
-	}
+	b.consume(ctx, b.consumer.ReadUpdateMsg)
 }
 
-func (b *Broker) runRetries(ctx context.Context) {
+func (b *Broker) runRetries(ctx context.Context, tier int) {
 	defer b.wg.Done()
 
+	b.consume(ctx, func(ctx context.Context) error {
+		return b.consumer.ReadRetryMsg(ctx, tier)
+	})
+}
+
+func (b *Broker) consume(ctx context.Context, read func(ctx context.Context) error) {
 	for {
 		select {
 		case <-ctx.Done():
@@ -79,7 +72,7 @@ func (b *Broker) runRetries(ctx context.Context) {
 		default:
 		}
 
-		err := b.consumer.ReadRetryMsg(ctx)
+		err := read(ctx)
 		if err == nil {
 			continue
 		}
@@ -97,7 +90,6 @@ func (b *Broker) runRetries(ctx context.Context) {
 
 		b.logger.Error("not kafka temporary error", "err", err)
 	}
-
 }
 
 func (b *Broker) retryableErr(err error) bool {
@@ -5,7 +5,11 @@ import "context"
 type Consumer interface {
 	Init() error
 	ReadOrderMsg(ctx context.Context) error
-	ReadRetryMsg(ctx context.Context) error
+	ReadUpdateMsg(ctx context.Context) error
+	// ReadRetryMsg reads the retry tier with the given index, from 0 up to
+	// RetryTiers.
+	ReadRetryMsg(ctx context.Context, tier int) error
+	RetryTiers() int
 	ShutDown() error
 	Ready() <-chan struct{}
 }
@@ -4,8 +4,10 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"log/slog"
 	"order-service/internal/domain"
+	"order-service/internal/infra/repo"
 	"order-service/internal/usecase"
 )
 
@@ -21,6 +23,9 @@ func NewMessageProcessor(useCase OrderCreatorUseCase, logger *slog.Logger) *Mess
 	}
 }
 
+// ErrMalformedMessage wraps the errors of messages that are not valid order JSON.
+var ErrMalformedMessage = errors.New("malformed message")
+
 type Result int
 
 const (
@@ -29,21 +34,107 @@ const (
 	DLQ
 )
 
-func (p *MessageProcessor) ProcessOrderMessage(ctx context.Context, data []byte) Result {
+func (r Result) String() string {
+	switch r {
+	case Success:
+		return "success"
+	case Retry:
+		return "retry"
+	case DLQ:
+		return "dlq"
+	default:
+		return fmt.Sprintf("result(%d)", int(r))
+	}
+}
+
+func (p *MessageProcessor) ProcessOrderMessage(ctx context.Context, data []byte) (Result, error) {
 	var params domain.OrderParams
 	if err := json.Unmarshal(data, &params); err != nil {
 		p.logger.Error("failed to unmarshal order message", "error", err)
-		return DLQ
+		return DLQ, fmt.Errorf("%w: %w", ErrMalformedMessage, err)
 	}
 	if err := p.useCase.CreateOrder(ctx, params); err != nil {
+		if errors.Is(err, usecase.ErrDuplicateOrder) {
+			p.logger.Info("duplicate order message acknowledged", "order_uid", params.OrderUID)
+			return Success, nil
+		}
+		if errors.Is(err, repo.ErrAlreadyConsumed) {
+			p.logger.Info("consumed order message acknowledged", "order_uid", params.OrderUID)
+			return Success, nil
+		}
 		p.logger.Error("failed to create order", "error", err, "order_uid", params.OrderUID)
 		if p.shouldRetryErr(err) {
-			return Retry
+			return Retry, err
 		}
-		return DLQ
+		return DLQ, err
 	}
 	p.logger.Info("order processed successfully", "order_uid", params.OrderUID)
-	return Success
+	return Success, nil
+}
+
+// ProcessOrderBatch creates the orders of a batch of messages at once. It
+// returns a result and an error for every message, in the same order.
+func (p *MessageProcessor) ProcessOrderBatch(ctx context.Context, batch [][]byte) ([]Result, []error) {
+	results := make([]Result, len(batch))
+	errs := make([]error, len(batch))
+
+	params := make([]domain.OrderParams, 0, len(batch))
+	indexes := make([]int, 0, len(batch))
+	for i, data := range batch {
+		var order domain.OrderParams
+		if err := json.Unmarshal(data, &order); err != nil {
+			p.logger.Error("failed to unmarshal order message", "error", err)
+			results[i], errs[i] = DLQ, fmt.Errorf("%w: %w", ErrMalformedMessage, err)
+			continue
+		}
+		params = append(params, order)
+		indexes = append(indexes, i)
+	}
+
+	createErrs := p.useCase.CreateOrders(ctx, params)
+	for j, err := range createErrs {
+		i := indexes[j]
+		uid := params[j].OrderUID
+		switch {
+		case err == nil:
+			results[i] = Success
+		case errors.Is(err, usecase.ErrDuplicateOrder):
+			p.logger.Info("duplicate order message acknowledged", "order_uid", uid)
+			results[i] = Success
+		case p.shouldRetryErr(err):
+			p.logger.Error("failed to create order", "error", err, "order_uid", uid)
+			results[i], errs[i] = Retry, err
+		default:
+			p.logger.Error("failed to create order", "error", err, "order_uid", uid)
+			results[i], errs[i] = DLQ, err
+		}
+	}
+	p.logger.Info("order batch processed", "size", len(batch))
+
+	return results, errs
+}
+
+// ProcessUpdateMessage applies an order update. An update for an order that
+// is not stored yet is retried, since its create message may still be in flight.
+func (p *MessageProcessor) ProcessUpdateMessage(ctx context.Context, data []byte) (Result, error) {
+	var params domain.OrderParams
+	if err := json.Unmarshal(data, &params); err != nil {
+		p.logger.Error("failed to unmarshal order update message", "error", err)
+		return DLQ, fmt.Errorf("%w: %w", ErrMalformedMessage, err)
+	}
+	if _, err := p.useCase.UpdateOrder(ctx, params); err != nil {
+		if errors.Is(err, repo.ErrAlreadyConsumed) {
+			p.logger.Info("consumed order update message acknowledged", "order_uid", params.OrderUID)
+			return Success, nil
+		}
+		p.logger.Error("failed to update order", "error", err, "order_uid", params.OrderUID)
+		if p.shouldRetryErr(err) {
+			return Retry, err
+		}
+		return DLQ, err
+	}
+	p.logger.Info("order updated successfully", "order_uid", params.OrderUID)
+	return Success, nil
 }
 
 func (p *MessageProcessor) shouldRetryErr(err error) bool {
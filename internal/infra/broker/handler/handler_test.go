@@ -3,14 +3,18 @@ package handler
 import (
 	"context"
 	"errors"
+	"fmt"
 	"order-service/internal/domain"
+	"order-service/internal/infra/repo"
 	"order-service/internal/lib/logger"
+	"order-service/internal/usecase"
 	"testing"
 )
 
 type MockUseCase struct {
-	called bool
-	error  error
+	called    bool
+	error     error
+	batchErrs map[string]error
 }
 
 func (m *MockUseCase) CreateOrder(ctx context.Context, params domain.OrderParams) error {
@@ -18,6 +22,23 @@ func (m *MockUseCase) CreateOrder(ctx context.Context, params domain.OrderParams
 	return m.error
 }
 
+func (m *MockUseCase) CreateOrders(ctx context.Context, params []domain.OrderParams) []error {
+	m.called = true
+	errs := make([]error, len(params))
+	for i, p := range params {
+		errs[i] = m.batchErrs[p.OrderUID]
+	}
+	return errs
+}
+
+func (m *MockUseCase) UpdateOrder(ctx context.Context, params domain.OrderParams) (*domain.Order, error) {
+	m.called = true
+	if m.error != nil {
+		return nil, m.error
+	}
+	return &domain.Order{OrderUID: params.OrderUID}, nil
+}
+
 func TestProcessOrderMessage(t *testing.T) {
 	logger, err := logger.InitLogger("test")
 	if err != nil {
@@ -55,6 +76,34 @@ func TestProcessOrderMessage(t *testing.T) {
 			wantResult: DLQ,
 			wantCalled: true,
 		},
+		{
+			name:       "inconsistent amounts - non-retryable",
+			input:      []byte(`{"order_uid": "12345"}`),
+			mockErr:    fmt.Errorf("amount 10, expected 20: %w", domain.ErrInconsistentAmounts),
+			wantResult: DLQ,
+			wantCalled: true,
+		},
+		{
+			name:       "exact duplicate - acknowledged",
+			input:      []byte(`{"order_uid": "12345"}`),
+			mockErr:    usecase.ErrDuplicateOrder,
+			wantResult: Success,
+			wantCalled: true,
+		},
+		{
+			name:       "already consumed - acknowledged",
+			input:      []byte(`{"order_uid": "12345"}`),
+			mockErr:    fmt.Errorf("orders/0/7: %w", repo.ErrAlreadyConsumed),
+			wantResult: Success,
+			wantCalled: true,
+		},
+		{
+			name:       "conflicting duplicate - non-retryable",
+			input:      []byte(`{"order_uid": "12345"}`),
+			mockErr:    &usecase.ConflictError{OrderUID: "12345"},
+			wantResult: DLQ,
+			wantCalled: true,
+		},
 		{
 			name:       "retryable error",
 			input:      []byte(`{"order_uid": "12345"}`),
@@ -72,14 +121,89 @@ func TestProcessOrderMessage(t *testing.T) {
 			}
 			processor := NewMessageProcessor(uc, logger)
 
-			res := processor.ProcessOrderMessage(context.Background(), tt.input)
+			res, err := processor.ProcessOrderMessage(context.Background(), tt.input)
 
 			if res != tt.wantResult {
 				t.Fatalf("expected result %v, got %v", tt.wantResult, res)
 			}
+			if (res == Success) != (err == nil) {
+				t.Fatalf("expected error only for non-success result, got %v", err)
+			}
 			if uc.called != tt.wantCalled {
 				t.Fatalf("expected use case called %v, got %v", tt.wantCalled, uc.called)
 			}
 		})
 	}
 }
+
+func TestProcessUpdateMessage(t *testing.T) {
+	logger, err := logger.InitLogger("test")
+	if err != nil {
+		t.Fatalf("expected logger not nil, got %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		input      []byte
+		mockErr    error
+		wantResult Result
+	}{
+		{name: "valid update", input: []byte(`{"order_uid": "12345"}`), wantResult: Success},
+		{name: "invalid JSON", input: []byte(`{"order_uid":`), wantResult: DLQ},
+		{name: "invalid update", input: []byte(`{"order_uid": "12345"}`), mockErr: domain.ErrInvalidState, wantResult: DLQ},
+		{name: "order not stored yet", input: []byte(`{"order_uid": "12345"}`), mockErr: repo.ErrNotFound, wantResult: Retry},
+		{name: "already consumed", input: []byte(`{"order_uid": "12345"}`), mockErr: repo.ErrAlreadyConsumed, wantResult: Success},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			processor := NewMessageProcessor(&MockUseCase{error: tt.mockErr}, logger)
+
+			res, _ := processor.ProcessUpdateMessage(context.Background(), tt.input)
+			if res != tt.wantResult {
+				t.Fatalf("expected result %v, got %v", tt.wantResult, res)
+			}
+		})
+	}
+}
+
+func TestProcessOrderBatch(t *testing.T) {
+	logger, err := logger.InitLogger("test")
+	if err != nil {
+		t.Fatalf("expected logger not nil, got %v", err)
+	}
+
+	uc := &MockUseCase{batchErrs: map[string]error{
+		"duplicate": usecase.ErrDuplicateOrder,
+		"invalid":   domain.ErrInvalidState,
+		"network":   errors.New("network error"),
+	}}
+	processor := NewMessageProcessor(uc, logger)
+
+	results, errs := processor.ProcessOrderBatch(context.Background(), [][]byte{
+		[]byte(`{"order_uid": "ok"}`),
+		[]byte(`{"invalid_json":`),
+		[]byte(`{"order_uid": "duplicate"}`),
+		[]byte(`{"order_uid": "invalid"}`),
+		[]byte(`{"order_uid": "network"}`),
+	})
+
+	want := []Result{Success, DLQ, Success, DLQ, Retry}
+	if len(results) != len(want) || len(errs) != len(want) {
+		t.Fatalf("expected %d results and errors, got %v and %v", len(want), results, errs)
+	}
+	for i := range want {
+		if results[i] != want[i] {
+			t.Fatalf("message %d: expected result %v, got %v", i, want[i], results[i])
+		}
+		if (results[i] == Success) != (errs[i] == nil) {
+			t.Fatalf("message %d: expected error only for non-success result, got %v", i, errs[i])
+		}
+	}
+	if !errors.Is(errs[1], ErrMalformedMessage) {
+		t.Fatalf("expected malformed message error, got %v", errs[1])
+	}
+	if errors.Is(errs[3], ErrMalformedMessage) || !errors.Is(errs[3], domain.ErrInvalidState) {
+		t.Fatalf("expected invalid state error, got %v", errs[3])
+	}
+}
@@ -7,4 +7,6 @@ import (
 
 type OrderCreatorUseCase interface {
 	CreateOrder(ctx context.Context, params domain.OrderParams) error
+	CreateOrders(ctx context.Context, params []domain.OrderParams) []error
+	UpdateOrder(ctx context.Context, params domain.OrderParams) (*domain.Order, error)
 }
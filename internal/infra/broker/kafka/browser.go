@@ -0,0 +1,168 @@
+package kafka
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"slices"
+	"time"
+)
+
+const (
+	DefaultDLQPageLimit = 20
+	MaxDLQPageLimit     = 100
+	// maxCountScan caps the messages read to count one time window.
+	maxCountScan = 100000
+	// maxPageScan caps the messages read for one page, so a filter that
+	// matches few messages does not read the whole topic at once.
+	maxPageScan = 10000
+)
+
+var ErrInvalidDLQCursor = errors.New("invalid dlq cursor")
+
+// DLQPosition is the position of a message in the DLQ topic. Pages run
+// through the partitions in order and through each partition by offset.
+type DLQPosition struct {
+	Partition int   `json:"p"`
+	Offset    int64 `json:"o"`
+}
+
+func (p DLQPosition) String() string {
+	data, _ := json.Marshal(p)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func ParseDLQPosition(s string) (DLQPosition, error) {
+	var p DLQPosition
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return p, ErrInvalidDLQCursor
+	}
+	if err := json.Unmarshal(data, &p); err != nil || p.Partition < 0 || p.Offset < 0 {
+		return p, ErrInvalidDLQCursor
+	}
+	return p, nil
+}
+
+type DLQPageQuery struct {
+	// Start is the first position of the page, the beginning of the topic
+	// when nil.
+	Start  *DLQPosition
+	From   time.Time
+	To     time.Time
+	Filter DLQFilter
+	Limit  int
+}
+
+type DLQPage struct {
+	Letters []DeadLetter
+	// Next is nil on the last page.
+	Next *DLQPosition
+}
+
+type DLQCounts struct {
+	Total        int                `json:"total"`
+	ByErrorClass map[ErrorClass]int `json:"by_error_class"`
+	ByReason     map[string]int     `json:"by_reason"`
+	// Truncated tells that the window has more than maxCountScan messages
+	// and only the first ones were counted.
+	Truncated bool `json:"truncated"`
+}
+
+type dlqPartitionSource interface {
+	dlqSource
+	Partitions(ctx context.Context) ([]int, error)
+}
+
+// DLQBrowser pages through the DLQ topic for inspection. It only reads.
+type DLQBrowser struct {
+	source dlqPartitionSource
+}
+
+func NewDLQBrowser(reader *DLQReader) *DLQBrowser {
+	return &DLQBrowser{source: reader}
+}
+
+// Page returns up to q.Limit messages matching q from q.Start on. It stops
+// after reading maxPageScan messages, so a page may hold fewer messages than
+// the limit and still have a next one.
+func (b *DLQBrowser) Page(ctx context.Context, q DLQPageQuery) (DLQPage, error) {
+	limit := q.Limit
+	if limit <= 0 || limit > MaxDLQPageLimit {
+		limit = DefaultDLQPageLimit
+	}
+
+	partitions, err := b.source.Partitions(ctx)
+	if err != nil {
+		return DLQPage{}, err
+	}
+	slices.Sort(partitions)
+
+	page := DLQPage{Letters: []DeadLetter{}}
+	scanned := 0
+	for _, partition := range partitions {
+		rng := DLQRange{Partitions: []int{partition}, From: q.From, To: q.To}
+		if q.Start != nil {
+			if partition < q.Start.Partition {
+				continue
+			}
+			if partition == q.Start.Partition {
+				rng.FromOffset = q.Start.Offset
+			}
+		}
+
+		err := b.source.Read(ctx, rng, func(d DeadLetter) bool {
+			if scanned == maxPageScan {
+				page.Next = &DLQPosition{Partition: d.Partition, Offset: d.Offset}
+				return false
+			}
+			scanned++
+			if !q.Filter.Match(d) {
+				return true
+			}
+			if len(page.Letters) == limit {
+				page.Next = &DLQPosition{Partition: d.Partition, Offset: d.Offset}
+				return false
+			}
+			page.Letters = append(page.Letters, d)
+			return true
+		})
+		if err != nil {
+			return DLQPage{}, err
+		}
+		if page.Next != nil {
+			break
+		}
+	}
+	return page, nil
+}
+
+// Counts counts the messages dead-lettered in [from, to) by error class and
+// by reason.
+func (b *DLQBrowser) Counts(ctx context.Context, from, to time.Time) (DLQCounts, error) {
+	counts := DLQCounts{ByErrorClass: map[ErrorClass]int{}, ByReason: map[string]int{}}
+	err := b.source.Read(ctx, DLQRange{From: from, To: to}, func(d DeadLetter) bool {
+		if counts.Total == maxCountScan {
+			counts.Truncated = true
+			return false
+		}
+		// messages dead-lettered before the failure headers existed have none
+		class, reason := d.ErrorClass(), d.Header(HeaderDLQReason)
+		if class == "" {
+			class = "unknown"
+		}
+		if reason == "" {
+			reason = "unknown"
+		}
+
+		counts.Total++
+		counts.ByErrorClass[class]++
+		counts.ByReason[reason]++
+		return true
+	})
+	if err != nil {
+		return DLQCounts{}, err
+	}
+	return counts, nil
+}
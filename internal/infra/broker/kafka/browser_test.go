@@ -0,0 +1,141 @@
+package kafka
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	kafka "github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// MockDLQPartitions serves letters by partition, honoring the partitions
+// and the start offset of a range.
+type MockDLQPartitions struct {
+	letters map[int][]DeadLetter
+}
+
+func (m *MockDLQPartitions) Partitions(ctx context.Context) ([]int, error) {
+	partitions := make([]int, 0, len(m.letters))
+	for p := range m.letters {
+		partitions = append(partitions, p)
+	}
+	return partitions, nil
+}
+
+func (m *MockDLQPartitions) Read(ctx context.Context, rng DLQRange, fn func(DeadLetter) bool) error {
+	partitions := rng.Partitions
+	if len(partitions) == 0 {
+		partitions = []int{0, 1}
+	}
+	for _, p := range partitions {
+		for _, d := range m.letters[p] {
+			if d.Offset < rng.FromOffset {
+				continue
+			}
+			if !fn(d) {
+				return nil
+			}
+		}
+	}
+	return nil
+}
+
+func mockPartitions() *MockDLQPartitions {
+	letters := map[int][]DeadLetter{}
+	for p := range 2 {
+		for offset := range int64(3) {
+			d := testDeadLetter(offset, "order", ErrorClassInternal,
+				kafka.Header{Key: HeaderDLQReason, Value: []byte("retry")})
+			d.Partition = p
+			letters[p] = append(letters[p], d)
+		}
+	}
+	letters[1][1].Headers = []kafka.Header{
+		{Key: HeaderDLQErrorClass, Value: []byte(ErrorClassValidation)},
+		{Key: HeaderDLQReason, Value: []byte("dlq")},
+	}
+	return &MockDLQPartitions{letters: letters}
+}
+
+func TestDLQBrowserPage(t *testing.T) {
+	browser := &DLQBrowser{source: mockPartitions()}
+	ctx := context.Background()
+
+	var positions []DLQPosition
+	query := DLQPageQuery{Limit: 4}
+	for {
+		page, err := browser.Page(ctx, query)
+		require.NoError(t, err)
+		for _, d := range page.Letters {
+			positions = append(positions, DLQPosition{Partition: d.Partition, Offset: d.Offset})
+		}
+		if page.Next == nil {
+			break
+		}
+
+		next, err := ParseDLQPosition(page.Next.String())
+		require.NoError(t, err)
+		query.Start = &next
+	}
+
+	assert.Equal(t, []DLQPosition{{0, 0}, {0, 1}, {0, 2}, {1, 0}, {1, 1}, {1, 2}}, positions)
+}
+
+func TestDLQBrowserPageFilter(t *testing.T) {
+	browser := &DLQBrowser{source: mockPartitions()}
+
+	page, err := browser.Page(context.Background(), DLQPageQuery{Filter: DLQFilter{ErrorClass: ErrorClassValidation}})
+	require.NoError(t, err)
+
+	require.Len(t, page.Letters, 1)
+	assert.Equal(t, 1, page.Letters[0].Partition)
+	assert.Equal(t, int64(1), page.Letters[0].Offset)
+	assert.Nil(t, page.Next)
+}
+
+func TestDLQBrowserPageScanLimit(t *testing.T) {
+	source := &MockDLQPartitions{letters: map[int][]DeadLetter{}}
+	for offset := range int64(maxPageScan + 1) {
+		source.letters[0] = append(source.letters[0], testDeadLetter(offset, "other", ErrorClassInternal))
+	}
+	source.letters[1] = []DeadLetter{testDeadLetter(0, "order", ErrorClassInternal)}
+	source.letters[1][0].Partition = 1
+	browser := &DLQBrowser{source: source}
+	query := DLQPageQuery{Filter: DLQFilter{OrderUID: "order"}}
+
+	page, err := browser.Page(context.Background(), query)
+	require.NoError(t, err)
+	assert.Empty(t, page.Letters)
+	require.NotNil(t, page.Next)
+	assert.Equal(t, DLQPosition{Partition: 0, Offset: maxPageScan}, *page.Next)
+
+	query.Start = page.Next
+	page, err = browser.Page(context.Background(), query)
+	require.NoError(t, err)
+	require.Len(t, page.Letters, 1)
+	assert.Equal(t, 1, page.Letters[0].Partition)
+	assert.Nil(t, page.Next)
+}
+
+func TestDLQBrowserCounts(t *testing.T) {
+	browser := &DLQBrowser{source: mockPartitions()}
+
+	counts, err := browser.Counts(context.Background(), time.Now().Add(-time.Hour), time.Now())
+	require.NoError(t, err)
+
+	assert.Equal(t, DLQCounts{
+		Total:        6,
+		ByErrorClass: map[ErrorClass]int{ErrorClassInternal: 5, ErrorClassValidation: 1},
+		ByReason:     map[string]int{"retry": 5, "dlq": 1},
+	}, counts)
+}
+
+func TestParseDLQPosition(t *testing.T) {
+	_, err := ParseDLQPosition("not a cursor")
+	assert.ErrorIs(t, err, ErrInvalidDLQCursor)
+
+	_, err = ParseDLQPosition(DLQPosition{Partition: -1}.String())
+	assert.ErrorIs(t, err, ErrInvalidDLQCursor)
+}
@@ -2,12 +2,12 @@ package kafka
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"log/slog"
 	"order-service/internal/config"
-	"order-service/internal/domain"
 	"order-service/internal/infra/broker/handler"
+	"order-service/internal/infra/repo"
+	"time"
 
 	kafka "github.com/segmentio/kafka-go"
 )
@@ -19,16 +19,35 @@ type KafkaConsumer struct {
 	logger       *slog.Logger
 	cfg          *config.KafkaConfig
 	orderReader  *kafka.Reader
-	retryReader  *kafka.Reader
+	updateReader *kafka.Reader
+	orderPool    *workerPool
+	updatePool   *workerPool
+	retryTiers   []*retryTier
+	groups       map[string]string
 	retryWriter  *kafka.Writer
 	DLQWriter    *kafka.Writer
 	ready        chan struct{}
+	offsetStore  OffsetStore
+	orderGate    *offsetGate
+	updateGate   *offsetGate
 }
 
 var ErrNotInitialized = errors.New("not initialized")
 
-func NewKafkaConsumer(cfg *config.KafkaConfig, handler Handler, retryHandler RetryHandler, logger *slog.Logger) *KafkaConsumer {
-	return &KafkaConsumer{
+type ConsumerOption func(*KafkaConsumer)
+
+// WithOffsetStore commits offsets to store before the consumer groups,
+// records the offset of every message in the transaction of the order it
+// writes and skips the messages the store has committed.
+func WithOffsetStore(store OffsetStore) ConsumerOption {
+	return func(kc *KafkaConsumer) {
+		kc.offsetStore = store
+	}
+}
+
+func NewKafkaConsumer(cfg *config.KafkaConfig, handler Handler, retryHandler RetryHandler, logger *slog.Logger,
+	opts ...ConsumerOption) *KafkaConsumer {
+	kc := &KafkaConsumer{
 		broker:       cfg.Broker,
 		cfg:          cfg,
 		handler:      handler,
@@ -36,6 +55,10 @@ func NewKafkaConsumer(cfg *config.KafkaConfig, handler Handler, retryHandler Ret
 		logger:       logger,
 		ready:        make(chan struct{}),
 	}
+	for _, opt := range opts {
+		opt(kc)
+	}
+	return kc
 }
 
 func (kc *KafkaConsumer) Init() error {
@@ -45,15 +68,42 @@ func (kc *KafkaConsumer) Init() error {
 		Topic:          kc.cfg.OrderTopicCfg.KafkaTopic,
 		CommitInterval: 0,
 	})
-	kc.retryReader = kafka.NewReader(kafka.ReaderConfig{
+	kc.updateReader = kafka.NewReader(kafka.ReaderConfig{
 		Brokers:        []string{kc.broker},
-		GroupID:        kc.cfg.RetryTopicCfg.GroupID,
-		Topic:          kc.cfg.RetryTopicCfg.KafkaTopic,
+		GroupID:        kc.cfg.UpdateTopicCfg.GroupID,
+		Topic:          kc.cfg.UpdateTopicCfg.KafkaTopic,
 		CommitInterval: 0,
 	})
+	commitInterval := time.Duration(kc.cfg.CommitInterval) * time.Millisecond
+	kc.orderPool = newWorkerPool("orders", kc.committer(kc.cfg.OrderTopicCfg.GroupID, kc.orderReader), kc.handleOrderMsg,
+		kc.cfg.Workers, kc.cfg.WorkerQueueSize, commitInterval, kc.logger)
+	kc.updatePool = newWorkerPool("updates", kc.committer(kc.cfg.UpdateTopicCfg.GroupID, kc.updateReader), kc.handleUpdateMsg,
+		kc.cfg.Workers, kc.cfg.WorkerQueueSize, commitInterval, kc.logger)
+	kc.orderGate = kc.gate(kc.cfg.OrderTopicCfg.GroupID)
+	kc.updateGate = kc.gate(kc.cfg.UpdateTopicCfg.GroupID)
+	kc.groups = map[string]string{
+		kc.cfg.OrderTopicCfg.KafkaTopic:  kc.cfg.OrderTopicCfg.GroupID,
+		kc.cfg.UpdateTopicCfg.KafkaTopic: kc.cfg.UpdateTopicCfg.GroupID,
+	}
+	kc.retryTiers = nil
+	for _, cfg := range kc.retryHandler.Tiers() {
+		kc.groups[cfg.Topic] = cfg.GroupID
+		tier := &retryTier{RetryTier: cfg}
+		tier.reader = kafka.NewReader(kafka.ReaderConfig{
+			Brokers:        []string{kc.broker},
+			GroupID:        cfg.GroupID,
+			Topic:          cfg.Topic,
+			CommitInterval: 0,
+		})
+		tier.queue = newDelayQueue(cfg.Topic, kc.committer(cfg.GroupID, tier.reader), func(ctx context.Context, msg kafka.Message) error {
+			return kc.handleRetryMsg(ctx, tier, msg)
+		}, kc.cfg.RetryMaxHeld, commitInterval, kc.logger)
+		tier.gate = kc.gate(cfg.GroupID)
+		kc.retryTiers = append(kc.retryTiers, tier)
+	}
+	// the topic is set per message, by retry tier
 	kc.retryWriter = &kafka.Writer{
-		Addr:  kafka.TCP(kc.cfg.Broker),
-		Topic: kc.cfg.RetryTopicCfg.KafkaTopic,
+		Addr: kafka.TCP(kc.cfg.Broker),
 	}
 	kc.DLQWriter = &kafka.Writer{
 		Addr:  kafka.TCP(kc.cfg.Broker),
@@ -72,6 +122,56 @@ func (kc *KafkaConsumer) Init() error {
 	return nil
 }
 
+// committer returns where the offsets of a reader of group are committed.
+func (kc *KafkaConsumer) committer(group string, reader *kafka.Reader) committer {
+	if kc.offsetStore == nil {
+		return reader
+	}
+	return &storeCommitter{group: group, store: kc.offsetStore, reader: reader}
+}
+
+func (kc *KafkaConsumer) gate(group string) *offsetGate {
+	if kc.offsetStore == nil {
+		return nil
+	}
+	return newOffsetGate(group, kc.offsetStore)
+}
+
+// consumed tells whether msg was fetched again after its offset was
+// committed to the offset store. It asks the store until it answers, since
+// a fetched message that is neither processed nor skipped would be lost once
+// a later offset is committed.
+func (kc *KafkaConsumer) consumed(ctx context.Context, gate *offsetGate, msg kafka.Message) (bool, error) {
+	if gate == nil {
+		return false, nil
+	}
+
+	var skip bool
+	ok := untilDone(ctx, func() error {
+		var err error
+		skip, err = gate.consumed(ctx, msg)
+		return err
+	}, func(err error) {
+		kc.logger.Error("failed to read committed offset", "error", err, "topic", msg.Topic, "partition", msg.Partition)
+	})
+	if !ok {
+		return false, ctx.Err()
+	}
+	if skip {
+		kc.logger.Debug("skipped consumed message", "topic", msg.Topic, "partition", msg.Partition, "offset", msg.Offset)
+	}
+	return skip, nil
+}
+
+// withOffset marks ctx as processing msg, so its offset is recorded with the
+// order it writes.
+func (kc *KafkaConsumer) withOffset(ctx context.Context, msg kafka.Message) context.Context {
+	if kc.offsetStore == nil {
+		return ctx
+	}
+	return repo.WithConsumedOffset(ctx, consumedOffset(kc.groups[msg.Topic], msg))
+}
+
 func (kc *KafkaConsumer) checkBroker() error {
 	conn, err := kafka.Dial("tcp", kc.broker)
 	if err != nil {
@@ -90,9 +190,12 @@ func (kc *KafkaConsumer) checkBroker() error {
 func (kc *KafkaConsumer) checkTopics() {
 	topics := []string{
 		kc.cfg.OrderTopicCfg.KafkaTopic,
-		kc.cfg.RetryTopicCfg.KafkaTopic,
+		kc.cfg.UpdateTopicCfg.KafkaTopic,
 		kc.cfg.DLQTopicCfg.KafkaTopic,
 	}
+	for _, tier := range kc.retryTiers {
+		topics = append(topics, tier.Topic)
+	}
 	for _, t := range topics {
 		_, err := kafka.DialLeader(context.Background(), "tcp", kc.broker, t, 0)
 		if err != nil {
@@ -107,6 +210,9 @@ func (kc *KafkaConsumer) Ready() <-chan struct{} {
 	return kc.ready
 }
 
+// ReadOrderMsg fetches the next order message and hands it to the worker
+// pool. Its offset is committed once it and all earlier messages of its
+// partition are handled.
 func (kc *KafkaConsumer) ReadOrderMsg(ctx context.Context) error {
 	if kc.orderReader == nil {
 		kc.logger.Error("orderReader is not initialized")
@@ -114,84 +220,253 @@ func (kc *KafkaConsumer) ReadOrderMsg(ctx context.Context) error {
 		return ErrNotInitialized
 	}
 
-	msg, err := kc.orderReader.ReadMessage(ctx)
+	if kc.cfg.BatchSize > 1 {
+		return kc.readOrderBatch(ctx)
+	}
 
+	msg, err := kc.orderReader.FetchMessage(ctx)
 	if err != nil {
 		kc.logger.Error("failed to read message from Kafka", "error", err)
 
 		return err
 	}
 
-	if len(msg.Value) == 0 {
-
-		kc.logger.Error("no data to process")
+	skip, err := kc.consumed(ctx, kc.orderGate, msg)
+	if err != nil {
+		return err
+	}
+	if skip {
+		kc.orderPool.skip(ctx, msg)
 
 		return nil
 	}
 
-	res := kc.handler.ProcessOrderMessage(ctx, msg.Value)
+	return kc.orderPool.dispatch(ctx, msg)
+}
 
-	var order domain.OrderParams
+// readOrderBatch gathers up to BatchSize messages, waiting at most
+// BatchTimeout after the first one, and stores their orders in one
+// transaction.
+func (kc *KafkaConsumer) readOrderBatch(ctx context.Context) error {
+	msgs, err := kc.fetchOrderBatch(ctx)
+	if err != nil {
+		kc.logger.Error("failed to read message from Kafka", "error", err)
 
-	if err := json.Unmarshal(msg.Value, &order); err != nil {
-		kc.logger.Error("failed to unmarshal order message", "error", err)
 		return err
 	}
 
-	switch res {
-	case handler.Success:
-		if err := kc.orderReader.CommitMessages(ctx, msg); err != nil {
-			kc.logger.Error("failed to commit message", "error", err, "uid", order.OrderUID)
+	return kc.handleOrderBatch(ctx, msgs)
+}
 
-			return err
+// handleOrderBatch stores the orders of msgs in one transaction and forwards
+// the failed ones to the retry topic or the DLQ. The offsets are committed by
+// the order pool, like those of single messages, so a batch cut off by
+// shutdown is committed only up to the messages that were forwarded.
+func (kc *KafkaConsumer) handleOrderBatch(ctx context.Context, msgs []kafka.Message) error {
+	kc.orderPool.track(ctx, msgs...)
+
+	values := make([][]byte, 0, len(msgs))
+	batch := make([]kafka.Message, 0, len(msgs))
+	for _, msg := range msgs {
+		if len(msg.Value) == 0 {
+			kc.logger.Error("no data to process")
+			kc.orderPool.done(msg)
+
+			continue
 		}
+		values = append(values, msg.Value)
+		batch = append(batch, msg)
+	}
 
-		kc.logger.Info("message processed", "uid", order.OrderUID)
+	results, procErrs := kc.handler.ProcessOrderBatch(ctx, values)
+	for i, msg := range batch {
+		routed := untilDone(ctx, func() error {
+			return kc.routeOrderResult(ctx, msg, results[i], procErrs[i])
+		}, func(err error) {
+			kc.logger.Error("failed to route message", "error", err, "key", string(msg.Key))
+		})
+		if !routed {
+			return ctx.Err()
+		}
+		kc.orderPool.done(msg)
+	}
 
-		return nil
-	case handler.Retry:
-		if kc.retryWriter == nil {
-			kc.logger.Error("retry writer is not initialized")
+	return nil
+}
 
-			return ErrNotInitialized
+func (kc *KafkaConsumer) fetchOrderBatch(ctx context.Context) ([]kafka.Message, error) {
+	first, err := kc.orderReader.FetchMessage(ctx)
+	if err != nil {
+		return nil, err
+	}
+	msgs := []kafka.Message{first}
+
+	batchCtx, cancel := context.WithTimeout(ctx, time.Duration(kc.cfg.BatchTimeout)*time.Millisecond)
+	defer cancel()
+	for len(msgs) < kc.cfg.BatchSize {
+		msg, err := kc.orderReader.FetchMessage(batchCtx)
+		if err != nil {
+			break
 		}
+		msgs = append(msgs, msg)
+	}
 
-		if err := kc.WriteRetryTopic(ctx, kafka.Message{
-			Key:   msg.Key,
-			Value: msg.Value,
-		}); err != nil {
-			kc.logger.Error("failed to write to retryHandler topic", "error", err, "uid", order.OrderUID)
+	return msgs, nil
+}
 
-			return err
-		}
+func (kc *KafkaConsumer) handleOrderMsg(ctx context.Context, msg kafka.Message) error {
+	if len(msg.Value) == 0 {
+		kc.logger.Error("no data to process")
 
-		kc.logger.Debug("written to retry")
+		return nil
+	}
+
+	res, procErr := kc.handler.ProcessOrderMessage(kc.withOffset(ctx, msg), msg.Value)
+
+	return kc.routeOrderResult(ctx, msg, res, procErr)
+}
+
+// routeOrderResult forwards an order message to the retry topic or the DLQ
+// according to its processing result.
+func (kc *KafkaConsumer) routeOrderResult(ctx context.Context, msg kafka.Message, res handler.Result, procErr error) error {
+	msg.Headers = nil
+
+	return kc.routeResult(ctx, msg, retryState{}, res, procErr)
+}
+
+// routeResult forwards msg to the retry topic or the DLQ according to its
+// processing result. state is the retry state msg was delivered with.
+func (kc *KafkaConsumer) routeResult(ctx context.Context, msg kafka.Message, state retryState, res handler.Result, procErr error) error {
+	switch res {
+	case handler.Success:
+		kc.logger.Info("message processed", "key", string(msg.Key), "attempt", state.attempt)
 
 		return nil
+	case handler.Retry:
+		_, err := kc.scheduleRetry(ctx, msg, state, procErr)
+
+		return err
 	case handler.DLQ:
-		if kc.DLQWriter == nil {
-			kc.logger.Error("orderReader is not initialized")
+		return kc.writeDeadLetter(ctx, msg, state, handler.DLQ, procErr)
+	}
 
-			return ErrNotInitialized
-		}
+	return nil
+}
+
+// writeDeadLetter writes msg to the DLQ with the dead letter headers. state
+// is the retry state msg was delivered with and reason the result that sent
+// it there.
+func (kc *KafkaConsumer) writeDeadLetter(ctx context.Context, msg kafka.Message, state retryState,
+	reason handler.Result, procErr error) error {
+	dl := deadLetter{
+		source:         msg,
+		reason:         reason,
+		err:            procErr,
+		attempts:       state.attempt + 1,
+		group:          kc.groups[msg.Topic],
+		version:        kc.cfg.ServiceVersion,
+		deadLetteredAt: time.Now(),
+	}
+	if err := kc.WriteDLQTopic(ctx, kafka.Message{
+		Key:     msg.Key,
+		Value:   msg.Value,
+		Headers: withHeaders(msg.Headers, dl.headers()...),
+	}); err != nil {
+		kc.logger.Error("failed to write to dead letter queue", "error", err, "key", string(msg.Key))
+
+		return err
+	}
+
+	kc.logger.Debug("written to dlq", "key", string(msg.Key), "reason", reason, "attempts", dl.attempts)
 
-		if err := kc.WriteDLQTopic(ctx, kafka.Message{
-			Key:   msg.Key,
-			Value: msg.Value,
-		}); err != nil {
+	return nil
+}
+
+// scheduleRetry writes msg to the retry tier of its next attempt and tells
+// whether it did. Once msg has no retries left it goes to the DLQ instead.
+func (kc *KafkaConsumer) scheduleRetry(ctx context.Context, msg kafka.Message, state retryState, procErr error) (bool, error) {
+	now := time.Now()
+	if state.firstFailure.IsZero() {
+		state.firstFailure = now
+	}
 
-			kc.logger.Error("failed to write to dead letter queue", "error", err, "uid", order.OrderUID)
+	next := state
+	next.attempt++
+	tier, notBefore, ok := kc.retryHandler.NextAttempt(next.attempt, now)
+	if !ok {
+		msg.Headers = withHeaders(msg.Headers, state.headers()...)
+		if err := kc.writeDeadLetter(ctx, msg, state, handler.Retry, procErr); err != nil {
+			return false, err
 		}
 
-		kc.logger.Debug("written to dlq", "uid", order.OrderUID)
+		kc.logger.Warn("retries exhausted, written to dlq", "key", string(msg.Key), "attempts", state.attempt)
+
+		return false, nil
+	}
+	if tier < 0 || tier >= len(kc.retryTiers) {
+		return false, ErrNotInitialized
+	}
+	next.notBefore = notBefore
+
+	topic := kc.retryTiers[tier].Topic
+	if err := kc.WriteRetryTopic(ctx, kafka.Message{
+		Topic:   topic,
+		Key:     msg.Key,
+		Value:   msg.Value,
+		Headers: withHeaders(msg.Headers, next.headers()...),
+	}); err != nil {
+		kc.logger.Error("failed to write to retry topic", "error", err, "topic", topic, "key", string(msg.Key))
+
+		return false, err
+	}
+
+	kc.logger.Debug("written to retry", "key", string(msg.Key), "topic", topic,
+		"attempt", next.attempt, "not_before", notBefore)
+
+	return true, nil
+}
+
+func (kc *KafkaConsumer) ReadUpdateMsg(ctx context.Context) error {
+	if kc.updateReader == nil {
+		kc.logger.Error("updateReader is not initialized")
+
+		return ErrNotInitialized
+	}
+
+	msg, err := kc.updateReader.FetchMessage(ctx)
+	if err != nil {
+		kc.logger.Error("failed to read message from Kafka", "error", err)
+
+		return err
+	}
+
+	skip, err := kc.consumed(ctx, kc.updateGate, msg)
+	if err != nil {
+		return err
+	}
+	if skip {
+		kc.updatePool.skip(ctx, msg)
 
 		return nil
 	}
 
-	return nil
+	return kc.updatePool.dispatch(ctx, msg)
+}
+
+func (kc *KafkaConsumer) handleUpdateMsg(ctx context.Context, msg kafka.Message) error {
+	if len(msg.Value) == 0 {
+		kc.logger.Error("no data to process")
+
+		return nil
+	}
+
+	res, procErr := kc.handler.ProcessUpdateMessage(kc.withOffset(ctx, msg), msg.Value)
+	msg.Headers = []kafka.Header{{Key: HeaderMessageType, Value: []byte(MessageTypeUpdate)}}
 
+	return kc.routeResult(ctx, msg, retryState{}, res, procErr)
 }
 
+// WriteRetryTopic writes msg to the retry tier topic set in msg.Topic.
 func (kc *KafkaConsumer) WriteRetryTopic(ctx context.Context, msg kafka.Message) error {
 	if kc.retryWriter == nil {
 		kc.logger.Error("retry writer is not initialized")
@@ -200,64 +475,103 @@ func (kc *KafkaConsumer) WriteRetryTopic(ctx context.Context, msg kafka.Message)
 	}
 
 	return kc.retryWriter.WriteMessages(ctx, kafka.Message{
-		Key:   msg.Key,
-		Value: msg.Value,
+		Topic:   msg.Topic,
+		Key:     msg.Key,
+		Value:   msg.Value,
+		Headers: msg.Headers,
 	})
 }
 
-func (kc *KafkaConsumer) ReadRetryMsg(ctx context.Context) error {
-	msg, err := kc.retryReader.ReadMessage(ctx)
+// RetryTiers tells how many retry tiers ReadRetryMsg reads.
+func (kc *KafkaConsumer) RetryTiers() int {
+	return len(kc.retryTiers)
+}
+
+// ReadRetryMsg fetches the next message of the given retry tier and holds it
+// until its x-not-before time. Messages that are not due do not block the
+// ones behind them.
+func (kc *KafkaConsumer) ReadRetryMsg(ctx context.Context, tier int) error {
+	if tier < 0 || tier >= len(kc.retryTiers) {
+		kc.logger.Error("retry tier is not initialized", "tier", tier)
+
+		return ErrNotInitialized
+	}
+	t := kc.retryTiers[tier]
+
+	msg, err := t.reader.FetchMessage(ctx)
 	if err != nil {
-		kc.logger.Error("failed to read message from Kafka", "error", err)
+		kc.logger.Error("failed to read message from Kafka", "error", err, "topic", t.Topic)
 
 		return err
 	}
 
-	if len(msg.Value) == 0 {
-		kc.logger.Error("no data to process")
+	skip, err := kc.consumed(ctx, t.gate, msg)
+	if err != nil {
+		return err
 	}
+	if skip {
+		t.queue.skip(ctx, msg)
 
-	res := kc.retryHandler.RetryWrapper(ctx, func() handler.Result {
-		return kc.handler.ProcessOrderMessage(ctx, msg.Value)
-	})
+		return nil
+	}
 
-	var order domain.OrderParams
+	return t.queue.hold(ctx, msg)
+}
 
-	if err := json.Unmarshal(msg.Value, &order); err != nil {
-		kc.logger.Error("failed to unmarshal order message", "error", err)
+// handleRetryMsg processes a due retry message once. A message that fails
+// again is scheduled for the next attempt with the retry state it carries.
+func (kc *KafkaConsumer) handleRetryMsg(ctx context.Context, tier *retryTier, msg kafka.Message) error {
+	if len(msg.Value) == 0 {
+		kc.logger.Error("no data to process")
 
-		return err
+		return nil
 	}
 
-	switch res {
+	var (
+		res     handler.Result
+		procErr error
+	)
+	processCtx := kc.withOffset(ctx, msg)
+	if isUpdateMsg(msg) {
+		res, procErr = kc.handler.ProcessUpdateMessage(processCtx, msg.Value)
+	} else {
+		res, procErr = kc.handler.ProcessOrderMessage(processCtx, msg.Value)
+	}
 
-	case handler.Success:
-		if err := kc.retryReader.CommitMessages(ctx, msg); err != nil {
-			kc.logger.Error("failed to commit messages", "err", err, "uid", order.OrderUID)
+	state := readRetryState(msg.Headers)
+	if res == handler.Retry {
+		retried, err := kc.scheduleRetry(ctx, msg, state, procErr)
+		if err != nil {
 			return err
 		}
-
-		kc.logger.Debug("successfully created")
+		if retried {
+			tier.escalated.Add(1)
+		} else {
+			tier.deadLettered.Add(1)
+		}
 
 		return nil
-	case handler.DLQ:
-		if err := kc.WriteDLQTopic(ctx, kafka.Message{
-			Key:   msg.Key,
-			Value: msg.Value,
-		}); err != nil {
-			kc.logger.Error("failed to write to dlq topuc", "err", err)
-
-			return err
-		}
+	}
 
-		kc.logger.Debug("written to dlq", "uid", order.OrderUID)
+	if err := kc.routeResult(ctx, msg, state, res, procErr); err != nil {
+		return err
+	}
+	if res == handler.DLQ {
+		tier.deadLettered.Add(1)
+	} else {
+		tier.succeeded.Add(1)
+	}
 
-		return nil
-	default:
-		kc.logger.Error("unknown result", "result", res)
+	return nil
+}
 
-		return nil
+// RetryStats reports the retry tiers. It is exported through expvar.
+func (kc *KafkaConsumer) RetryStats() []RetryTierStats {
+	stats := make([]RetryTierStats, 0, len(kc.retryTiers))
+	for _, tier := range kc.retryTiers {
+		stats = append(stats, tier.stats())
 	}
+	return stats
 }
 
 func (kc *KafkaConsumer) WriteDLQTopic(ctx context.Context, msg kafka.Message) error {
@@ -268,22 +582,41 @@ func (kc *KafkaConsumer) WriteDLQTopic(ctx context.Context, msg kafka.Message) e
 	}
 
 	return kc.DLQWriter.WriteMessages(ctx, kafka.Message{
-		Key:   msg.Key,
-		Value: msg.Value,
+		Key:     msg.Key,
+		Value:   msg.Value,
+		Headers: msg.Headers,
 	})
 }
 
 func (kc *KafkaConsumer) ShutDown() error {
 	var errs []error
 
+	if kc.orderPool != nil {
+		kc.orderPool.shutdown()
+	}
+
+	if kc.updatePool != nil {
+		kc.updatePool.shutdown()
+	}
+
+	for _, tier := range kc.retryTiers {
+		tier.queue.shutdown()
+	}
+
 	if kc.orderReader != nil {
 		if err := kc.orderReader.Close(); err != nil {
 			errs = append(errs, err)
 		}
 	}
 
-	if kc.retryReader != nil {
-		if err := kc.retryReader.Close(); err != nil {
+	if kc.updateReader != nil {
+		if err := kc.updateReader.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	for _, tier := range kc.retryTiers {
+		if err := tier.reader.Close(); err != nil {
 			errs = append(errs, err)
 		}
 	}
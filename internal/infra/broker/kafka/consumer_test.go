@@ -0,0 +1,93 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"order-service/internal/config"
+	"order-service/internal/infra/broker/handler"
+	"order-service/internal/lib/logger"
+	"testing"
+	"time"
+
+	kafka "github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type MockHandler struct {
+	results []handler.Result
+}
+
+func (m *MockHandler) ProcessOrderMessage(ctx context.Context, msg []byte) (handler.Result, error) {
+	return handler.Success, nil
+}
+
+func (m *MockHandler) ProcessOrderBatch(ctx context.Context, batch [][]byte) ([]handler.Result, []error) {
+	errs := make([]error, len(batch))
+	for i, res := range m.results {
+		if res != handler.Success {
+			errs[i] = errors.New("invalid order")
+		}
+	}
+	return m.results, errs
+}
+
+func (m *MockHandler) ProcessUpdateMessage(ctx context.Context, msg []byte) (handler.Result, error) {
+	return handler.Success, nil
+}
+
+func TestHandleOrderBatchCommits(t *testing.T) {
+	l, err := logger.InitLogger("test")
+	require.NoError(t, err)
+
+	msgs := []kafka.Message{
+		{Topic: "orders", Partition: 0, Offset: 10, Value: []byte(`{}`)},
+		{Topic: "orders", Partition: 0, Offset: 11},
+		{Topic: "orders", Partition: 0, Offset: 12, Value: []byte(`{}`)},
+		{Topic: "orders", Partition: 1, Offset: 5, Value: []byte(`{}`)},
+	}
+	tests := []struct {
+		name      string
+		results   []handler.Result
+		committed map[int]int64
+		err       error
+	}{
+		{
+			name:      "batch handled",
+			results:   []handler.Result{handler.Success, handler.Success, handler.Success},
+			committed: map[int]int64{0: 12, 1: 5},
+		},
+		{
+			// the DLQ write of offset 12 never succeeds before shutdown
+			name:      "batch cut off by shutdown",
+			results:   []handler.Result{handler.Success, handler.DLQ, handler.Success},
+			committed: map[int]int64{0: 11},
+			err:       context.Canceled,
+		},
+		{
+			name:      "first message cut off by shutdown",
+			results:   []handler.Result{handler.DLQ, handler.Success, handler.Success},
+			committed: map[int]int64{},
+			err:       context.Canceled,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader := &MockCommitter{committed: make(map[int]int64)}
+			kc := &KafkaConsumer{handler: &MockHandler{results: tt.results}, logger: l, cfg: &config.KafkaConfig{}}
+			kc.orderPool = newWorkerPool("orders", reader, kc.handleOrderMsg, 1, 1, time.Hour, l)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if tt.err != nil {
+				cancel()
+			}
+
+			err := kc.handleOrderBatch(ctx, msgs)
+			kc.orderPool.shutdown()
+
+			assert.ErrorIs(t, err, tt.err)
+			assert.Equal(t, tt.committed, reader.committed)
+		})
+	}
+}
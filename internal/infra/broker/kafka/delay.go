@@ -0,0 +1,113 @@
+package kafka
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	kafka "github.com/segmentio/kafka-go"
+)
+
+const defaultMaxHeld = 1000
+
+// delayQueue holds retry topic messages until their x-not-before time and
+// then processes them. Every held message waits on its own, so a message
+// that is not due yet does not delay the ones fetched after it. Offsets are
+// committed only up to the highest contiguous processed offset of each
+// partition, so held messages are delivered again after a restart.
+type delayQueue struct {
+	name    string
+	process func(ctx context.Context, msg kafka.Message) error
+	logger  *slog.Logger
+	commits *offsetCommitter
+	// slots bounds the number of held messages.
+	slots chan struct{}
+	now   func() time.Time
+
+	held     sync.WaitGroup
+	stopOnce sync.Once
+}
+
+func newDelayQueue(name string, reader committer, process func(ctx context.Context, msg kafka.Message) error,
+	maxHeld int, commitInterval time.Duration, logger *slog.Logger) *delayQueue {
+	if maxHeld < 1 {
+		maxHeld = defaultMaxHeld
+	}
+
+	return &delayQueue{
+		name:    name,
+		process: process,
+		logger:  logger,
+		commits: newOffsetCommitter(name, reader, commitInterval, logger),
+		slots:   make(chan struct{}, maxHeld),
+		now:     time.Now,
+	}
+}
+
+// hold schedules msg for processing at its due time, blocking while the
+// queue is full.
+func (q *delayQueue) hold(ctx context.Context, msg kafka.Message) error {
+	q.commits.run(ctx)
+
+	select {
+	case q.slots <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	q.commits.offsets.track(msg)
+	q.held.Add(1)
+	go func() {
+		defer q.held.Done()
+		defer func() { <-q.slots }()
+
+		if q.handle(ctx, msg) {
+			q.commits.offsets.done(msg)
+		}
+	}()
+
+	return nil
+}
+
+// skip commits msg in order with the other messages without holding it.
+func (q *delayQueue) skip(ctx context.Context, msg kafka.Message) {
+	q.commits.run(ctx)
+	q.commits.skip(msg)
+}
+
+// handle waits until msg is due and processes it until it succeeds or ctx is
+// done.
+func (q *delayQueue) handle(ctx context.Context, msg kafka.Message) bool {
+	if wait := readRetryState(msg.Headers).notBefore.Sub(q.now()); wait > 0 {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	return untilDone(ctx, func() error { return q.process(ctx, msg) }, func(err error) {
+		q.logger.Error("failed to handle message", "reader", q.name, "error", err,
+			"partition", msg.Partition, "offset", msg.Offset, "key", string(msg.Key))
+	})
+}
+
+// size tells how many messages wait for their due time or are processed.
+func (q *delayQueue) size() int {
+	return len(q.slots)
+}
+
+// shutdown waits for the held messages and commits what was processed. The
+// context passed to hold has to be canceled first, otherwise shutdown waits
+// until every held message is due. No message may be held after it is
+// called.
+func (q *delayQueue) shutdown() {
+	q.stopOnce.Do(func() {
+		q.held.Wait()
+		q.commits.close()
+	})
+}
@@ -0,0 +1,73 @@
+package kafka
+
+import (
+	"context"
+	"order-service/internal/lib/logger"
+	"sync"
+	"testing"
+	"time"
+
+	kafka "github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryStateHeaders(t *testing.T) {
+	state := retryState{
+		attempt:      2,
+		firstFailure: time.Date(2025, 10, 1, 12, 0, 0, 123, time.UTC),
+		notBefore:    time.Date(2025, 10, 1, 12, 0, 30, 0, time.UTC),
+	}
+	typeHeader := kafka.Header{Key: HeaderMessageType, Value: []byte(MessageTypeUpdate)}
+	stale := kafka.Header{Key: HeaderRetryAttempt, Value: []byte("1")}
+
+	headers := withHeaders([]kafka.Header{typeHeader, stale}, state.headers()...)
+
+	assert.Equal(t, state, readRetryState(headers))
+	assert.Equal(t, "2", headerValue(headers, HeaderRetryAttempt))
+	assert.Len(t, headers, 4)
+	assert.Equal(t, retryState{}, readRetryState(nil))
+}
+
+func TestDelayQueueDoesNotBlockOnHeldMessages(t *testing.T) {
+	l, err := logger.InitLogger("test")
+	require.NoError(t, err)
+
+	var (
+		mu        sync.Mutex
+		processed []int64
+	)
+	process := func(ctx context.Context, msg kafka.Message) error {
+		mu.Lock()
+		defer mu.Unlock()
+		processed = append(processed, msg.Offset)
+		return nil
+	}
+
+	reader := &MockCommitter{committed: make(map[int]int64)}
+	queue := newDelayQueue("test", reader, process, 10, 10*time.Millisecond, l)
+
+	now := time.Now()
+	msg := func(offset int64, delay time.Duration) kafka.Message {
+		state := retryState{attempt: 1, firstFailure: now, notBefore: now.Add(delay)}
+		return kafka.Message{Topic: "retry", Offset: offset, Headers: state.headers()}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	require.NoError(t, queue.hold(ctx, msg(0, time.Hour)))
+	require.NoError(t, queue.hold(ctx, msg(1, 0)))
+	require.NoError(t, queue.hold(ctx, msg(2, -time.Second)))
+
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(processed) == 2
+	}, time.Second, 10*time.Millisecond)
+
+	// the message that is not due holds back the commit of its partition
+	cancel()
+	queue.shutdown()
+
+	assert.ElementsMatch(t, []int64{1, 2}, processed)
+	assert.Empty(t, reader.committed)
+}
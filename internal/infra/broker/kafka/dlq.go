@@ -0,0 +1,252 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	kafka "github.com/segmentio/kafka-go"
+)
+
+// DeadLetter is a message read from the DLQ topic.
+type DeadLetter struct {
+	Partition int
+	Offset    int64
+	Time      time.Time
+	Key       []byte
+	Value     []byte
+	Headers   []kafka.Header
+}
+
+func newDeadLetter(msg kafka.Message) DeadLetter {
+	return DeadLetter{
+		Partition: msg.Partition,
+		Offset:    msg.Offset,
+		Time:      msg.Time,
+		Key:       msg.Key,
+		Value:     msg.Value,
+		Headers:   msg.Headers,
+	}
+}
+
+// Header returns the value of the header key, empty when it is missing.
+func (d DeadLetter) Header(key string) string {
+	return headerValue(d.Headers, key)
+}
+
+func (d DeadLetter) ErrorClass() ErrorClass {
+	return ErrorClass(d.Header(HeaderDLQErrorClass))
+}
+
+// OrderUID is the order_uid of the payload, empty when the payload is not an
+// order.
+func (d DeadLetter) OrderUID() string {
+	var order struct {
+		OrderUID string `json:"order_uid"`
+	}
+	if err := json.Unmarshal(d.Value, &order); err != nil {
+		return ""
+	}
+	return order.OrderUID
+}
+
+// Attempts is the number of processing attempts from the dead letter
+// headers, 0 when it is missing.
+func (d DeadLetter) Attempts() int {
+	n, _ := strconv.Atoi(d.Header(HeaderDLQAttempts))
+	return n
+}
+
+// DLQRange selects DLQ messages. Zero fields do not limit the range. The
+// offsets apply to every partition; FromOffset and From are inclusive,
+// ToOffset and To are exclusive.
+type DLQRange struct {
+	Partitions []int
+	FromOffset int64
+	ToOffset   int64
+	From       time.Time
+	To         time.Time
+}
+
+// DLQFilter selects DLQ messages by content. Empty fields do not filter.
+type DLQFilter struct {
+	OrderUID   string
+	ErrorClass ErrorClass
+}
+
+func (f DLQFilter) Match(d DeadLetter) bool {
+	if f.ErrorClass != "" && d.ErrorClass() != f.ErrorClass {
+		return false
+	}
+	if f.OrderUID != "" && d.OrderUID() != f.OrderUID {
+		return false
+	}
+	return true
+}
+
+var errStopReading = errors.New("stop reading")
+
+// DLQReader reads the DLQ topic by partition, without a consumer group, so
+// reading it never moves committed offsets.
+type DLQReader struct {
+	broker string
+	topic  string
+}
+
+func NewDLQReader(broker, topic string) *DLQReader {
+	return &DLQReader{broker: broker, topic: topic}
+}
+
+// Partitions lists the partitions of the DLQ topic.
+func (r *DLQReader) Partitions(ctx context.Context) ([]int, error) {
+	conn, err := kafka.DialContext(ctx, "tcp", r.broker)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	partitions, err := conn.ReadPartitions(r.topic)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]int, len(partitions))
+	for i, p := range partitions {
+		ids[i] = p.ID
+	}
+	return ids, nil
+}
+
+// Read calls fn for the messages of rng in offset order, partition by
+// partition, until fn returns false.
+func (r *DLQReader) Read(ctx context.Context, rng DLQRange, fn func(DeadLetter) bool) error {
+	partitions := rng.Partitions
+	if len(partitions) == 0 {
+		var err error
+		if partitions, err = r.Partitions(ctx); err != nil {
+			return err
+		}
+	}
+
+	for _, partition := range partitions {
+		start, end, err := r.bounds(ctx, partition, rng)
+		if err != nil {
+			return err
+		}
+		err = r.readPartition(ctx, partition, start, end, fn)
+		if errors.Is(err, errStopReading) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// bounds resolves rng to the offsets [start, end) of a partition.
+func (r *DLQReader) bounds(ctx context.Context, partition int, rng DLQRange) (int64, int64, error) {
+	conn, err := kafka.DialLeader(ctx, "tcp", r.broker, r.topic, partition)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer conn.Close()
+
+	first, last, err := conn.ReadOffsets()
+	if err != nil {
+		return 0, 0, err
+	}
+	// a time after the last message has no offset and resolves to the end
+	timeOffset := func(t time.Time) (int64, error) {
+		offset, err := conn.ReadOffset(t)
+		if err != nil || offset < 0 {
+			return last, err
+		}
+		return offset, nil
+	}
+
+	start, end := max(first, rng.FromOffset), last
+	if rng.ToOffset > 0 {
+		end = min(end, rng.ToOffset)
+	}
+	if !rng.From.IsZero() {
+		offset, err := timeOffset(rng.From)
+		if err != nil {
+			return 0, 0, err
+		}
+		start = max(start, offset)
+	}
+	if !rng.To.IsZero() {
+		offset, err := timeOffset(rng.To)
+		if err != nil {
+			return 0, 0, err
+		}
+		end = min(end, offset)
+	}
+	return start, end, nil
+}
+
+func (r *DLQReader) readPartition(ctx context.Context, partition int, start, end int64, fn func(DeadLetter) bool) error {
+	if start >= end {
+		return nil
+	}
+
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers:   []string{r.broker},
+		Topic:     r.topic,
+		Partition: partition,
+		MaxWait:   500 * time.Millisecond,
+	})
+	defer reader.Close()
+
+	if err := reader.SetOffset(start); err != nil {
+		return err
+	}
+	for {
+		msg, err := reader.ReadMessage(ctx)
+		if err != nil {
+			return err
+		}
+		if msg.Offset >= end {
+			return nil
+		}
+		if !fn(newDeadLetter(msg)) {
+			return errStopReading
+		}
+		if msg.Offset+1 >= end {
+			return nil
+		}
+	}
+}
+
+// DLQFailure is the failure described by the dead letter headers. Messages
+// dead-lettered before the headers existed have a zero DLQFailure.
+type DLQFailure struct {
+	ErrorClass      ErrorClass
+	ErrorMessage    string
+	Reason          string
+	SourceTopic     string
+	SourcePartition int
+	SourceOffset    int64
+	Attempts        int
+	ConsumerGroup   string
+	ServiceVersion  string
+	DeadLetteredAt  time.Time
+}
+
+func (d DeadLetter) Failure() DLQFailure {
+	f := DLQFailure{
+		ErrorClass:     d.ErrorClass(),
+		ErrorMessage:   d.Header(HeaderDLQErrorMessage),
+		Reason:         d.Header(HeaderDLQReason),
+		SourceTopic:    d.Header(HeaderDLQSourceTopic),
+		Attempts:       d.Attempts(),
+		ConsumerGroup:  d.Header(HeaderDLQConsumerGroup),
+		ServiceVersion: d.Header(HeaderDLQServiceVersion),
+	}
+	f.SourcePartition, _ = strconv.Atoi(d.Header(HeaderDLQSourcePartition))
+	f.SourceOffset, _ = strconv.ParseInt(d.Header(HeaderDLQSourceOffset), 10, 64)
+	f.DeadLetteredAt, _ = time.Parse(time.RFC3339Nano, d.Header(HeaderDLQTimestamp))
+	return f
+}
@@ -2,13 +2,21 @@ package kafka
 
 import (
 	"context"
+	"order-service/internal/config"
 	"order-service/internal/infra/broker/handler"
+	"time"
 )
 
 type Handler interface {
-	ProcessOrderMessage(ctx context.Context, msg []byte) handler.Result
+	ProcessOrderMessage(ctx context.Context, msg []byte) (handler.Result, error)
+	ProcessOrderBatch(ctx context.Context, batch [][]byte) ([]handler.Result, []error)
+	ProcessUpdateMessage(ctx context.Context, msg []byte) (handler.Result, error)
 }
 
+// RetryHandler schedules the retries of failed messages. NextAttempt tells
+// the index of the tier a retry goes to and reports false once a message has
+// no retries left.
 type RetryHandler interface {
-	RetryWrapper(ctx context.Context, fn func() handler.Result) handler.Result
+	Tiers() []config.RetryTier
+	NextAttempt(attempt int, now time.Time) (tier int, notBefore time.Time, ok bool)
 }
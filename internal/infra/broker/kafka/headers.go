@@ -0,0 +1,211 @@
+package kafka
+
+import (
+	"encoding/json"
+	"errors"
+	"order-service/internal/domain"
+	"order-service/internal/infra/broker/handler"
+	"order-service/internal/usecase"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	kafka "github.com/segmentio/kafka-go"
+)
+
+const (
+	// HeaderValidationErrors carries a JSON array of domain.Violation for
+	// messages rejected by domain validation.
+	HeaderValidationErrors = "x-validation-errors"
+	// HeaderMessageType tells retry readers which handler a message belongs
+	// to. Messages without it are order creations.
+	HeaderMessageType = "x-message-type"
+	// HeaderConflictDiff carries a JSON array of domain.FieldDiff for orders
+	// whose uid is already stored with different content.
+	HeaderConflictDiff = "x-conflict-diff"
+	// HeaderRetryAttempt is the number of the retry a retry topic message
+	// is delivered for, starting at 1.
+	HeaderRetryAttempt = "x-retry-attempt"
+	// HeaderFirstFailure is the RFC 3339 time the message first failed.
+	HeaderFirstFailure = "x-first-failure"
+	// HeaderNotBefore is the RFC 3339 time before which a retry topic
+	// message is not processed.
+	HeaderNotBefore = "x-not-before"
+)
+
+const MessageTypeUpdate = "order-update"
+
+// Dead letter headers describe why a message was dead-lettered. Every DLQ
+// message carries all of them as UTF-8 strings, next to the headers the
+// message had and HeaderValidationErrors or HeaderConflictDiff when they
+// apply. The names and values are stable within a DLQFormatVersion.
+const (
+	// HeaderDLQFormat is the DLQFormatVersion the headers follow.
+	HeaderDLQFormat = "x-dlq-format"
+	// HeaderDLQErrorClass is one of the ErrorClass constants.
+	HeaderDLQErrorClass = "x-dlq-error-class"
+	// HeaderDLQErrorMessage is the error text, cut to maxDLQErrorMessage bytes.
+	HeaderDLQErrorMessage = "x-dlq-error-message"
+	// HeaderDLQReason is the handler.Result that sent the message to the DLQ:
+	// "dlq" when the handler rejected it, "retry" when it ran out of retries.
+	HeaderDLQReason = "x-dlq-reason"
+	// HeaderDLQSourceTopic, HeaderDLQSourcePartition and HeaderDLQSourceOffset
+	// locate the message that failed last, in the order, update or retry topic.
+	HeaderDLQSourceTopic     = "x-dlq-source-topic"
+	HeaderDLQSourcePartition = "x-dlq-source-partition"
+	HeaderDLQSourceOffset    = "x-dlq-source-offset"
+	// HeaderDLQAttempts is how many times the message was processed,
+	// the first delivery included.
+	HeaderDLQAttempts = "x-dlq-attempts"
+	// HeaderDLQConsumerGroup is the consumer group that read the source message.
+	HeaderDLQConsumerGroup = "x-dlq-consumer-group"
+	// HeaderDLQServiceVersion is the APP_VERSION of the service.
+	HeaderDLQServiceVersion = "x-dlq-service-version"
+	// HeaderDLQTimestamp is the RFC 3339 time the message was dead-lettered.
+	HeaderDLQTimestamp = "x-dlq-timestamp"
+)
+
+const DLQFormatVersion = "1"
+
+const maxDLQErrorMessage = 1024
+
+type ErrorClass string
+
+const (
+	// ErrorClassMalformed is a message that is not valid order JSON.
+	ErrorClassMalformed ErrorClass = "malformed"
+	// ErrorClassValidation is an order rejected by domain validation.
+	ErrorClassValidation ErrorClass = "validation"
+	// ErrorClassConflict is an order whose uid is stored with other content.
+	ErrorClassConflict ErrorClass = "conflict"
+	// ErrorClassInvalidState is any other domain rule violation.
+	ErrorClassInvalidState ErrorClass = "invalid_state"
+	// ErrorClassInternal covers the remaining errors, such as storage failures.
+	ErrorClassInternal ErrorClass = "internal"
+)
+
+func classifyError(err error) ErrorClass {
+	if _, ok := domain.AsValidationError(err); ok {
+		return ErrorClassValidation
+	}
+	if _, ok := usecase.AsConflictError(err); ok {
+		return ErrorClassConflict
+	}
+	switch {
+	case errors.Is(err, handler.ErrMalformedMessage):
+		return ErrorClassMalformed
+	case errors.Is(err, domain.ErrInvalidState):
+		return ErrorClassInvalidState
+	default:
+		return ErrorClassInternal
+	}
+}
+
+// deadLetter describes a message that is dead-lettered.
+type deadLetter struct {
+	source         kafka.Message
+	reason         handler.Result
+	err            error
+	attempts       int
+	group          string
+	version        string
+	deadLetteredAt time.Time
+}
+
+func (d deadLetter) headers() []kafka.Header {
+	var msg string
+	if d.err != nil {
+		msg = d.err.Error()
+	}
+	if len(msg) > maxDLQErrorMessage {
+		msg = strings.ToValidUTF8(msg[:maxDLQErrorMessage], "")
+	}
+
+	headers := []kafka.Header{
+		{Key: HeaderDLQFormat, Value: []byte(DLQFormatVersion)},
+		{Key: HeaderDLQErrorClass, Value: []byte(classifyError(d.err))},
+		{Key: HeaderDLQErrorMessage, Value: []byte(msg)},
+		{Key: HeaderDLQReason, Value: []byte(d.reason.String())},
+		{Key: HeaderDLQSourceTopic, Value: []byte(d.source.Topic)},
+		{Key: HeaderDLQSourcePartition, Value: []byte(strconv.Itoa(d.source.Partition))},
+		{Key: HeaderDLQSourceOffset, Value: []byte(strconv.FormatInt(d.source.Offset, 10))},
+		{Key: HeaderDLQAttempts, Value: []byte(strconv.Itoa(d.attempts))},
+		{Key: HeaderDLQConsumerGroup, Value: []byte(d.group)},
+		{Key: HeaderDLQServiceVersion, Value: []byte(d.version)},
+		{Key: HeaderDLQTimestamp, Value: []byte(d.deadLetteredAt.UTC().Format(time.RFC3339Nano))},
+	}
+	return append(headers, dlqHeaders(d.err)...)
+}
+
+func headerValue(headers []kafka.Header, key string) string {
+	for _, h := range headers {
+		if h.Key == key {
+			return string(h.Value)
+		}
+	}
+	return ""
+}
+
+func isUpdateMsg(msg kafka.Message) bool {
+	return headerValue(msg.Headers, HeaderMessageType) == MessageTypeUpdate
+}
+
+// retryState is carried in the headers of a message across retry topic
+// redeliveries. The zero value is a message that has not failed yet.
+type retryState struct {
+	attempt      int
+	firstFailure time.Time
+	notBefore    time.Time
+}
+
+// readRetryState parses the retry headers of msg. Missing or malformed
+// headers read as zero values.
+func readRetryState(headers []kafka.Header) retryState {
+	var s retryState
+	s.attempt, _ = strconv.Atoi(headerValue(headers, HeaderRetryAttempt))
+	s.firstFailure, _ = time.Parse(time.RFC3339Nano, headerValue(headers, HeaderFirstFailure))
+	s.notBefore, _ = time.Parse(time.RFC3339Nano, headerValue(headers, HeaderNotBefore))
+	return s
+}
+
+func (s retryState) headers() []kafka.Header {
+	headers := []kafka.Header{
+		{Key: HeaderRetryAttempt, Value: []byte(strconv.Itoa(s.attempt))},
+		{Key: HeaderFirstFailure, Value: []byte(s.firstFailure.UTC().Format(time.RFC3339Nano))},
+	}
+	if !s.notBefore.IsZero() {
+		headers = append(headers, kafka.Header{Key: HeaderNotBefore, Value: []byte(s.notBefore.UTC().Format(time.RFC3339Nano))})
+	}
+	return headers
+}
+
+// withHeaders returns headers with set added, replacing the headers that
+// have the same keys.
+func withHeaders(headers []kafka.Header, set ...kafka.Header) []kafka.Header {
+	out := make([]kafka.Header, 0, len(headers)+len(set))
+	for _, h := range headers {
+		if !slices.ContainsFunc(set, func(s kafka.Header) bool { return s.Key == h.Key }) {
+			out = append(out, h)
+		}
+	}
+	return append(out, set...)
+}
+
+func dlqHeaders(err error) []kafka.Header {
+	if vErr, ok := domain.AsValidationError(err); ok {
+		return jsonHeader(HeaderValidationErrors, vErr.Violations)
+	}
+	if cErr, ok := usecase.AsConflictError(err); ok {
+		return jsonHeader(HeaderConflictDiff, cErr.Diff)
+	}
+	return nil
+}
+
+func jsonHeader(key string, value any) []kafka.Header {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil
+	}
+	return []kafka.Header{{Key: key, Value: data}}
+}
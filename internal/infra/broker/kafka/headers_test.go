@@ -0,0 +1,89 @@
+package kafka
+
+import (
+	"errors"
+	"fmt"
+	"order-service/internal/domain"
+	"order-service/internal/infra/broker/handler"
+	"order-service/internal/infra/repo"
+	"order-service/internal/usecase"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	kafka "github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want ErrorClass
+	}{
+		{fmt.Errorf("%w: unexpected end of JSON input", handler.ErrMalformedMessage), ErrorClassMalformed},
+		{&domain.ValidationError{Violations: []domain.Violation{{Field: "order_uid"}}}, ErrorClassValidation},
+		{&usecase.ConflictError{OrderUID: "b563feb7b2b84b6test"}, ErrorClassConflict},
+		{domain.ErrInconsistentAmounts, ErrorClassInvalidState},
+		{fmt.Errorf("get order: %w", repo.ErrNotFound), ErrorClassInternal},
+		{errors.New("connection refused"), ErrorClassInternal},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, classifyError(tt.err), tt.err.Error())
+	}
+}
+
+func TestDeadLetterHeaders(t *testing.T) {
+	at := time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC)
+	dl := deadLetter{
+		source:         kafka.Message{Topic: "orders-retry", Partition: 2, Offset: 42},
+		reason:         handler.Retry,
+		err:            errors.New("connection refused"),
+		attempts:       4,
+		group:          "retry-group",
+		version:        "1.2.0",
+		deadLetteredAt: at,
+	}
+
+	headers := dl.headers()
+
+	for key, want := range map[string]string{
+		HeaderDLQFormat:          DLQFormatVersion,
+		HeaderDLQErrorClass:      string(ErrorClassInternal),
+		HeaderDLQErrorMessage:    "connection refused",
+		HeaderDLQReason:          "retry",
+		HeaderDLQSourceTopic:     "orders-retry",
+		HeaderDLQSourcePartition: "2",
+		HeaderDLQSourceOffset:    "42",
+		HeaderDLQAttempts:        "4",
+		HeaderDLQConsumerGroup:   "retry-group",
+		HeaderDLQServiceVersion:  "1.2.0",
+		HeaderDLQTimestamp:       "2025-10-01T12:00:00Z",
+	} {
+		assert.Equal(t, want, headerValue(headers, key), key)
+	}
+}
+
+func TestDeadLetterHeadersCutLongMessage(t *testing.T) {
+	dl := deadLetter{reason: handler.DLQ, err: errors.New(strings.Repeat("ошибка", 200))}
+
+	msg := headerValue(dl.headers(), HeaderDLQErrorMessage)
+
+	assert.LessOrEqual(t, len(msg), maxDLQErrorMessage)
+	assert.True(t, utf8.ValidString(msg))
+}
+
+func TestWithHeadersReplacesEmptyValues(t *testing.T) {
+	headers := []kafka.Header{
+		{Key: HeaderMessageType, Value: []byte(MessageTypeUpdate)},
+		{Key: HeaderDLQErrorMessage, Value: []byte("old error")},
+	}
+
+	got := withHeaders(headers, kafka.Header{Key: HeaderDLQErrorMessage, Value: nil})
+
+	assert.Equal(t, []kafka.Header{
+		{Key: HeaderMessageType, Value: []byte(MessageTypeUpdate)},
+		{Key: HeaderDLQErrorMessage, Value: nil},
+	}, got)
+}
@@ -0,0 +1,76 @@
+package kafka
+
+import (
+	"context"
+	"order-service/internal/infra/repo"
+
+	kafka "github.com/segmentio/kafka-go"
+)
+
+// OffsetStore keeps the consumed offsets next to the orders, so that a
+// message and the order it writes are committed in one transaction. See
+// config.OffsetStorePostgres.
+type OffsetStore interface {
+	NextOffset(ctx context.Context, group, topic string, partition int) (int64, error)
+	CommitOffsets(ctx context.Context, offsets []repo.ConsumedOffset) error
+}
+
+// storeCommitter commits offsets to the offset store and then to the
+// consumer group, so the group's offsets may lag behind the store's but never
+// run ahead of them.
+type storeCommitter struct {
+	group  string
+	store  OffsetStore
+	reader committer
+}
+
+func (c *storeCommitter) CommitMessages(ctx context.Context, msgs ...kafka.Message) error {
+	offsets := make([]repo.ConsumedOffset, len(msgs))
+	for i, msg := range msgs {
+		offsets[i] = consumedOffset(c.group, msg)
+	}
+	if err := c.store.CommitOffsets(ctx, offsets); err != nil {
+		return err
+	}
+	return c.reader.CommitMessages(ctx, msgs...)
+}
+
+func consumedOffset(group string, msg kafka.Message) repo.ConsumedOffset {
+	return repo.ConsumedOffset{Group: group, Topic: msg.Topic, Partition: msg.Partition, Offset: msg.Offset}
+}
+
+type gatePartition struct {
+	next int64
+	last int64
+}
+
+// offsetGate tells which fetched messages are below the offsets committed to
+// the offset store, so that readers resume from the store even when the
+// consumer group lags behind it. The committed offset of a partition is read
+// when the partition is first fetched and again whenever it is fetched from
+// an earlier offset, as after a restart or a rebalance. A gate belongs to one
+// reader and is used by its fetching goroutine only.
+type offsetGate struct {
+	group      string
+	store      OffsetStore
+	partitions map[int]*gatePartition
+}
+
+func newOffsetGate(group string, store OffsetStore) *offsetGate {
+	return &offsetGate{group: group, store: store, partitions: make(map[int]*gatePartition)}
+}
+
+func (g *offsetGate) consumed(ctx context.Context, msg kafka.Message) (bool, error) {
+	p, ok := g.partitions[msg.Partition]
+	if !ok || msg.Offset <= p.last {
+		next, err := g.store.NextOffset(ctx, g.group, msg.Topic, msg.Partition)
+		if err != nil {
+			return false, err
+		}
+		p = &gatePartition{next: next}
+		g.partitions[msg.Partition] = p
+	}
+	p.last = msg.Offset
+
+	return msg.Offset < p.next, nil
+}
@@ -0,0 +1,95 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"order-service/internal/infra/repo"
+	"order-service/internal/lib/logger"
+	"testing"
+	"time"
+
+	kafka "github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type MockOffsetStore struct {
+	next      map[int]int64
+	reads     int
+	committed []repo.ConsumedOffset
+	err       error
+}
+
+func (m *MockOffsetStore) NextOffset(ctx context.Context, group, topic string, partition int) (int64, error) {
+	m.reads++
+	return m.next[partition], nil
+}
+
+func (m *MockOffsetStore) CommitOffsets(ctx context.Context, offsets []repo.ConsumedOffset) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.committed = append(m.committed, offsets...)
+	return nil
+}
+
+func TestStoreCommitter(t *testing.T) {
+	store := &MockOffsetStore{}
+	reader := &MockCommitter{committed: map[int]int64{}}
+	c := &storeCommitter{group: "orders-group", store: store, reader: reader}
+	msg := kafka.Message{Topic: "orders", Partition: 1, Offset: 41}
+
+	require.NoError(t, c.CommitMessages(context.Background(), msg))
+	assert.Equal(t, []repo.ConsumedOffset{{Group: "orders-group", Topic: "orders", Partition: 1, Offset: 41}}, store.committed)
+	assert.Equal(t, map[int]int64{1: 41}, reader.committed)
+
+	store.err = errors.New("db unavailable")
+	msg.Offset = 42
+	assert.ErrorIs(t, c.CommitMessages(context.Background(), msg), store.err)
+	assert.Equal(t, map[int]int64{1: 41}, reader.committed, "the group must not run ahead of the store")
+}
+
+func TestOffsetGate(t *testing.T) {
+	store := &MockOffsetStore{next: map[int]int64{0: 10}}
+	gate := newOffsetGate("orders-group", store)
+	ctx := context.Background()
+	consumed := func(partition int, offset int64) bool {
+		ok, err := gate.consumed(ctx, kafka.Message{Topic: "orders", Partition: partition, Offset: offset})
+		require.NoError(t, err)
+		return ok
+	}
+
+	assert.True(t, consumed(0, 8))
+	assert.True(t, consumed(0, 9))
+	assert.False(t, consumed(0, 10))
+	assert.False(t, consumed(1, 0))
+	assert.Equal(t, 2, store.reads)
+
+	// read again from an earlier offset, the store has moved on meanwhile
+	store.next[0] = 12
+	assert.True(t, consumed(0, 10))
+	assert.True(t, consumed(0, 11))
+	assert.False(t, consumed(0, 12))
+	assert.Equal(t, 3, store.reads)
+}
+
+func TestWorkerPoolSkipCommitsInOrder(t *testing.T) {
+	l, err := logger.InitLogger("test")
+	require.NoError(t, err)
+
+	reader := &MockCommitter{committed: map[int]int64{}}
+	release := make(chan struct{})
+	pool := newWorkerPool("test", reader, func(ctx context.Context, msg kafka.Message) error {
+		<-release
+		return nil
+	}, 1, 1, time.Hour, l)
+	ctx := context.Background()
+
+	require.NoError(t, pool.dispatch(ctx, kafka.Message{Topic: "orders", Offset: 0}))
+	pool.skip(ctx, kafka.Message{Topic: "orders", Offset: 1})
+	assert.Empty(t, pool.commits.offsets.committable(), "a skipped message must wait for the ones before it")
+
+	close(release)
+	pool.shutdown()
+	assert.Equal(t, map[int]int64{0: 1}, reader.committed)
+}
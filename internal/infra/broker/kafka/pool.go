@@ -0,0 +1,325 @@
+package kafka
+
+import (
+	"context"
+	"hash/fnv"
+	"log/slog"
+	"sync"
+	"time"
+
+	kafka "github.com/segmentio/kafka-go"
+)
+
+const (
+	defaultCommitInterval = time.Second
+	processRetryDelay     = time.Second
+	finalCommitTimeout    = 5 * time.Second
+)
+
+type committer interface {
+	CommitMessages(ctx context.Context, msgs ...kafka.Message) error
+}
+
+// workerPool processes the messages of one reader concurrently. Messages with
+// the same key, or without a key but from the same partition, go to the same
+// worker and are processed in fetch order. Offsets are committed only up to
+// the highest contiguous processed offset of each partition.
+type workerPool struct {
+	name    string
+	process func(ctx context.Context, msg kafka.Message) error
+	logger  *slog.Logger
+	queues  []chan kafka.Message
+	commits *offsetCommitter
+
+	start    sync.Once
+	workers  sync.WaitGroup
+	stopOnce sync.Once
+}
+
+func newWorkerPool(name string, reader committer, process func(ctx context.Context, msg kafka.Message) error,
+	workers, queueSize int, commitInterval time.Duration, logger *slog.Logger) *workerPool {
+	if workers < 1 {
+		workers = 1
+	}
+	queues := make([]chan kafka.Message, workers)
+	for i := range queues {
+		queues[i] = make(chan kafka.Message, queueSize)
+	}
+
+	return &workerPool{
+		name:    name,
+		process: process,
+		logger:  logger,
+		queues:  queues,
+		commits: newOffsetCommitter(name, reader, commitInterval, logger),
+	}
+}
+
+// dispatch hands msg to its worker, blocking while the worker's queue is full.
+func (p *workerPool) dispatch(ctx context.Context, msg kafka.Message) error {
+	p.start.Do(func() { p.run(ctx) })
+
+	p.commits.offsets.track(msg)
+	select {
+	case p.queues[p.workerFor(msg)] <- msg:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// skip commits msg in order with the other messages without processing it.
+func (p *workerPool) skip(ctx context.Context, msg kafka.Message) {
+	p.start.Do(func() { p.run(ctx) })
+
+	p.commits.skip(msg)
+}
+
+// track registers msgs handled outside the workers, as by the batch reader.
+// They are committed in order with the other messages once marked done.
+func (p *workerPool) track(ctx context.Context, msgs ...kafka.Message) {
+	p.start.Do(func() { p.run(ctx) })
+
+	for _, msg := range msgs {
+		p.commits.offsets.track(msg)
+	}
+}
+
+func (p *workerPool) done(msg kafka.Message) {
+	p.commits.offsets.done(msg)
+}
+
+func (p *workerPool) workerFor(msg kafka.Message) int {
+	if len(msg.Key) == 0 {
+		return msg.Partition % len(p.queues)
+	}
+	h := fnv.New32a()
+	_, _ = h.Write(msg.Key)
+	return int(h.Sum32() % uint32(len(p.queues)))
+}
+
+func (p *workerPool) run(ctx context.Context) {
+	for _, q := range p.queues {
+		p.workers.Add(1)
+		go p.work(ctx, q)
+	}
+	p.commits.run(ctx)
+}
+
+func (p *workerPool) work(ctx context.Context, queue <-chan kafka.Message) {
+	defer p.workers.Done()
+
+	for msg := range queue {
+		if p.handle(ctx, msg) {
+			p.commits.offsets.done(msg)
+		}
+	}
+}
+
+// handle processes msg until it succeeds or ctx is done. A message that was
+// not handled is left uncommitted and is delivered again after a restart.
+func (p *workerPool) handle(ctx context.Context, msg kafka.Message) bool {
+	return untilDone(ctx, func() error { return p.process(ctx, msg) }, func(err error) {
+		p.logger.Error("failed to handle message", "reader", p.name, "error", err,
+			"partition", msg.Partition, "offset", msg.Offset, "key", string(msg.Key))
+	})
+}
+
+// untilDone calls fn until it succeeds or ctx is done, reporting each failure
+// to onErr, and tells whether fn succeeded.
+func untilDone(ctx context.Context, fn func() error, onErr func(error)) bool {
+	for {
+		err := fn()
+		if err == nil {
+			return true
+		}
+		if ctx.Err() != nil {
+			return false
+		}
+
+		onErr(err)
+		select {
+		case <-time.After(processRetryDelay):
+		case <-ctx.Done():
+			return false
+		}
+	}
+}
+
+// shutdown waits for the queued messages and commits what was processed.
+// No message may be dispatched after it is called.
+func (p *workerPool) shutdown() {
+	p.stopOnce.Do(func() {
+		for _, q := range p.queues {
+			close(q)
+		}
+		p.workers.Wait()
+		p.commits.close()
+	})
+}
+
+// offsetCommitter periodically commits the offsets its tracker reports as
+// committable, and once more when it is closed.
+type offsetCommitter struct {
+	name     string
+	reader   committer
+	logger   *slog.Logger
+	offsets  *offsetTracker
+	interval time.Duration
+
+	start    sync.Once
+	stopOnce sync.Once
+	stop     chan struct{}
+	stopped  chan struct{}
+}
+
+func newOffsetCommitter(name string, reader committer, interval time.Duration, logger *slog.Logger) *offsetCommitter {
+	if interval <= 0 {
+		interval = defaultCommitInterval
+	}
+
+	return &offsetCommitter{
+		name:     name,
+		reader:   reader,
+		logger:   logger,
+		offsets:  newOffsetTracker(),
+		interval: interval,
+		stop:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+}
+
+func (c *offsetCommitter) run(ctx context.Context) {
+	c.start.Do(func() { go c.loop(ctx) })
+}
+
+func (c *offsetCommitter) loop(ctx context.Context) {
+	defer close(c.stopped)
+
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			c.commit(ctx)
+		case <-c.stop:
+			return
+		}
+	}
+}
+
+func (c *offsetCommitter) commit(ctx context.Context) {
+	msgs := c.offsets.committable()
+	if len(msgs) == 0 {
+		return
+	}
+	if err := c.reader.CommitMessages(ctx, msgs...); err != nil {
+		c.logger.Error("failed to commit offsets", "reader", c.name, "error", err)
+
+		return
+	}
+	c.offsets.committed(msgs)
+}
+
+// skip marks msg processed as soon as it is fetched.
+func (c *offsetCommitter) skip(msg kafka.Message) {
+	c.offsets.track(msg)
+	c.offsets.done(msg)
+}
+
+// close stops the commit loop and commits what was processed.
+func (c *offsetCommitter) close() {
+	c.stopOnce.Do(func() {
+		started := true
+		c.start.Do(func() { started = false })
+		if started {
+			close(c.stop)
+			<-c.stopped
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), finalCommitTimeout)
+		defer cancel()
+		c.commit(ctx)
+	})
+}
+
+type topicPartition struct {
+	topic     string
+	partition int
+}
+
+type partitionOffsets struct {
+	// inflight holds fetched offsets that are not committable yet, in fetch order.
+	inflight []int64
+	done     map[int64]bool
+	// ready is the highest offset whose predecessors are all processed.
+	ready *kafka.Message
+}
+
+type offsetTracker struct {
+	mu         sync.Mutex
+	partitions map[topicPartition]*partitionOffsets
+}
+
+func newOffsetTracker() *offsetTracker {
+	return &offsetTracker{partitions: make(map[topicPartition]*partitionOffsets)}
+}
+
+func (t *offsetTracker) track(msg kafka.Message) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	tp := topicPartition{msg.Topic, msg.Partition}
+	po, ok := t.partitions[tp]
+	// an offset that is not increasing means the partition was reassigned
+	// and is read again from the last commit
+	if !ok || (len(po.inflight) > 0 && msg.Offset <= po.inflight[len(po.inflight)-1]) {
+		po = &partitionOffsets{done: make(map[int64]bool)}
+		t.partitions[tp] = po
+	}
+	po.inflight = append(po.inflight, msg.Offset)
+}
+
+func (t *offsetTracker) done(msg kafka.Message) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	po, ok := t.partitions[topicPartition{msg.Topic, msg.Partition}]
+	if !ok {
+		return
+	}
+	po.done[msg.Offset] = true
+
+	for len(po.inflight) > 0 && po.done[po.inflight[0]] {
+		offset := po.inflight[0]
+		delete(po.done, offset)
+		po.inflight = po.inflight[1:]
+		po.ready = &kafka.Message{Topic: msg.Topic, Partition: msg.Partition, Offset: offset}
+	}
+}
+
+func (t *offsetTracker) committable() []kafka.Message {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var msgs []kafka.Message
+	for _, po := range t.partitions {
+		if po.ready != nil {
+			msgs = append(msgs, *po.ready)
+		}
+	}
+	return msgs
+}
+
+func (t *offsetTracker) committed(msgs []kafka.Message) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, msg := range msgs {
+		po, ok := t.partitions[topicPartition{msg.Topic, msg.Partition}]
+		if ok && po.ready != nil && po.ready.Offset == msg.Offset {
+			po.ready = nil
+		}
+	}
+}
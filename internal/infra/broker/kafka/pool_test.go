@@ -0,0 +1,111 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"order-service/internal/lib/logger"
+	"sync"
+	"testing"
+	"time"
+
+	kafka "github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type MockCommitter struct {
+	mu        sync.Mutex
+	committed map[int]int64
+}
+
+func (m *MockCommitter) CommitMessages(ctx context.Context, msgs ...kafka.Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, msg := range msgs {
+		m.committed[msg.Partition] = msg.Offset
+	}
+	return nil
+}
+
+func TestOffsetTrackerContiguous(t *testing.T) {
+	tracker := newOffsetTracker()
+	msg := func(offset int64) kafka.Message {
+		return kafka.Message{Topic: "orders", Partition: 0, Offset: offset}
+	}
+	for _, offset := range []int64{10, 11, 13, 14} {
+		tracker.track(msg(offset))
+	}
+
+	tracker.done(msg(11))
+	tracker.done(msg(13))
+	assert.Empty(t, tracker.committable())
+
+	tracker.done(msg(10))
+	assert.Equal(t, []kafka.Message{msg(13)}, tracker.committable())
+
+	tracker.committed([]kafka.Message{msg(13)})
+	assert.Empty(t, tracker.committable())
+
+	tracker.done(msg(14))
+	assert.Equal(t, []kafka.Message{msg(14)}, tracker.committable())
+}
+
+func TestOffsetTrackerReassignedPartition(t *testing.T) {
+	tracker := newOffsetTracker()
+	msg := func(offset int64) kafka.Message {
+		return kafka.Message{Topic: "orders", Partition: 0, Offset: offset}
+	}
+	tracker.track(msg(5))
+	tracker.track(msg(6))
+
+	// partition read again from the last commit
+	tracker.track(msg(5))
+	tracker.done(msg(5))
+	assert.Equal(t, []kafka.Message{msg(5)}, tracker.committable())
+}
+
+func TestWorkerPoolPreservesKeyOrder(t *testing.T) {
+	l, err := logger.InitLogger("test")
+	require.NoError(t, err)
+
+	var (
+		mu        sync.Mutex
+		processed = make(map[string][]int64)
+		failed    bool
+	)
+	process := func(ctx context.Context, msg kafka.Message) error {
+		// the first attempt of one message fails and is retried
+		mu.Lock()
+		defer mu.Unlock()
+		if msg.Partition == 1 && msg.Offset == 10 && !failed {
+			failed = true
+			return errors.New("temporary error")
+		}
+		processed[string(msg.Key)] = append(processed[string(msg.Key)], msg.Offset)
+		return nil
+	}
+
+	reader := &MockCommitter{committed: make(map[int]int64)}
+	pool := newWorkerPool("test", reader, process, 4, 8, 10*time.Millisecond, l)
+
+	const perPartition = 30
+	ctx := context.Background()
+	for offset := int64(0); offset < perPartition; offset++ {
+		for partition := 0; partition < 3; partition++ {
+			key := fmt.Sprintf("order-%d", offset%5)
+			require.NoError(t, pool.dispatch(ctx, kafka.Message{Topic: "orders", Partition: partition, Offset: offset, Key: []byte(key)}))
+		}
+	}
+	pool.shutdown()
+
+	total := 0
+	for key, offsets := range processed {
+		total += len(offsets)
+		for i := 1; i < len(offsets); i++ {
+			assert.LessOrEqual(t, offsets[i-1], offsets[i], "key %s processed out of order", key)
+		}
+	}
+	assert.Equal(t, 3*perPartition, total)
+	assert.Equal(t, map[int]int64{0: perPartition - 1, 1: perPartition - 1, 2: perPartition - 1}, reader.committed)
+}
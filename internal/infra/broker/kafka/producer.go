@@ -0,0 +1,34 @@
+package kafka
+
+import (
+	"context"
+
+	kafka "github.com/segmentio/kafka-go"
+)
+
+// OrderProducer writes order messages to the orders topic for the consumer,
+// so orders received over HTTP take the path of the ones produced to Kafka.
+type OrderProducer struct {
+	writer *kafka.Writer
+}
+
+func NewOrderProducer(broker, topic string) *OrderProducer {
+	return &OrderProducer{
+		writer: &kafka.Writer{
+			Addr:         kafka.TCP(broker),
+			Topic:        topic,
+			Balancer:     &kafka.Hash{},
+			RequiredAcks: kafka.RequireAll,
+		},
+	}
+}
+
+// EnqueueOrder writes an order message keyed by key, the order uid, so the
+// messages of one order land in one partition.
+func (p *OrderProducer) EnqueueOrder(ctx context.Context, key, value []byte) error {
+	return p.writer.WriteMessages(ctx, kafka.Message{Key: key, Value: value})
+}
+
+func (p *OrderProducer) Close() error {
+	return p.writer.Close()
+}
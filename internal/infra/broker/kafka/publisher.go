@@ -0,0 +1,69 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"order-service/internal/infra/repo"
+	"strconv"
+
+	kafka "github.com/segmentio/kafka-go"
+)
+
+const (
+	// HeaderEventID carries the outbox id of an order event. Delivery is at
+	// least once, so consumers should deduplicate on it.
+	HeaderEventID = "x-event-id"
+	// HeaderEventType carries the domain.EventType of an order event.
+	HeaderEventType = "x-event-type"
+)
+
+// EventPublisher writes outbox events to the order events topic. Messages are
+// keyed by order uid, so the events of one order land in one partition.
+type EventPublisher struct {
+	writer *kafka.Writer
+}
+
+func NewEventPublisher(broker, topic string) *EventPublisher {
+	return &EventPublisher{
+		writer: &kafka.Writer{
+			Addr:         kafka.TCP(broker),
+			Topic:        topic,
+			Balancer:     &kafka.Hash{},
+			RequiredAcks: kafka.RequireAll,
+		},
+	}
+}
+
+func (p *EventPublisher) Publish(ctx context.Context, msgs []repo.OutboxMessage) []error {
+	kafkaMsgs := make([]kafka.Message, len(msgs))
+	for i, msg := range msgs {
+		kafkaMsgs[i] = kafka.Message{
+			Key:   []byte(msg.OrderUID),
+			Value: msg.Payload,
+			Headers: []kafka.Header{
+				{Key: HeaderEventID, Value: []byte(strconv.FormatInt(msg.Id, 10))},
+				{Key: HeaderEventType, Value: []byte(msg.Type)},
+			},
+		}
+	}
+
+	errs := make([]error, len(msgs))
+	err := p.writer.WriteMessages(ctx, kafkaMsgs...)
+	if err == nil {
+		return errs
+	}
+
+	var writeErrs kafka.WriteErrors
+	if errors.As(err, &writeErrs) && len(writeErrs) == len(msgs) {
+		copy(errs, writeErrs)
+		return errs
+	}
+	for i := range errs {
+		errs[i] = err
+	}
+	return errs
+}
+
+func (p *EventPublisher) Close() error {
+	return p.writer.Close()
+}
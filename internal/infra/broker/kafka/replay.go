@@ -0,0 +1,192 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"order-service/internal/config"
+	"strings"
+
+	kafka "github.com/segmentio/kafka-go"
+)
+
+// HeaderReplayedFrom marks a replayed message with the DLQ position it was
+// replayed from, as topic/partition/offset.
+const HeaderReplayedFrom = "x-replayed-from"
+
+// ReplayTarget is where replayed messages are published.
+type ReplayTarget string
+
+const (
+	// ReplayToSource publishes orders to the order topic and updates to the
+	// update topic, as if they arrived again.
+	ReplayToSource ReplayTarget = "orders"
+	// ReplayToRetry publishes to the first retry tier with no retries made,
+	// due at once.
+	ReplayToRetry ReplayTarget = "retry"
+)
+
+const (
+	DefaultReplayLimit = 1000
+	MaxReplayLimit     = 10000
+)
+
+var ErrInvalidReplay = errors.New("invalid replay request")
+
+type ReplayRequest struct {
+	Range  DLQRange
+	Filter DLQFilter
+	Target ReplayTarget
+	// DryRun reports the matching messages without publishing them.
+	DryRun bool
+	// Limit caps the number of replayed messages, DefaultReplayLimit when 0.
+	Limit int
+}
+
+type ReplayedMessage struct {
+	Partition  int        `json:"partition"`
+	Offset     int64      `json:"offset"`
+	OrderUID   string     `json:"order_uid"`
+	ErrorClass ErrorClass `json:"error_class"`
+	Topic      string     `json:"topic"`
+}
+
+type ReplayReport struct {
+	DryRun  bool `json:"dry_run"`
+	Scanned int  `json:"scanned"`
+	// Truncated tells that the range has more matching messages than Limit.
+	Truncated bool              `json:"truncated"`
+	Replayed  []ReplayedMessage `json:"replayed"`
+}
+
+type dlqSource interface {
+	Read(ctx context.Context, rng DLQRange, fn func(DeadLetter) bool) error
+}
+
+type messageWriter interface {
+	WriteMessages(ctx context.Context, msgs ...kafka.Message) error
+	Close() error
+}
+
+// Replayer republishes DLQ messages once the cause of their failure is fixed.
+type Replayer struct {
+	reader      dlqSource
+	writer      messageWriter
+	dlqTopic    string
+	orderTopic  string
+	updateTopic string
+	retryTopic  string
+	logger      *slog.Logger
+}
+
+// NewReplayer replays the DLQ of cfg. retryTopic is the first retry tier.
+func NewReplayer(cfg *config.KafkaConfig, retryTopic string, logger *slog.Logger) *Replayer {
+	return &Replayer{
+		reader: NewDLQReader(cfg.Broker, cfg.DLQTopicCfg.KafkaTopic),
+		// the topic is set per message, by target
+		writer: &kafka.Writer{
+			Addr:         kafka.TCP(cfg.Broker),
+			Balancer:     &kafka.Hash{},
+			RequiredAcks: kafka.RequireAll,
+		},
+		dlqTopic:    cfg.DLQTopicCfg.KafkaTopic,
+		orderTopic:  cfg.OrderTopicCfg.KafkaTopic,
+		updateTopic: cfg.UpdateTopicCfg.KafkaTopic,
+		retryTopic:  retryTopic,
+		logger:      logger,
+	}
+}
+
+// Replay republishes the DLQ messages of req.Range that match req.Filter,
+// up to req.Limit. The messages are published together after the range is
+// read, so a failed replay publishes nothing.
+func (r *Replayer) Replay(ctx context.Context, req ReplayRequest) (ReplayReport, error) {
+	switch req.Target {
+	case ReplayToSource, ReplayToRetry:
+	default:
+		return ReplayReport{}, fmt.Errorf("unknown target %q: %w", req.Target, ErrInvalidReplay)
+	}
+	limit := req.Limit
+	switch {
+	case limit == 0:
+		limit = DefaultReplayLimit
+	case limit < 0 || limit > MaxReplayLimit:
+		return ReplayReport{}, fmt.Errorf("limit must be between 1 and %d: %w", MaxReplayLimit, ErrInvalidReplay)
+	}
+
+	report := ReplayReport{DryRun: req.DryRun, Replayed: []ReplayedMessage{}}
+	var msgs []kafka.Message
+	err := r.reader.Read(ctx, req.Range, func(d DeadLetter) bool {
+		report.Scanned++
+		if !req.Filter.Match(d) {
+			return true
+		}
+		if len(msgs) == limit {
+			report.Truncated = true
+			return false
+		}
+
+		msg := r.replayMessage(d, req.Target)
+		msgs = append(msgs, msg)
+		report.Replayed = append(report.Replayed, ReplayedMessage{
+			Partition:  d.Partition,
+			Offset:     d.Offset,
+			OrderUID:   d.OrderUID(),
+			ErrorClass: d.ErrorClass(),
+			Topic:      msg.Topic,
+		})
+		return true
+	})
+	if err != nil {
+		return ReplayReport{}, err
+	}
+
+	if req.DryRun || len(msgs) == 0 {
+		return report, nil
+	}
+	if err := r.writer.WriteMessages(ctx, msgs...); err != nil {
+		return ReplayReport{}, err
+	}
+	r.logger.Info("dlq messages replayed", "count", len(msgs), "target", req.Target)
+
+	return report, nil
+}
+
+// replayMessage builds the message that replays d. The failure headers are
+// dropped, so the message starts over with no retries made.
+func (r *Replayer) replayMessage(d DeadLetter, target ReplayTarget) kafka.Message {
+	topic := r.retryTopic
+	if target == ReplayToSource {
+		topic = r.orderTopic
+		if headerValue(d.Headers, HeaderMessageType) == MessageTypeUpdate {
+			topic = r.updateTopic
+		}
+	}
+
+	headers := make([]kafka.Header, 0, len(d.Headers)+1)
+	for _, h := range d.Headers {
+		if !isFailureHeader(h.Key) {
+			headers = append(headers, h)
+		}
+	}
+	headers = append(headers, kafka.Header{
+		Key:   HeaderReplayedFrom,
+		Value: fmt.Appendf(nil, "%s/%d/%d", r.dlqTopic, d.Partition, d.Offset),
+	})
+
+	return kafka.Message{Topic: topic, Key: d.Key, Value: d.Value, Headers: headers}
+}
+
+func isFailureHeader(key string) bool {
+	switch key {
+	case HeaderValidationErrors, HeaderConflictDiff, HeaderRetryAttempt, HeaderFirstFailure,
+		HeaderNotBefore, HeaderReplayedFrom:
+		return true
+	}
+	return strings.HasPrefix(key, "x-dlq-")
+}
+
+func (r *Replayer) Close() error {
+	return r.writer.Close()
+}
@@ -0,0 +1,159 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"order-service/internal/lib/logger"
+	"testing"
+
+	kafka "github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type MockDLQSource struct {
+	letters []DeadLetter
+	rng     DLQRange
+}
+
+func (m *MockDLQSource) Read(ctx context.Context, rng DLQRange, fn func(DeadLetter) bool) error {
+	m.rng = rng
+	for _, d := range m.letters {
+		if !fn(d) {
+			return nil
+		}
+	}
+	return nil
+}
+
+type MockWriter struct {
+	written []kafka.Message
+	err     error
+}
+
+func (m *MockWriter) WriteMessages(ctx context.Context, msgs ...kafka.Message) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.written = append(m.written, msgs...)
+	return nil
+}
+
+func (m *MockWriter) Close() error {
+	return nil
+}
+
+func testDeadLetter(offset int64, uid string, class ErrorClass, headers ...kafka.Header) DeadLetter {
+	headers = append(headers,
+		kafka.Header{Key: HeaderDLQErrorClass, Value: []byte(class)},
+		kafka.Header{Key: HeaderDLQAttempts, Value: []byte("3")},
+		kafka.Header{Key: HeaderRetryAttempt, Value: []byte("2")},
+		kafka.Header{Key: HeaderValidationErrors, Value: []byte("[]")},
+	)
+	return DeadLetter{
+		Offset:  offset,
+		Key:     []byte(uid),
+		Value:   []byte(`{"order_uid":"` + uid + `"}`),
+		Headers: headers,
+	}
+}
+
+func newTestReplayer(t *testing.T, source dlqSource, writer messageWriter) *Replayer {
+	l, err := logger.InitLogger("test")
+	require.NoError(t, err)
+
+	return &Replayer{
+		reader:      source,
+		writer:      writer,
+		dlqTopic:    "orders-dlq",
+		orderTopic:  "orders",
+		updateTopic: "order-updates",
+		retryTopic:  "orders-retry",
+		logger:      l,
+	}
+}
+
+func TestReplay(t *testing.T) {
+	update := kafka.Header{Key: HeaderMessageType, Value: []byte(MessageTypeUpdate)}
+	source := &MockDLQSource{letters: []DeadLetter{
+		testDeadLetter(0, "order-1", ErrorClassInternal),
+		testDeadLetter(1, "order-2", ErrorClassValidation),
+		testDeadLetter(2, "order-3", ErrorClassInternal, update),
+	}}
+	writer := &MockWriter{}
+	replayer := newTestReplayer(t, source, writer)
+
+	rng := DLQRange{FromOffset: 0, ToOffset: 10}
+	report, err := replayer.Replay(context.Background(), ReplayRequest{
+		Range:  rng,
+		Filter: DLQFilter{ErrorClass: ErrorClassInternal},
+		Target: ReplayToSource,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, rng, source.rng)
+	assert.Equal(t, 3, report.Scanned)
+	assert.False(t, report.Truncated)
+	assert.Equal(t, []ReplayedMessage{
+		{Offset: 0, OrderUID: "order-1", ErrorClass: ErrorClassInternal, Topic: "orders"},
+		{Offset: 2, OrderUID: "order-3", ErrorClass: ErrorClassInternal, Topic: "order-updates"},
+	}, report.Replayed)
+
+	require.Len(t, writer.written, 2)
+	replayed := writer.written[1]
+	assert.Equal(t, []byte("order-3"), replayed.Key)
+	assert.Equal(t, []kafka.Header{update, {Key: HeaderReplayedFrom, Value: []byte("orders-dlq/0/2")}}, replayed.Headers)
+}
+
+func TestReplayToRetryByOrderUID(t *testing.T) {
+	source := &MockDLQSource{letters: []DeadLetter{
+		testDeadLetter(0, "order-1", ErrorClassInternal),
+		testDeadLetter(1, "order-2", ErrorClassConflict),
+	}}
+	writer := &MockWriter{}
+	replayer := newTestReplayer(t, source, writer)
+
+	report, err := replayer.Replay(context.Background(), ReplayRequest{
+		Filter: DLQFilter{OrderUID: "order-2"},
+		Target: ReplayToRetry,
+	})
+	require.NoError(t, err)
+
+	require.Len(t, report.Replayed, 1)
+	require.Len(t, writer.written, 1)
+	assert.Equal(t, "orders-retry", writer.written[0].Topic)
+	assert.Equal(t, retryState{}, readRetryState(writer.written[0].Headers))
+}
+
+func TestReplayDryRunAndLimit(t *testing.T) {
+	source := &MockDLQSource{letters: []DeadLetter{
+		testDeadLetter(0, "order-1", ErrorClassInternal),
+		testDeadLetter(1, "order-2", ErrorClassInternal),
+		testDeadLetter(2, "order-3", ErrorClassInternal),
+	}}
+	writer := &MockWriter{}
+	replayer := newTestReplayer(t, source, writer)
+
+	report, err := replayer.Replay(context.Background(), ReplayRequest{Target: ReplayToSource, DryRun: true, Limit: 2})
+	require.NoError(t, err)
+
+	assert.True(t, report.DryRun)
+	assert.True(t, report.Truncated)
+	assert.Len(t, report.Replayed, 2)
+	assert.Empty(t, writer.written)
+}
+
+func TestReplayErrors(t *testing.T) {
+	writer := &MockWriter{err: errors.New("broker unavailable")}
+	replayer := newTestReplayer(t, &MockDLQSource{letters: []DeadLetter{testDeadLetter(0, "order-1", ErrorClassInternal)}}, writer)
+	ctx := context.Background()
+
+	_, err := replayer.Replay(ctx, ReplayRequest{Target: "elsewhere"})
+	assert.ErrorIs(t, err, ErrInvalidReplay)
+
+	_, err = replayer.Replay(ctx, ReplayRequest{Target: ReplayToSource, Limit: MaxReplayLimit + 1})
+	assert.ErrorIs(t, err, ErrInvalidReplay)
+
+	_, err = replayer.Replay(ctx, ReplayRequest{Target: ReplayToSource})
+	assert.ErrorIs(t, err, writer.err)
+}
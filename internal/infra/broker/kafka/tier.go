@@ -0,0 +1,44 @@
+package kafka
+
+import (
+	"order-service/internal/config"
+	"sync/atomic"
+
+	kafka "github.com/segmentio/kafka-go"
+)
+
+// retryTier reads one retry topic with its own consumer group.
+type retryTier struct {
+	config.RetryTier
+	reader *kafka.Reader
+	queue  *delayQueue
+	gate   *offsetGate
+
+	succeeded    atomic.Uint64
+	escalated    atomic.Uint64
+	deadLettered atomic.Uint64
+}
+
+// RetryTierStats counts the messages a retry tier processed. Escalated
+// messages failed again and moved to the next tier.
+type RetryTierStats struct {
+	Topic        string  `json:"topic"`
+	GroupID      string  `json:"group_id"`
+	DelaySeconds float64 `json:"delay_seconds"`
+	Held         int     `json:"held"`
+	Succeeded    uint64  `json:"succeeded"`
+	Escalated    uint64  `json:"escalated"`
+	DeadLettered uint64  `json:"dead_lettered"`
+}
+
+func (t *retryTier) stats() RetryTierStats {
+	return RetryTierStats{
+		Topic:        t.Topic,
+		GroupID:      t.GroupID,
+		DelaySeconds: t.Delay.Seconds(),
+		Held:         t.queue.size(),
+		Succeeded:    t.succeeded.Load(),
+		Escalated:    t.escalated.Load(),
+		DeadLettered: t.deadLettered.Load(),
+	}
+}
@@ -0,0 +1,307 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"order-service/internal/config"
+	"order-service/internal/infra/broker/handler"
+	"sync"
+	"time"
+)
+
+type Handler interface {
+	ProcessOrderMessage(ctx context.Context, msg []byte) (handler.Result, error)
+	ProcessUpdateMessage(ctx context.Context, msg []byte) (handler.Result, error)
+}
+
+// RetryHandler schedules the retries of failed messages, like the one of the
+// Kafka consumer.
+type RetryHandler interface {
+	Tiers() []config.RetryTier
+	NextAttempt(attempt int, now time.Time) (tier int, notBefore time.Time, ok bool)
+}
+
+type MessageType string
+
+const (
+	MessageTypeOrder  MessageType = "order"
+	MessageTypeUpdate MessageType = "update"
+)
+
+type Message struct {
+	Type  MessageType
+	Key   []byte
+	Value []byte
+	// Attempt is the number of the retry the message is delivered for, 0 on
+	// the first delivery.
+	Attempt      int
+	FirstFailure time.Time
+	NotBefore    time.Time
+}
+
+type DeadLetter struct {
+	Message
+	// Reason is handler.DLQ when the handler rejected the message and
+	// handler.Retry when it ran out of retries.
+	Reason handler.Result
+	Err    error
+	// Attempts is how many times the message was processed, the first
+	// delivery included.
+	Attempts       int
+	DeadLetteredAt time.Time
+}
+
+var ErrUnknownTier = errors.New("unknown retry tier")
+
+// Consumer is an in-process broker.Consumer for local runs and tests. It
+// keeps the order, update, retry and DLQ queues in memory and routes the
+// handler results like the Kafka consumer: failed messages are retried
+// through the retry tiers and go to the DLQ once their retries are used up.
+// Nothing survives a restart.
+type Consumer struct {
+	handler      Handler
+	retryHandler RetryHandler
+	logger       *slog.Logger
+	orders       *queue
+	updates      *queue
+	retries      []*queue
+	now          func() time.Time
+
+	mu          sync.Mutex
+	pending     int
+	idle        chan struct{}
+	deadLetters []DeadLetter
+
+	held      sync.WaitGroup
+	ready     chan struct{}
+	readyOnce sync.Once
+}
+
+func NewConsumer(handler Handler, retryHandler RetryHandler, logger *slog.Logger) *Consumer {
+	c := &Consumer{
+		handler:      handler,
+		retryHandler: retryHandler,
+		logger:       logger,
+		orders:       newQueue(),
+		updates:      newQueue(),
+		now:          time.Now,
+		idle:         make(chan struct{}),
+		ready:        make(chan struct{}),
+	}
+	for range retryHandler.Tiers() {
+		c.retries = append(c.retries, newQueue())
+	}
+	return c
+}
+
+func (c *Consumer) Init() error {
+	c.readyOnce.Do(func() {
+		close(c.ready)
+		c.logger.Info("memory broker initialized", "retry_tiers", len(c.retries))
+	})
+	return nil
+}
+
+func (c *Consumer) Ready() <-chan struct{} {
+	return c.ready
+}
+
+// PublishOrder queues an order creation message. It may be called before
+// the broker runs.
+func (c *Consumer) PublishOrder(key, value []byte) {
+	c.publish(c.orders, Message{Type: MessageTypeOrder, Key: key, Value: value})
+}
+
+// PublishUpdate queues an order update message.
+func (c *Consumer) PublishUpdate(key, value []byte) {
+	c.publish(c.updates, Message{Type: MessageTypeUpdate, Key: key, Value: value})
+}
+
+// EnqueueOrder publishes an order message, like PublishOrder. It lets the
+// HTTP API queue orders in memory mode.
+func (c *Consumer) EnqueueOrder(ctx context.Context, key, value []byte) error {
+	c.PublishOrder(key, value)
+	return nil
+}
+
+// DeadLetters returns the messages sent to the DLQ so far, in order.
+func (c *Consumer) DeadLetters() []DeadLetter {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	dls := make([]DeadLetter, len(c.deadLetters))
+	copy(dls, c.deadLetters)
+	return dls
+}
+
+// Pending tells how many published messages are not processed to the end
+// yet, retries waiting for their due time included.
+func (c *Consumer) Pending() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.pending
+}
+
+// WaitIdle waits until every published message succeeded or went to the
+// DLQ, or until ctx is done.
+func (c *Consumer) WaitIdle(ctx context.Context) error {
+	for {
+		c.mu.Lock()
+		if c.pending == 0 {
+			c.mu.Unlock()
+			return nil
+		}
+		idle := c.idle
+		c.mu.Unlock()
+
+		select {
+		case <-idle:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (c *Consumer) ReadOrderMsg(ctx context.Context) error {
+	msg, err := c.orders.pop(ctx)
+	if err != nil {
+		return err
+	}
+	c.process(ctx, msg)
+	return nil
+}
+
+func (c *Consumer) ReadUpdateMsg(ctx context.Context) error {
+	msg, err := c.updates.pop(ctx)
+	if err != nil {
+		return err
+	}
+	c.process(ctx, msg)
+	return nil
+}
+
+func (c *Consumer) RetryTiers() int {
+	return len(c.retries)
+}
+
+// ReadRetryMsg takes the next message of a retry tier and processes it at
+// its due time. Messages that are not due do not block the ones behind them.
+// A message still held when ctx is done goes back to its queue.
+func (c *Consumer) ReadRetryMsg(ctx context.Context, tier int) error {
+	if tier < 0 || tier >= len(c.retries) {
+		return ErrUnknownTier
+	}
+	q := c.retries[tier]
+
+	msg, err := q.pop(ctx)
+	if err != nil {
+		return err
+	}
+
+	c.held.Add(1)
+	go func() {
+		defer c.held.Done()
+
+		if wait := msg.NotBefore.Sub(c.now()); wait > 0 {
+			timer := time.NewTimer(wait)
+			defer timer.Stop()
+
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				q.push(msg)
+				return
+			}
+		}
+		c.process(ctx, msg)
+	}()
+
+	return nil
+}
+
+// ShutDown waits for the held retry messages. The context passed to
+// ReadRetryMsg has to be canceled first.
+func (c *Consumer) ShutDown() error {
+	c.held.Wait()
+	return nil
+}
+
+func (c *Consumer) publish(q *queue, msg Message) {
+	c.mu.Lock()
+	c.pending++
+	c.mu.Unlock()
+
+	q.push(msg)
+}
+
+// process handles msg and routes it by result. msg stops being pending
+// unless it is queued for a retry.
+func (c *Consumer) process(ctx context.Context, msg Message) {
+	defer c.done()
+
+	var (
+		res     handler.Result
+		procErr error
+	)
+	if msg.Type == MessageTypeUpdate {
+		res, procErr = c.handler.ProcessUpdateMessage(ctx, msg.Value)
+	} else {
+		res, procErr = c.handler.ProcessOrderMessage(ctx, msg.Value)
+	}
+
+	switch res {
+	case handler.Success:
+		c.logger.Info("message processed", "key", string(msg.Key), "attempt", msg.Attempt)
+	case handler.Retry:
+		c.scheduleRetry(msg, procErr)
+	case handler.DLQ:
+		c.deadLetter(msg, handler.DLQ, procErr)
+	}
+}
+
+func (c *Consumer) scheduleRetry(msg Message, procErr error) {
+	now := c.now()
+	if msg.FirstFailure.IsZero() {
+		msg.FirstFailure = now
+	}
+
+	tier, notBefore, ok := c.retryHandler.NextAttempt(msg.Attempt+1, now)
+	if !ok || tier < 0 || tier >= len(c.retries) {
+		c.deadLetter(msg, handler.Retry, procErr)
+		c.logger.Warn("retries exhausted, written to dlq", "key", string(msg.Key), "attempts", msg.Attempt)
+
+		return
+	}
+
+	msg.Attempt++
+	msg.NotBefore = notBefore
+	c.publish(c.retries[tier], msg)
+
+	c.logger.Debug("written to retry", "key", string(msg.Key), "tier", tier,
+		"attempt", msg.Attempt, "not_before", notBefore)
+}
+
+func (c *Consumer) deadLetter(msg Message, reason handler.Result, procErr error) {
+	c.mu.Lock()
+	c.deadLetters = append(c.deadLetters, DeadLetter{
+		Message:        msg,
+		Reason:         reason,
+		Err:            procErr,
+		Attempts:       msg.Attempt + 1,
+		DeadLetteredAt: c.now(),
+	})
+	c.mu.Unlock()
+
+	c.logger.Debug("written to dlq", "key", string(msg.Key), "reason", reason, "attempts", msg.Attempt+1)
+}
+
+func (c *Consumer) done() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.pending--
+	if c.pending == 0 {
+		close(c.idle)
+		c.idle = make(chan struct{})
+	}
+}
@@ -0,0 +1,135 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"order-service/internal/config"
+	"order-service/internal/domain"
+	"order-service/internal/infra/broker"
+	"order-service/internal/infra/broker/handler"
+	"order-service/internal/infra/broker/retry"
+	"order-service/internal/lib/logger"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type MockUseCase struct {
+	mu       sync.Mutex
+	failures map[string]int
+	errs     map[string]error
+	created  []string
+	updated  []string
+}
+
+func (m *MockUseCase) result(uid string) error {
+	if err := m.errs[uid]; err != nil {
+		return err
+	}
+	if m.failures[uid] > 0 {
+		m.failures[uid]--
+		return errors.New("database unavailable")
+	}
+	return nil
+}
+
+func (m *MockUseCase) CreateOrder(ctx context.Context, params domain.OrderParams) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.result(params.OrderUID); err != nil {
+		return err
+	}
+	m.created = append(m.created, params.OrderUID)
+	return nil
+}
+
+func (m *MockUseCase) CreateOrders(ctx context.Context, params []domain.OrderParams) []error {
+	return make([]error, len(params))
+}
+
+func (m *MockUseCase) UpdateOrder(ctx context.Context, params domain.OrderParams) (*domain.Order, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.result(params.OrderUID); err != nil {
+		return nil, err
+	}
+	m.updated = append(m.updated, params.OrderUID)
+	return &domain.Order{OrderUID: params.OrderUID}, nil
+}
+
+func TestBrokerRoutesResults(t *testing.T) {
+	l, err := logger.InitLogger("test")
+	require.NoError(t, err)
+
+	uc := &MockUseCase{
+		failures: map[string]int{"flaky": 1, "down": 10},
+		errs:     map[string]error{"invalid": domain.ErrInvalidState},
+	}
+	retries := retry.NewRetry(config.KafkaConfig{RetryTiers: config.RetryTiers{
+		{Topic: "retry-1", Delay: 10 * time.Millisecond},
+		{Topic: "retry-2", Delay: 20 * time.Millisecond},
+	}})
+	consumer := NewConsumer(handler.NewMessageProcessor(uc, l), retries, l)
+
+	consumer.PublishOrder([]byte("ok"), []byte(`{"order_uid":"ok"}`))
+	consumer.PublishOrder([]byte("flaky"), []byte(`{"order_uid":"flaky"}`))
+	consumer.PublishOrder([]byte("down"), []byte(`{"order_uid":"down"}`))
+	consumer.PublishOrder([]byte("invalid"), []byte(`{"order_uid":"invalid"}`))
+	consumer.PublishOrder(nil, []byte(`{"order_uid":`))
+	consumer.PublishUpdate([]byte("ok"), []byte(`{"order_uid":"ok"}`))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	b := broker.NewBroker(consumer, l)
+	b.Run(ctx)
+
+	waitCtx, waitCancel := context.WithTimeout(ctx, 5*time.Second)
+	defer waitCancel()
+	require.NoError(t, consumer.WaitIdle(waitCtx))
+	cancel()
+	require.NoError(t, b.Shutdown())
+
+	assert.ElementsMatch(t, []string{"ok", "flaky"}, uc.created)
+	assert.Equal(t, []string{"ok"}, uc.updated)
+
+	dls := consumer.DeadLetters()
+	require.Len(t, dls, 3)
+	byKey := make(map[string]DeadLetter)
+	for _, dl := range dls {
+		byKey[string(dl.Key)] = dl
+	}
+
+	down := byKey["down"]
+	assert.Equal(t, handler.Retry, down.Reason)
+	assert.Equal(t, 3, down.Attempts)
+	assert.False(t, down.FirstFailure.IsZero())
+
+	invalid := byKey["invalid"]
+	assert.Equal(t, handler.DLQ, invalid.Reason)
+	assert.Equal(t, 1, invalid.Attempts)
+	assert.ErrorIs(t, invalid.Err, domain.ErrInvalidState)
+
+	assert.ErrorIs(t, byKey[""].Err, handler.ErrMalformedMessage)
+	assert.Equal(t, 0, consumer.Pending())
+}
+
+func TestReadRetryMsgRequeuesOnCancel(t *testing.T) {
+	l, err := logger.InitLogger("test")
+	require.NoError(t, err)
+
+	retries := retry.NewRetry(config.KafkaConfig{RetryTiers: config.RetryTiers{{Topic: "retry-1", Delay: time.Hour}}})
+	consumer := NewConsumer(handler.NewMessageProcessor(&MockUseCase{}, l), retries, l)
+	consumer.publish(consumer.retries[0], Message{Type: MessageTypeOrder, Value: []byte(`{"order_uid":"later"}`),
+		Attempt: 1, NotBefore: time.Now().Add(time.Hour)})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	require.NoError(t, consumer.ReadRetryMsg(ctx, 0))
+	cancel()
+	require.NoError(t, consumer.ShutDown())
+
+	assert.Equal(t, 1, consumer.retries[0].len())
+	assert.Equal(t, 1, consumer.Pending())
+	assert.ErrorIs(t, consumer.ReadRetryMsg(context.Background(), 1), ErrUnknownTier)
+}
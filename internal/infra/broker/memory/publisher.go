@@ -0,0 +1,28 @@
+package memory
+
+import (
+	"context"
+	"log/slog"
+	"order-service/internal/infra/repo"
+)
+
+// EventPublisher stands in for the order events topic. Nothing reads the
+// events in memory mode, so it logs and drops them.
+type EventPublisher struct {
+	logger *slog.Logger
+}
+
+func NewEventPublisher(logger *slog.Logger) *EventPublisher {
+	return &EventPublisher{logger: logger}
+}
+
+func (p *EventPublisher) Publish(ctx context.Context, msgs []repo.OutboxMessage) []error {
+	for _, msg := range msgs {
+		p.logger.Debug("order event published", "id", msg.Id, "order_uid", msg.OrderUID, "type", msg.Type)
+	}
+	return make([]error, len(msgs))
+}
+
+func (p *EventPublisher) Close() error {
+	return nil
+}
@@ -0,0 +1,65 @@
+package memory
+
+import (
+	"context"
+	"sync"
+)
+
+// queue is an unbounded FIFO of messages. Pushing never blocks, so a reader
+// can requeue into its own queue.
+type queue struct {
+	mu   sync.Mutex
+	msgs []Message
+	// ready is signalled when msgs may have become non-empty.
+	ready chan struct{}
+}
+
+func newQueue() *queue {
+	return &queue{ready: make(chan struct{}, 1)}
+}
+
+func (q *queue) push(msg Message) {
+	q.mu.Lock()
+	q.msgs = append(q.msgs, msg)
+	q.mu.Unlock()
+
+	q.signal()
+}
+
+// pop removes the first message, waiting for one until ctx is done.
+func (q *queue) pop(ctx context.Context) (Message, error) {
+	for {
+		q.mu.Lock()
+		if len(q.msgs) > 0 {
+			msg := q.msgs[0]
+			q.msgs = q.msgs[1:]
+			left := len(q.msgs)
+			q.mu.Unlock()
+
+			if left > 0 {
+				q.signal()
+			}
+			return msg, nil
+		}
+		q.mu.Unlock()
+
+		select {
+		case <-q.ready:
+		case <-ctx.Done():
+			return Message{}, ctx.Err()
+		}
+	}
+}
+
+func (q *queue) len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.msgs)
+}
+
+func (q *queue) signal() {
+	select {
+	case q.ready <- struct{}{}:
+	default:
+	}
+}
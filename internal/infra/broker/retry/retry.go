@@ -1,25 +1,44 @@
 package retry
 
 import (
-	"context"
 	"math/rand"
 	"order-service/internal/config"
-	"order-service/internal/infra/broker/handler"
 	"time"
 )
 
+// Retry schedules the retries of failed messages. Without configured tiers
+// every retry goes to the retry topic with an exponential backoff, up to
+// maxAttempts times. With tiers, retry number n goes to tier n after the
+// tier delay and there are as many retries as tiers.
 type Retry struct {
+	tiers       []config.RetryTier
+	tiered      bool
 	maxAttempts int
 	backoffMin  time.Duration
 	backoffMax  time.Duration
 }
 
 func NewRetry(cfg config.KafkaConfig) *Retry {
-	return &Retry{
+	r := &Retry{
+		tiers:       cfg.RetryTiers,
+		tiered:      len(cfg.RetryTiers) > 0,
 		maxAttempts: cfg.RetryMaxAttempts,
 		backoffMin:  time.Duration(cfg.BackoffDurationMin) * time.Second,
 		backoffMax:  time.Duration(cfg.BackoffDurationMax) * time.Second,
 	}
+	if r.tiered {
+		r.maxAttempts = len(r.tiers)
+	} else {
+		r.tiers = []config.RetryTier{{Topic: cfg.RetryTopicCfg.KafkaTopic, GroupID: cfg.RetryTopicCfg.GroupID}}
+	}
+
+	return r
+}
+
+// Tiers lists the retry topics. Without configured tiers it is the single
+// retry topic.
+func (r *Retry) Tiers() []config.RetryTier {
+	return r.tiers
 }
 
 func (r *Retry) BackoffDuration(attempt int) time.Duration {
@@ -27,40 +46,31 @@ func (r *Retry) BackoffDuration(attempt int) time.Duration {
 		return 0
 	}
 
-	backoff := r.backoffMin * (1 << (attempt - 1))
-	if backoff > r.backoffMax {
+	// double up to the max one step at a time, so that a large attempt
+	// cannot overflow the shift
+	backoff := r.backoffMin
+	for i := 1; i < attempt && backoff > 0 && backoff < r.backoffMax; i++ {
+		backoff *= 2
+	}
+	if backoff > r.backoffMax || backoff < 0 {
 		backoff = r.backoffMax
 	}
+	if backoff < 4 {
+		return backoff
+	}
 
 	jitter := time.Duration(rand.Int63n(int64(backoff)/2) - int64(backoff)/4)
 	return backoff + jitter
 }
 
-// decorator for retry logic.
-
-func (r *Retry) RetryWrapper(ctx context.Context, fn func() handler.Result) handler.Result {
-	var res handler.Result
-	for attempt := 1; attempt <= r.maxAttempts; attempt++ {
-		select {
-		case <-ctx.Done():
-			return res
-		default:
-
-			res = fn()
-
-			if res == handler.Success || res == handler.DLQ {
-				return res
-			}
-
-			if attempt < r.maxAttempts {
-				select {
-				case <-time.After(r.BackoffDuration(attempt)):
-				case <-ctx.Done():
-					return res
-				}
-			}
-		}
+// NextAttempt tells the tier, by index, and the time retry number attempt is
+// due. It reports false once the message has used up its retries.
+func (r *Retry) NextAttempt(attempt int, now time.Time) (int, time.Time, bool) {
+	if attempt < 1 || attempt > r.maxAttempts {
+		return 0, time.Time{}, false
 	}
-
-	return res
+	if !r.tiered {
+		return 0, now.Add(r.BackoffDuration(attempt)), true
+	}
+	return attempt - 1, now.Add(r.tiers[attempt-1].Delay), true
 }
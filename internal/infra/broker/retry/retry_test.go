@@ -1,62 +1,78 @@
 package retry
 
 import (
-	"context"
+	"math"
 	"order-service/internal/config"
-	"order-service/internal/infra/broker/handler"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
 
-func TestRetryWrapperResults(t *testing.T) {
+func TestNextAttempt(t *testing.T) {
 	retry := NewRetry(config.KafkaConfig{
-		RetryMaxAttempts:   5,
+		RetryMaxAttempts:   3,
 		BackoffDurationMin: 1,
 		BackoffDurationMax: 4,
 	})
-	ctx := context.Background()
-
-	t.Run("always retryable", func(t *testing.T) {
-		attempts := 0
-		res := retry.RetryWrapper(ctx, func() handler.Result {
-			attempts++
-			return handler.Retry
-		})
-		assert.Equal(t, handler.Retry, res)
-		assert.Equal(t, retry.maxAttempts, attempts)
-	})
+	now := time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC)
 
-	t.Run("success first try", func(t *testing.T) {
-		attempts := 0
-		res := retry.RetryWrapper(ctx, func() handler.Result {
-			attempts++
-			return handler.Success
-		})
-		assert.Equal(t, handler.Success, res)
-		assert.Equal(t, 1, attempts)
-	})
+	for attempt := 1; attempt <= 3; attempt++ {
+		tier, due, ok := retry.NextAttempt(attempt, now)
+		assert.True(t, ok, "attempt %d", attempt)
+		assert.Equal(t, 0, tier)
 
-	t.Run("success after retries", func(t *testing.T) {
-		attempts := 0
-		res := retry.RetryWrapper(ctx, func() handler.Result {
-			attempts++
-			if attempts < retry.maxAttempts {
-				return handler.Retry
-			}
-			return handler.Success
-		})
-		assert.Equal(t, handler.Success, res)
-		assert.Equal(t, retry.maxAttempts, attempts)
-	})
+		// backoff doubles from 1s up to 4s with a jitter of +-25%
+		backoff := min(time.Second<<(attempt-1), 4*time.Second)
+		assert.GreaterOrEqual(t, due.Sub(now), backoff*3/4, "attempt %d", attempt)
+		assert.LessOrEqual(t, due.Sub(now), backoff*5/4, "attempt %d", attempt)
+	}
+
+	_, _, ok := retry.NextAttempt(4, now)
+	assert.False(t, ok)
+}
+
+func TestNextAttemptEscalatesTiers(t *testing.T) {
+	tiers := config.RetryTiers{
+		{Topic: "orders-retry-10s", GroupID: "retry-10s-group", Delay: 10 * time.Second},
+		{Topic: "orders-retry-1m", GroupID: "retry-1m-group", Delay: time.Minute},
+	}
+	retry := NewRetry(config.KafkaConfig{RetryTiers: tiers, RetryMaxAttempts: 5})
+	now := time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, []config.RetryTier(tiers), retry.Tiers())
+
+	tier, due, ok := retry.NextAttempt(1, now)
+	assert.True(t, ok)
+	assert.Equal(t, 0, tier)
+	assert.Equal(t, now.Add(10*time.Second), due)
 
-	t.Run("DLQ returned immediately", func(t *testing.T) {
-		attempts := 0
-		res := retry.RetryWrapper(ctx, func() handler.Result {
-			attempts++
-			return handler.DLQ
-		})
-		assert.Equal(t, handler.DLQ, res)
-		assert.Equal(t, 1, attempts)
+	tier, due, ok = retry.NextAttempt(2, now)
+	assert.True(t, ok)
+	assert.Equal(t, 1, tier)
+	assert.Equal(t, now.Add(time.Minute), due)
+
+	// the last tier sends failures to the DLQ regardless of RetryMaxAttempts
+	_, _, ok = retry.NextAttempt(3, now)
+	assert.False(t, ok)
+}
+
+func TestNextAttemptWithoutRetries(t *testing.T) {
+	retry := NewRetry(config.KafkaConfig{
+		RetryTopicCfg: config.RetryTopicConfig{KafkaTopic: "orders-retry", GroupID: "retry-group"},
 	})
+
+	assert.Equal(t, []config.RetryTier{{Topic: "orders-retry", GroupID: "retry-group"}}, retry.Tiers())
+	_, _, ok := retry.NextAttempt(1, time.Now())
+	assert.False(t, ok)
+}
+
+func TestBackoffDurationLargeAttempt(t *testing.T) {
+	retry := NewRetry(config.KafkaConfig{BackoffDurationMin: 1, BackoffDurationMax: 4})
+
+	for _, attempt := range []int{64, 1000, math.MaxInt} {
+		backoff := retry.BackoffDuration(attempt)
+		assert.GreaterOrEqual(t, backoff, 3*time.Second, "attempt %d", attempt)
+		assert.LessOrEqual(t, backoff, 5*time.Second, "attempt %d", attempt)
+	}
 }
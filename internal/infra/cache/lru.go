@@ -1,34 +1,277 @@
 package cache
 
 import (
+	"cmp"
+	"context"
+	"math/rand/v2"
 	"order-service/internal/domain"
+	"slices"
+	"sync"
+	"time"
 
-	lru "github.com/hashicorp/golang-lru"
+	"github.com/hashicorp/golang-lru/simplelru"
 )
 
+// Stats is exported through expvar.
+type Stats struct {
+	Entries     int    `json:"entries"`
+	Capacity    int    `json:"capacity"`
+	Bytes       int64  `json:"bytes"`
+	MaxBytes    int64  `json:"max_bytes"` // 0 when the size is not bounded
+	Hits        uint64 `json:"hits"`
+	Misses      uint64 `json:"misses"`
+	Evictions   uint64 `json:"evictions"`
+	Expirations uint64 `json:"expirations"`
+}
+
+// EntryInfo describes a cached order without copying it.
+type EntryInfo struct {
+	OrderUID  string
+	Hits      uint64
+	Size      int64
+	ExpiresAt time.Time // zero when the entry does not expire
+}
+
+type Option func(*LRUCache)
+
+// WithMaxBytes bounds the estimated size of the cached orders. The least
+// recently used orders are evicted to stay within it, an order larger than
+// the budget is not cached.
+func WithMaxBytes(maxBytes int64) Option {
+	return func(l *LRUCache) {
+		l.maxBytes = maxBytes
+	}
+}
+
+// WithTTL expires orders ttl after they were set, plus a random delay of up
+// to jitter so orders loaded together do not expire together.
+func WithTTL(ttl, jitter time.Duration) Option {
+	return func(l *LRUCache) {
+		l.ttl = ttl
+		l.jitter = jitter
+	}
+}
+
+// WithExpiryInterval sets how often Run removes the expired orders.
+func WithExpiryInterval(interval time.Duration) Option {
+	return func(l *LRUCache) {
+		l.expiryInterval = interval
+	}
+}
+
+type entry struct {
+	order     *domain.Order
+	size      int64
+	expiresAt time.Time // zero when the entry does not expire
+	hits      uint64
+}
+
+// LRUCache keeps the most recently used orders, bounded by their count and
+// optionally by their estimated size in bytes. It keeps its own copies of the
+// orders and hands out copies, so callers may change the orders they set or
+// get without affecting other readers.
 type LRUCache struct {
-	cache *lru.Cache
+	capacity       int
+	maxBytes       int64
+	ttl            time.Duration
+	jitter         time.Duration
+	expiryInterval time.Duration
+	now            func() time.Time
+
+	mu          sync.Mutex
+	cache       *simplelru.LRU
+	bytes       int64
+	hits        uint64
+	misses      uint64
+	evictions   uint64
+	expirations uint64
 }
 
-func NewLRUCache(size int) (*LRUCache, error) {
-	cache, err := lru.New(size)
+func NewLRUCache(size int, opts ...Option) (*LRUCache, error) {
+	l := &LRUCache{capacity: size, expiryInterval: time.Minute, now: time.Now}
+	cache, err := simplelru.NewLRU(size, l.onRemove)
 	if err != nil {
 		return nil, err
 	}
-	return &LRUCache{
-		cache: cache,
-	}, nil
+	l.cache = cache
+
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l, nil
 }
 
 func (l *LRUCache) Get(key string) (*domain.Order, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
 	v, ok := l.cache.Get(key)
 	if !ok {
+		l.misses++
+		return nil, false
+	}
+	e := v.(*entry)
+	if l.expired(e, l.now()) {
+		l.cache.Remove(key)
+		l.expirations++
+		l.misses++
 		return nil, false
 	}
-	order, ok := v.(*domain.Order)
-	return order, ok
+	e.hits++
+	l.hits++
+	return e.order.Clone(), true
 }
 
 func (l *LRUCache) Set(order *domain.Order) {
-	l.cache.Add(order.OrderUID, order)
+	order = order.Clone()
+	e := &entry{order: order, size: EstimateSize(order)}
+	if l.ttl > 0 {
+		e.expiresAt = l.now().Add(l.ttl)
+		if l.jitter > 0 {
+			e.expiresAt = e.expiresAt.Add(rand.N(l.jitter))
+		}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.maxBytes > 0 && e.size > l.maxBytes {
+		// keep a stale version from being served
+		l.cache.Remove(order.OrderUID)
+		return
+	}
+
+	if v, ok := l.cache.Peek(order.OrderUID); ok {
+		// replacing does not call onRemove
+		l.bytes -= v.(*entry).size
+		e.hits = v.(*entry).hits
+	}
+	if l.cache.Add(order.OrderUID, e) {
+		l.evictions++
+	}
+	l.bytes += e.size
+
+	for l.maxBytes > 0 && l.bytes > l.maxBytes {
+		l.cache.RemoveOldest()
+		l.evictions++
+	}
+}
+
+// Run removes the expired orders periodically until ctx is done. Without it
+// expired orders are only removed when they are read or evicted.
+func (l *LRUCache) Run(ctx context.Context) {
+	if l.ttl <= 0 || l.expiryInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(l.expiryInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			l.removeExpired()
+		}
+	}
+}
+
+func (l *LRUCache) Stats() Stats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return Stats{
+		Entries:     l.cache.Len(),
+		Capacity:    l.capacity,
+		Bytes:       l.bytes,
+		MaxBytes:    l.maxBytes,
+		Hits:        l.hits,
+		Misses:      l.misses,
+		Evictions:   l.evictions,
+		Expirations: l.expirations,
+	}
+}
+
+// Info describes the cached order with the uid, if it has not expired. It
+// does not count as a use of the order.
+func (l *LRUCache) Info(uid string) (EntryInfo, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	v, ok := l.cache.Peek(uid)
+	if !ok || l.expired(v.(*entry), l.now()) {
+		return EntryInfo{}, false
+	}
+	return entryInfo(uid, v.(*entry)), true
+}
+
+// Hottest describes up to n cached orders with the most hits, the most
+// recently used first among equals.
+func (l *LRUCache) Hottest(n int) []EntryInfo {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	keys := l.cache.Keys()
+	infos := make([]EntryInfo, 0, len(keys))
+	for i := len(keys) - 1; i >= 0; i-- {
+		v, ok := l.cache.Peek(keys[i])
+		if ok && !l.expired(v.(*entry), now) {
+			infos = append(infos, entryInfo(keys[i].(string), v.(*entry)))
+		}
+	}
+
+	slices.SortStableFunc(infos, func(a, b EntryInfo) int {
+		return cmp.Compare(b.Hits, a.Hits)
+	})
+	if len(infos) > n {
+		infos = infos[:n]
+	}
+	return infos
+}
+
+// Remove drops the order with the uid. It reports whether it was cached.
+func (l *LRUCache) Remove(uid string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.cache.Remove(uid)
+}
+
+// Purge drops every order and returns how many were cached.
+func (l *LRUCache) Purge() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	n := l.cache.Len()
+	l.cache.Purge()
+	return n
+}
+
+func entryInfo(uid string, e *entry) EntryInfo {
+	return EntryInfo{OrderUID: uid, Hits: e.hits, Size: e.size, ExpiresAt: e.expiresAt}
+}
+
+func (l *LRUCache) removeExpired() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	for _, key := range l.cache.Keys() {
+		v, ok := l.cache.Peek(key)
+		if ok && l.expired(v.(*entry), now) {
+			l.cache.Remove(key)
+			l.expirations++
+		}
+	}
+}
+
+func (l *LRUCache) expired(e *entry, now time.Time) bool {
+	return !e.expiresAt.IsZero() && !now.Before(e.expiresAt)
+}
+
+// onRemove is called by the LRU under l.mu for every entry that leaves it.
+func (l *LRUCache) onRemove(key, value any) {
+	l.bytes -= value.(*entry).size
 }
@@ -1,8 +1,12 @@
 package cache
 
 import (
+	"context"
+	"fmt"
 	"order-service/internal/domain"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -58,3 +62,239 @@ func TestLRUCache_Eviction(t *testing.T) {
 	_, ok = l.Get("3")
 	assert.True(t, ok)
 }
+
+func orderWithItems(uid string, n int) *domain.Order {
+	order := &domain.Order{OrderUID: uid}
+	for range n {
+		order.Items = append(order.Items, &domain.Item{Name: "Mascaras", Brand: "Vivienne Sabo", Rid: "ab4219087a764ae0btest"})
+	}
+	return order
+}
+
+func TestEstimateSize(t *testing.T) {
+	small := EstimateSize(orderWithItems("1", 1))
+	large := EstimateSize(orderWithItems("1", 100))
+
+	assert.Greater(t, small, int64(entryOverhead))
+	assert.Greater(t, large, 10*small)
+}
+
+func TestLRUCache_MaxBytes(t *testing.T) {
+	small := orderWithItems("small", 1)
+	budget := 3 * EstimateSize(small)
+	l, err := NewLRUCache(100, WithMaxBytes(budget))
+	require.NoError(t, err)
+
+	l.Set(orderWithItems("1", 1))
+	l.Set(orderWithItems("2", 1))
+	l.Set(orderWithItems("3", 1))
+	_, _ = l.Get("1")
+	assert.Equal(t, 3, l.Stats().Entries)
+
+	// takes the room of two small orders, the least recently used ones go
+	l.Set(orderWithItems("4", 2))
+	_, ok := l.Get("1")
+	assert.True(t, ok)
+	_, ok = l.Get("2")
+	assert.False(t, ok)
+	_, ok = l.Get("3")
+	assert.False(t, ok)
+	_, ok = l.Get("4")
+	assert.True(t, ok)
+
+	stats := l.Stats()
+	assert.LessOrEqual(t, stats.Bytes, budget)
+	assert.Equal(t, uint64(2), stats.Evictions)
+
+	// replacing an order accounts for the size of the new version only
+	l.Set(orderWithItems("4", 1))
+	assert.Equal(t, EstimateSize(orderWithItems("1", 1))+EstimateSize(orderWithItems("4", 1)), l.Stats().Bytes)
+
+	// too large to be cached at all, the stale version is dropped too
+	l.Set(orderWithItems("4", 10))
+	_, ok = l.Get("4")
+	assert.False(t, ok)
+	_, ok = l.Get("1")
+	assert.True(t, ok)
+	assert.Equal(t, EstimateSize(orderWithItems("1", 1)), l.Stats().Bytes)
+}
+
+func TestLRUCache_TTL(t *testing.T) {
+	now := time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC)
+	l, err := NewLRUCache(10, WithTTL(time.Minute, 10*time.Second))
+	require.NoError(t, err)
+	l.now = func() time.Time { return now }
+
+	l.Set(&domain.Order{OrderUID: "1"})
+	now = now.Add(30 * time.Second)
+	l.Set(&domain.Order{OrderUID: "2"})
+
+	now = now.Add(30 * time.Second)
+	_, ok := l.Get("1")
+	assert.True(t, ok, "the jitter only delays expiry")
+
+	now = now.Add(10 * time.Second)
+	_, ok = l.Get("1")
+	assert.False(t, ok)
+	_, ok = l.Get("2")
+	assert.True(t, ok)
+
+	now = now.Add(time.Minute)
+	l.removeExpired()
+	stats := l.Stats()
+	assert.Equal(t, 0, stats.Entries)
+	assert.Equal(t, int64(0), stats.Bytes)
+	assert.Equal(t, uint64(2), stats.Expirations)
+}
+
+func TestLRUCache_RunExpires(t *testing.T) {
+	l, err := NewLRUCache(10, WithTTL(time.Millisecond, 0), WithExpiryInterval(5*time.Millisecond))
+	require.NoError(t, err)
+	l.Set(&domain.Order{OrderUID: "1"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		l.Run(ctx)
+		close(done)
+	}()
+
+	assert.Eventually(t, func() bool { return l.Stats().Entries == 0 }, time.Second, 5*time.Millisecond)
+	cancel()
+	<-done
+}
+
+func TestLRUCache_Copies(t *testing.T) {
+	l, err := NewLRUCache(10)
+	require.NoError(t, err)
+
+	order := orderWithItems("1", 1)
+	l.Set(order)
+	order.Items[0].Name = "set and changed"
+
+	got, ok := l.Get("1")
+	require.True(t, ok)
+	assert.Equal(t, "Mascaras", got.Items[0].Name)
+	got.Items[0].Name = "got and changed"
+
+	got, ok = l.Get("1")
+	require.True(t, ok)
+	assert.Equal(t, "Mascaras", got.Items[0].Name)
+}
+
+// TestLRUCache_ConcurrentMutation fails under -race when readers share the
+// cached order.
+func TestLRUCache_ConcurrentMutation(t *testing.T) {
+	l, err := NewLRUCache(10)
+	require.NoError(t, err)
+	l.Set(orderWithItems("1", 3))
+
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 100 {
+				order, ok := l.Get("1")
+				if !assert.True(t, ok) {
+					return
+				}
+				order.Status = domain.StatusPaid
+				order.Items[0].Name = fmt.Sprintf("reader %d", i)
+				order.Items = append(order.Items, &domain.Item{})
+				l.Set(order)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func BenchmarkLRUCache_Get(b *testing.B) {
+	for _, items := range []int{1, 10, 100} {
+		b.Run(fmt.Sprintf("items=%d", items), func(b *testing.B) {
+			l, err := NewLRUCache(10)
+			require.NoError(b, err)
+			l.Set(orderWithItems("1", items))
+
+			b.ReportAllocs()
+			for b.Loop() {
+				_, _ = l.Get("1")
+			}
+		})
+	}
+}
+
+func BenchmarkLRUCache_Set(b *testing.B) {
+	for _, items := range []int{1, 10, 100} {
+		b.Run(fmt.Sprintf("items=%d", items), func(b *testing.B) {
+			l, err := NewLRUCache(10)
+			require.NoError(b, err)
+			order := orderWithItems("1", items)
+
+			b.ReportAllocs()
+			for b.Loop() {
+				l.Set(order)
+			}
+		})
+	}
+}
+
+func BenchmarkLRUCache_GetParallel(b *testing.B) {
+	l, err := NewLRUCache(10)
+	require.NoError(b, err)
+	l.Set(orderWithItems("1", 10))
+
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			_, _ = l.Get("1")
+		}
+	})
+}
+
+func TestLRUCache_Introspection(t *testing.T) {
+	l, err := NewLRUCache(10, WithMaxBytes(1<<20))
+	require.NoError(t, err)
+
+	for _, uid := range []string{"1", "2", "3"} {
+		l.Set(&domain.Order{OrderUID: uid})
+	}
+	for range 3 {
+		_, _ = l.Get("2")
+	}
+	_, _ = l.Get("1")
+	_, _ = l.Get("missing")
+	// replacing an order keeps its hits
+	l.Set(&domain.Order{OrderUID: "2", TrackNumber: "WBILMNEWTRACK"})
+
+	stats := l.Stats()
+	assert.Equal(t, 3, stats.Entries)
+	assert.Equal(t, 10, stats.Capacity)
+	assert.Equal(t, int64(1<<20), stats.MaxBytes)
+	assert.Equal(t, uint64(4), stats.Hits)
+	assert.Equal(t, uint64(1), stats.Misses)
+
+	hottest := l.Hottest(2)
+	require.Len(t, hottest, 2)
+	assert.Equal(t, "2", hottest[0].OrderUID)
+	assert.Equal(t, uint64(3), hottest[0].Hits)
+	assert.Equal(t, "1", hottest[1].OrderUID)
+
+	info, ok := l.Info("3")
+	assert.True(t, ok)
+	assert.Equal(t, EstimateSize(&domain.Order{OrderUID: "3"}), info.Size)
+	assert.Equal(t, uint64(4), l.Stats().Hits, "info does not count as a hit")
+	_, ok = l.Info("missing")
+	assert.False(t, ok)
+
+	assert.True(t, l.Remove("3"))
+	assert.False(t, l.Remove("3"))
+	_, ok = l.Info("3")
+	assert.False(t, ok)
+
+	assert.Equal(t, 2, l.Purge())
+	stats = l.Stats()
+	assert.Equal(t, 0, stats.Entries)
+	assert.Equal(t, int64(0), stats.Bytes)
+	assert.Empty(t, l.Hottest(10))
+}
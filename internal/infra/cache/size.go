@@ -0,0 +1,53 @@
+package cache
+
+import (
+	"order-service/internal/domain"
+	"unsafe"
+)
+
+// entryOverhead approximates the memory the LRU spends on an entry besides
+// the order: the list element, the map slot and the entry itself.
+const entryOverhead = 160
+
+// EstimateSize approximates the memory held by a cached order in bytes: the
+// structs and the string data they point to. Strings shared between orders
+// are counted for each of them, so the estimate errs on the large side.
+func EstimateSize(order *domain.Order) int64 {
+	if order == nil {
+		return entryOverhead
+	}
+
+	size := entryOverhead + int64(unsafe.Sizeof(*order)) + strLen(order.OrderUID, order.TrackNumber, order.Entry, order.Locale,
+		order.InternalSignature, order.CustomerID, order.DeliveryService, order.Shardkey, order.OofShard,
+		string(order.Status))
+
+	if d := order.Delivery; d != nil {
+		size += int64(unsafe.Sizeof(*d)) + strLen(d.Name, d.Phone, d.Zip, d.City, d.Address, d.Region, d.Country, d.Email)
+	}
+	if p := order.Payment; p != nil {
+		size += int64(unsafe.Sizeof(*p)) + strLen(p.Transaction, p.RequestID, string(p.Currency), p.Provider, p.Bank)
+	}
+
+	size += int64(cap(order.Items)) * int64(unsafe.Sizeof((*domain.Item)(nil)))
+	for _, item := range order.Items {
+		if item == nil {
+			continue
+		}
+		size += int64(unsafe.Sizeof(*item)) + strLen(item.TrackNumber, item.Rid, item.Name, item.Size, item.Brand)
+	}
+
+	size += int64(cap(order.StatusHistory)) * int64(unsafe.Sizeof(domain.StatusTransition{}))
+	for _, tr := range order.StatusHistory {
+		size += int64(len(tr.Status))
+	}
+
+	return size
+}
+
+func strLen(strs ...string) int64 {
+	var n int64
+	for _, s := range strs {
+		n += int64(len(s))
+	}
+	return n
+}
@@ -0,0 +1,259 @@
+package cache
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"order-service/internal/domain"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const snapshotVersion = 1
+
+var ErrSnapshotVersion = errors.New("unsupported cache snapshot version")
+
+// Snapshot lists the cached orders from the least to the most recently used.
+type Snapshot struct {
+	CreatedAt time.Time
+	Entries   []SnapshotEntry
+}
+
+// SnapshotEntry is a cached order. Order is nil when the snapshot was taken
+// without values.
+type SnapshotEntry struct {
+	OrderUID string
+	Order    *domain.Order
+}
+
+// Snapshot lists the cached orders that have not expired. With values it
+// keeps the orders too, otherwise only their uids.
+func (l *LRUCache) Snapshot(withValues bool) *Snapshot {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	snap := &Snapshot{CreatedAt: now, Entries: make([]SnapshotEntry, 0, l.cache.Len())}
+	for _, key := range l.cache.Keys() {
+		v, ok := l.cache.Peek(key)
+		if !ok || l.expired(v.(*entry), now) {
+			continue
+		}
+		e := SnapshotEntry{OrderUID: key.(string)}
+		if withValues {
+			// entries are never changed in place, sharing them is safe
+			e.Order = v.(*entry).order
+		}
+		snap.Entries = append(snap.Entries, e)
+	}
+	return snap
+}
+
+// SaveSnapshot writes the snapshot to path. The file is replaced at once, so
+// an interrupted save keeps the previous snapshot.
+func SaveSnapshot(path string, snap *Snapshot) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = os.Remove(tmp.Name())
+	}()
+
+	if err := WriteSnapshot(tmp, snap); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// LoadSnapshot reads the snapshot saved to path.
+func LoadSnapshot(path string) (*Snapshot, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	return ReadSnapshot(f)
+}
+
+// WriteSnapshot writes the snapshot as gzip-compressed JSON.
+func WriteSnapshot(w io.Writer, snap *Snapshot) error {
+	file := snapshotFile{Version: snapshotVersion, CreatedAt: snap.CreatedAt, Entries: make([]snapshotEntry, 0, len(snap.Entries))}
+	for _, e := range snap.Entries {
+		file.Entries = append(file.Entries, snapshotEntry{OrderUID: e.OrderUID, Order: toSnapshotOrder(e.Order)})
+	}
+
+	zw := gzip.NewWriter(w)
+	if err := json.NewEncoder(zw).Encode(file); err != nil {
+		_ = zw.Close()
+		return err
+	}
+	return zw.Close()
+}
+
+func ReadSnapshot(r io.Reader) (*Snapshot, error) {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = zr.Close()
+	}()
+
+	var file snapshotFile
+	if err := json.NewDecoder(zr).Decode(&file); err != nil {
+		return nil, err
+	}
+	if file.Version != snapshotVersion {
+		return nil, fmt.Errorf("version %d: %w", file.Version, ErrSnapshotVersion)
+	}
+
+	snap := &Snapshot{CreatedAt: file.CreatedAt, Entries: make([]SnapshotEntry, 0, len(file.Entries))}
+	for _, e := range file.Entries {
+		snap.Entries = append(snap.Entries, SnapshotEntry{OrderUID: e.OrderUID, Order: e.Order.toDomain(e.OrderUID)})
+	}
+	return snap, nil
+}
+
+type snapshotFile struct {
+	Version   int             `json:"version"`
+	CreatedAt time.Time       `json:"created_at"`
+	Entries   []snapshotEntry `json:"entries"`
+}
+
+type snapshotEntry struct {
+	OrderUID string         `json:"uid"`
+	Order    *snapshotOrder `json:"order,omitempty"`
+}
+
+// snapshotOrder is the stored form of domain.Order, whose money amounts
+// cannot be encoded directly.
+type snapshotOrder struct {
+	Id                int                       `json:"id"`
+	TrackNumber       string                    `json:"track_number"`
+	Entry             string                    `json:"entry"`
+	Delivery          *domain.DeliveryParams    `json:"delivery,omitempty"`
+	Payment           *domain.PaymentParams     `json:"payment,omitempty"`
+	Items             []domain.ItemParams       `json:"items"`
+	Locale            string                    `json:"locale"`
+	InternalSignature string                    `json:"internal_signature"`
+	CustomerID        string                    `json:"customer_id"`
+	DeliveryService   string                    `json:"delivery_service"`
+	Shardkey          string                    `json:"shardkey"`
+	SmID              int                       `json:"sm_id"`
+	DateCreated       time.Time                 `json:"date_created"`
+	DateUpdated       time.Time                 `json:"date_updated"`
+	OofShard          string                    `json:"oof_shard"`
+	Status            domain.Status             `json:"status"`
+	StatusHistory     []domain.StatusTransition `json:"status_history,omitempty"`
+}
+
+func toSnapshotOrder(o *domain.Order) *snapshotOrder {
+	if o == nil {
+		return nil
+	}
+
+	p := o.Params()
+	s := &snapshotOrder{
+		Id:                o.Id,
+		TrackNumber:       o.TrackNumber,
+		Entry:             o.Entry,
+		Items:             p.Items,
+		Locale:            o.Locale,
+		InternalSignature: o.InternalSignature,
+		CustomerID:        o.CustomerID,
+		DeliveryService:   o.DeliveryService,
+		Shardkey:          o.Shardkey,
+		SmID:              o.SmID,
+		DateCreated:       o.DateCreated,
+		DateUpdated:       o.DateUpdated,
+		OofShard:          o.OofShard,
+		Status:            o.Status,
+		StatusHistory:     o.StatusHistory,
+	}
+	if o.Delivery != nil {
+		s.Delivery = &p.Delivery
+	}
+	if o.Payment != nil {
+		s.Payment = &p.Payment
+	}
+	return s
+}
+
+func (s *snapshotOrder) toDomain(uid string) *domain.Order {
+	if s == nil {
+		return nil
+	}
+
+	o := &domain.Order{
+		Id:                s.Id,
+		OrderUID:          uid,
+		TrackNumber:       s.TrackNumber,
+		Entry:             s.Entry,
+		Locale:            s.Locale,
+		InternalSignature: s.InternalSignature,
+		CustomerID:        s.CustomerID,
+		DeliveryService:   s.DeliveryService,
+		Shardkey:          s.Shardkey,
+		SmID:              s.SmID,
+		DateCreated:       s.DateCreated,
+		DateUpdated:       s.DateUpdated,
+		OofShard:          s.OofShard,
+		Status:            s.Status,
+		StatusHistory:     s.StatusHistory,
+	}
+	if d := s.Delivery; d != nil {
+		o.Delivery = &domain.Delivery{
+			Name:    d.Name,
+			Phone:   d.Phone,
+			Zip:     d.Zip,
+			City:    d.City,
+			Address: d.Address,
+			Region:  d.Region,
+			Country: d.Country,
+			Email:   d.Email,
+		}
+	}
+	currency := domain.Currency("")
+	if p := s.Payment; p != nil {
+		currency = domain.Currency(p.Currency)
+		o.Payment = &domain.Payment{
+			Transaction:  p.Transaction,
+			RequestID:    p.RequestID,
+			Currency:     currency,
+			Provider:     p.Provider,
+			Amount:       domain.NewMoney(int64(p.Amount), currency),
+			PaymentDt:    p.PaymentDt,
+			Bank:         p.Bank,
+			DeliveryCost: domain.NewMoney(int64(p.DeliveryCost), currency),
+			GoodsTotal:   domain.NewMoney(int64(p.GoodsTotal), currency),
+			CustomFee:    domain.NewMoney(int64(p.CustomFee), currency),
+		}
+	}
+	for _, item := range s.Items {
+		o.Items = append(o.Items, &domain.Item{
+			ChrtID:      item.ChrtID,
+			TrackNumber: item.TrackNumber,
+			Price:       domain.NewMoney(int64(item.Price), currency),
+			Rid:         item.Rid,
+			Name:        item.Name,
+			Sale:        item.Sale,
+			Size:        item.Size,
+			TotalPrice:  domain.NewMoney(int64(item.TotalPrice), currency),
+			NmID:        item.NmID,
+			Brand:       item.Brand,
+			Status:      item.Status,
+		})
+	}
+	return o
+}
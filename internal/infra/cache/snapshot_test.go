@@ -0,0 +1,115 @@
+package cache
+
+import (
+	"bytes"
+	"compress/gzip"
+	"order-service/internal/domain"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func snapshotTestOrder(uid string) *domain.Order {
+	created := time.Date(2021, 11, 26, 6, 22, 0, 0, time.UTC)
+	return &domain.Order{
+		Id:          7,
+		OrderUID:    uid,
+		TrackNumber: "WBILMTESTTRACK",
+		Entry:       "WBIL",
+		Delivery:    &domain.Delivery{Name: "Test Testov", Phone: "+9720000000", City: "Kiryat Mozkin"},
+		Payment: &domain.Payment{
+			Transaction:  uid,
+			Currency:     "USD",
+			Provider:     "wbpay",
+			Amount:       domain.NewMoney(1817, "USD"),
+			PaymentDt:    1637907727,
+			DeliveryCost: domain.NewMoney(1500, "USD"),
+			GoodsTotal:   domain.NewMoney(317, "USD"),
+			CustomFee:    domain.NewMoney(0, "USD"),
+		},
+		Items: []*domain.Item{{
+			ChrtID:     9934930,
+			Price:      domain.NewMoney(453, "USD"),
+			Name:       "Mascaras",
+			Sale:       30,
+			TotalPrice: domain.NewMoney(317, "USD"),
+			Status:     202,
+		}},
+		Locale:        "en",
+		CustomerID:    "test",
+		SmID:          99,
+		DateCreated:   created,
+		DateUpdated:   created.Add(time.Hour),
+		Status:        domain.StatusPaid,
+		StatusHistory: []domain.StatusTransition{{Status: domain.StatusCreated, ChangedAt: created}, {Status: domain.StatusPaid, ChangedAt: created.Add(time.Hour)}},
+	}
+}
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	l, err := NewLRUCache(10)
+	require.NoError(t, err)
+	l.Set(snapshotTestOrder("1"))
+	l.Set(snapshotTestOrder("2"))
+	_, _ = l.Get("1")
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteSnapshot(&buf, l.Snapshot(true)))
+	snap, err := ReadSnapshot(&buf)
+	require.NoError(t, err)
+
+	require.Len(t, snap.Entries, 2)
+	assert.Equal(t, "2", snap.Entries[0].OrderUID, "least recently used first")
+	assert.Equal(t, "1", snap.Entries[1].OrderUID)
+	assert.Equal(t, snapshotTestOrder("1"), snap.Entries[1].Order)
+
+	buf.Reset()
+	require.NoError(t, WriteSnapshot(&buf, l.Snapshot(false)))
+	snap, err = ReadSnapshot(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, []SnapshotEntry{{OrderUID: "2"}, {OrderUID: "1"}}, snap.Entries)
+}
+
+func TestSnapshotSkipsExpired(t *testing.T) {
+	now := time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC)
+	l, err := NewLRUCache(10, WithTTL(time.Minute, 0))
+	require.NoError(t, err)
+	l.now = func() time.Time { return now }
+
+	l.Set(&domain.Order{OrderUID: "old"})
+	now = now.Add(time.Minute)
+	l.Set(&domain.Order{OrderUID: "new"})
+
+	assert.Equal(t, []SnapshotEntry{{OrderUID: "new"}}, l.Snapshot(false).Entries)
+}
+
+func TestSaveLoadSnapshot(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.snapshot")
+	snap := &Snapshot{CreatedAt: time.Now().UTC(), Entries: []SnapshotEntry{{OrderUID: "1"}}}
+
+	require.NoError(t, SaveSnapshot(path, snap))
+	snap.Entries = append(snap.Entries, SnapshotEntry{OrderUID: "2"})
+	require.NoError(t, SaveSnapshot(path, snap))
+
+	loaded, err := LoadSnapshot(path)
+	require.NoError(t, err)
+	assert.Equal(t, snap.Entries, loaded.Entries)
+	assert.True(t, snap.CreatedAt.Equal(loaded.CreatedAt))
+
+	matches, err := filepath.Glob(filepath.Join(filepath.Dir(path), "*.tmp"))
+	require.NoError(t, err)
+	assert.Empty(t, matches)
+}
+
+func TestReadSnapshotVersion(t *testing.T) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	_, err := zw.Write([]byte(`{"version":2,"entries":[]}`))
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+
+	_, err = ReadSnapshot(&buf)
+	assert.ErrorIs(t, err, ErrSnapshotVersion)
+}
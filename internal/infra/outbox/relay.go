@@ -0,0 +1,187 @@
+package outbox
+
+import (
+	"context"
+	"log/slog"
+	"order-service/internal/config"
+	"order-service/internal/infra/repo"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type Store interface {
+	ClaimOutbox(ctx context.Context, limit int, lease time.Duration) ([]repo.OutboxMessage, error)
+	MarkOutboxPublished(ctx context.Context, ids []int64) error
+	MarkOutboxFailed(ctx context.Context, id int64, retryAfter time.Duration, cause string) error
+	OutboxStats(ctx context.Context) (repo.OutboxStats, error)
+}
+
+// Publisher delivers a batch of events and returns one error per message,
+// nil for the ones that were delivered.
+type Publisher interface {
+	Publish(ctx context.Context, msgs []repo.OutboxMessage) []error
+	Close() error
+}
+
+// Stats is exported through expvar.
+type Stats struct {
+	Pending    int     `json:"pending"`
+	LagSeconds float64 `json:"lag_seconds"`
+	Published  uint64  `json:"published"`
+	Failed     uint64  `json:"failed"`
+}
+
+// Relay moves order events from the outbox table to Kafka. Delivery is
+// at least once: an event is marked published only after the broker acked it.
+type Relay struct {
+	store        Store
+	publisher    Publisher
+	logger       *slog.Logger
+	batchSize    int
+	pollInterval time.Duration
+	lease        time.Duration
+	backoffMin   time.Duration
+	backoffMax   time.Duration
+
+	// runMu orders Run against Shutdown: a Run that starts after Shutdown
+	// returns at once instead of publishing through a closed publisher.
+	runMu  sync.Mutex
+	closed bool
+	wg     sync.WaitGroup
+
+	published atomic.Uint64
+	failed    atomic.Uint64
+	mu        sync.Mutex
+	lastStats repo.OutboxStats
+}
+
+func NewRelay(cfg config.OutboxConfig, store Store, publisher Publisher, logger *slog.Logger) *Relay {
+	return &Relay{
+		store:        store,
+		publisher:    publisher,
+		logger:       logger,
+		batchSize:    cfg.BatchSize,
+		pollInterval: time.Duration(cfg.PollInterval) * time.Millisecond,
+		lease:        time.Duration(cfg.Lease) * time.Second,
+		backoffMin:   time.Duration(cfg.BackoffMin) * time.Second,
+		backoffMax:   time.Duration(cfg.BackoffMax) * time.Second,
+	}
+}
+
+func (r *Relay) Run(ctx context.Context) {
+	r.runMu.Lock()
+	if r.closed {
+		r.runMu.Unlock()
+		return
+	}
+	r.wg.Add(1)
+	r.runMu.Unlock()
+	defer r.wg.Done()
+
+	for {
+		n, err := r.relayBatch(ctx)
+		if err != nil {
+			r.logger.Error("outbox relay failed", "error", err)
+		}
+		r.refreshStats(ctx)
+
+		// a full batch means more events are likely waiting
+		if err == nil && n == r.batchSize {
+			if ctx.Err() != nil {
+				return
+			}
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(r.pollInterval):
+		}
+	}
+}
+
+// Shutdown waits for Run to return and closes the publisher. The context
+// passed to Run must be cancelled first.
+func (r *Relay) Shutdown() error {
+	r.runMu.Lock()
+	r.closed = true
+	r.runMu.Unlock()
+	r.wg.Wait()
+
+	return r.publisher.Close()
+}
+
+func (r *Relay) relayBatch(ctx context.Context) (int, error) {
+	msgs, err := r.store.ClaimOutbox(ctx, r.batchSize, r.lease)
+	if err != nil || len(msgs) == 0 {
+		return 0, err
+	}
+
+	errs := r.publisher.Publish(ctx, msgs)
+
+	var published []int64
+	for i, msg := range msgs {
+		var pubErr error
+		if i < len(errs) {
+			pubErr = errs[i]
+		}
+		if pubErr == nil {
+			published = append(published, msg.Id)
+			continue
+		}
+
+		r.failed.Add(1)
+		retryAfter := r.backoff(msg.Attempts + 1)
+		r.logger.Error("failed to publish order event", "error", pubErr, "order_uid", msg.OrderUID,
+			"event_type", msg.Type, "attempt", msg.Attempts+1, "retry_after", retryAfter)
+		if err := r.store.MarkOutboxFailed(ctx, msg.Id, retryAfter, pubErr.Error()); err != nil {
+			return len(msgs), err
+		}
+	}
+
+	if len(published) > 0 {
+		if err := r.store.MarkOutboxPublished(ctx, published); err != nil {
+			return len(msgs), err
+		}
+		r.published.Add(uint64(len(published)))
+	}
+
+	return len(msgs), nil
+}
+
+func (r *Relay) backoff(attempt int) time.Duration {
+	backoff := r.backoffMin << (attempt - 1)
+	if backoff > r.backoffMax || backoff <= 0 {
+		backoff = r.backoffMax
+	}
+	return backoff
+}
+
+func (r *Relay) refreshStats(ctx context.Context) {
+	stats, err := r.store.OutboxStats(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			r.logger.Error("failed to read outbox stats", "error", err)
+		}
+		return
+	}
+
+	r.mu.Lock()
+	r.lastStats = stats
+	r.mu.Unlock()
+}
+
+func (r *Relay) Stats() Stats {
+	r.mu.Lock()
+	last := r.lastStats
+	r.mu.Unlock()
+
+	return Stats{
+		Pending:    last.Pending,
+		LagSeconds: last.Lag.Seconds(),
+		Published:  r.published.Load(),
+		Failed:     r.failed.Load(),
+	}
+}
@@ -0,0 +1,137 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"order-service/internal/config"
+	"order-service/internal/infra/repo"
+	"order-service/internal/lib/logger"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type failedCall struct {
+	id         int64
+	retryAfter time.Duration
+}
+
+type MockStore struct {
+	pending   []repo.OutboxMessage
+	published []int64
+	failed    []failedCall
+	stats     repo.OutboxStats
+}
+
+func (m *MockStore) ClaimOutbox(ctx context.Context, limit int, lease time.Duration) ([]repo.OutboxMessage, error) {
+	if len(m.pending) < limit {
+		limit = len(m.pending)
+	}
+	claimed := m.pending[:limit]
+	m.pending = m.pending[limit:]
+	return claimed, nil
+}
+
+func (m *MockStore) MarkOutboxPublished(ctx context.Context, ids []int64) error {
+	m.published = append(m.published, ids...)
+	return nil
+}
+
+func (m *MockStore) MarkOutboxFailed(ctx context.Context, id int64, retryAfter time.Duration, cause string) error {
+	m.failed = append(m.failed, failedCall{id: id, retryAfter: retryAfter})
+	return nil
+}
+
+func (m *MockStore) OutboxStats(ctx context.Context) (repo.OutboxStats, error) {
+	return m.stats, nil
+}
+
+type MockPublisher struct {
+	failing map[string]error
+}
+
+func (m *MockPublisher) Publish(ctx context.Context, msgs []repo.OutboxMessage) []error {
+	errs := make([]error, len(msgs))
+	for i, msg := range msgs {
+		errs[i] = m.failing[msg.OrderUID]
+	}
+	return errs
+}
+
+func (m *MockPublisher) Close() error { return nil }
+
+func newTestRelay(t *testing.T, store Store, publisher Publisher) *Relay {
+	l, err := logger.InitLogger("test")
+	require.NoError(t, err)
+	return NewRelay(config.OutboxConfig{BatchSize: 10, PollInterval: 10, Lease: 30, BackoffMin: 1, BackoffMax: 4}, store, publisher, l)
+}
+
+func TestRelayBatch(t *testing.T) {
+	store := &MockStore{pending: []repo.OutboxMessage{
+		{Id: 1, OrderUID: "a"},
+		{Id: 2, OrderUID: "b", Attempts: 2},
+		{Id: 3, OrderUID: "c"},
+	}}
+	publisher := &MockPublisher{failing: map[string]error{"b": errors.New("broker down")}}
+	relay := newTestRelay(t, store, publisher)
+
+	n, err := relay.relayBatch(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+	assert.Equal(t, []int64{1, 3}, store.published)
+	assert.Equal(t, []failedCall{{id: 2, retryAfter: 4 * time.Second}}, store.failed)
+
+	stats := relay.Stats()
+	assert.Equal(t, uint64(2), stats.Published)
+	assert.Equal(t, uint64(1), stats.Failed)
+}
+
+func TestRelayBackoff(t *testing.T) {
+	relay := newTestRelay(t, &MockStore{}, &MockPublisher{})
+
+	assert.Equal(t, time.Second, relay.backoff(1))
+	assert.Equal(t, 2*time.Second, relay.backoff(2))
+	assert.Equal(t, 4*time.Second, relay.backoff(3))
+	assert.Equal(t, 4*time.Second, relay.backoff(10))
+	assert.Equal(t, 4*time.Second, relay.backoff(100))
+}
+
+func TestRelayRunDrainsOutbox(t *testing.T) {
+	store := &MockStore{
+		stats: repo.OutboxStats{Pending: 0, Lag: 0},
+	}
+	for i := 1; i <= 25; i++ {
+		store.pending = append(store.pending, repo.OutboxMessage{Id: int64(i), OrderUID: "uid"})
+	}
+	relay := newTestRelay(t, store, &MockPublisher{})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	relay.Run(ctx)
+
+	assert.Len(t, store.published, 25)
+	assert.NoError(t, relay.Shutdown())
+}
+
+func TestRelayRunAfterShutdown(t *testing.T) {
+	store := &MockStore{pending: []repo.OutboxMessage{{Id: 1, OrderUID: "uid"}}}
+	relay := newTestRelay(t, store, &MockPublisher{})
+
+	assert.NoError(t, relay.Shutdown())
+	relay.Run(context.Background())
+
+	assert.Empty(t, store.published)
+}
+
+func TestRelayStatsLag(t *testing.T) {
+	store := &MockStore{stats: repo.OutboxStats{Pending: 7, Lag: 1500 * time.Millisecond}}
+	relay := newTestRelay(t, store, &MockPublisher{})
+
+	relay.refreshStats(context.Background())
+
+	stats := relay.Stats()
+	assert.Equal(t, 7, stats.Pending)
+	assert.Equal(t, 1.5, stats.LagSeconds)
+}
@@ -2,4 +2,10 @@ package repo
 
 import "errors"
 
-var ErrNotFound = errors.New("not found")
+var (
+	ErrNotFound      = errors.New("not found")
+	ErrAlreadyExists = errors.New("already exists")
+	// ErrAlreadyConsumed means the message being processed was already
+	// applied, so the write was rolled back.
+	ErrAlreadyConsumed = errors.New("message already consumed")
+)
@@ -0,0 +1,25 @@
+package repo
+
+import "context"
+
+// ConsumedOffset is the position of a consumed Kafka message.
+type ConsumedOffset struct {
+	Group     string
+	Topic     string
+	Partition int
+	Offset    int64
+}
+
+type consumedOffsetKey struct{}
+
+// WithConsumedOffset marks ctx as processing the message at offset. Saving or
+// updating an order with the returned context records the offset in the same
+// transaction and fails with ErrAlreadyConsumed if it was recorded before.
+func WithConsumedOffset(ctx context.Context, offset ConsumedOffset) context.Context {
+	return context.WithValue(ctx, consumedOffsetKey{}, offset)
+}
+
+func ConsumedOffsetFrom(ctx context.Context) (ConsumedOffset, bool) {
+	offset, ok := ctx.Value(consumedOffsetKey{}).(ConsumedOffset)
+	return offset, ok
+}
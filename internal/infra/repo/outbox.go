@@ -0,0 +1,23 @@
+package repo
+
+import (
+	"order-service/internal/domain"
+	"time"
+)
+
+// OutboxMessage is an order event waiting in the outbox. Payload is the JSON
+// encoded domain.OrderEvent.
+type OutboxMessage struct {
+	Id        int64
+	OrderUID  string
+	Type      domain.EventType
+	Payload   []byte
+	Attempts  int
+	CreatedAt time.Time
+}
+
+type OutboxStats struct {
+	Pending int
+	// Lag is the age of the oldest unpublished event.
+	Lag time.Duration
+}
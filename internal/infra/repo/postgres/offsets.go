@@ -0,0 +1,91 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"order-service/internal/infra/repo"
+)
+
+// consumeOffsetTx records the offset of the message ctx is processing, if
+// any. It returns repo.ErrAlreadyConsumed when the offset is below the
+// committed offset of its partition or was recorded before, so a redelivered
+// message is not applied twice.
+func (p *PostgresDB) consumeOffsetTx(ctx context.Context, tx *sql.Tx) error {
+	offset, ok := repo.ConsumedOffsetFrom(ctx)
+	if !ok {
+		return nil
+	}
+
+	res, err := tx.ExecContext(ctx, `
+		INSERT INTO consumed_messages (group_id, topic, partition, "offset")
+		SELECT $1, $2, $3, $4
+		WHERE NOT EXISTS (
+			SELECT 1 FROM consumer_offsets
+			WHERE group_id = $1 AND topic = $2 AND partition = $3 AND next_offset > $4
+		)
+		ON CONFLICT DO NOTHING
+	`, offset.Group, offset.Topic, offset.Partition, offset.Offset)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return fmt.Errorf("%s/%d/%d: %w", offset.Topic, offset.Partition, offset.Offset, repo.ErrAlreadyConsumed)
+	}
+	return nil
+}
+
+// NextOffset returns the offset the group resumes the partition from, 0 when
+// none is stored.
+func (p *PostgresDB) NextOffset(ctx context.Context, group, topic string, partition int) (int64, error) {
+	var next int64
+	err := p.db.QueryRowContext(ctx, `
+		SELECT next_offset FROM consumer_offsets WHERE group_id = $1 AND topic = $2 AND partition = $3
+	`, group, topic, partition).Scan(&next)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	return next, nil
+}
+
+// CommitOffsets stores the offsets of the last processed message of each
+// partition. The group resumes the partitions after them, and the offsets
+// recorded by consumeOffsetTx up to them are no longer needed.
+func (p *PostgresDB) CommitOffsets(ctx context.Context, offsets []repo.ConsumedOffset) error {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	for _, o := range offsets {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO consumer_offsets (group_id, topic, partition, next_offset) VALUES ($1, $2, $3, $4)
+			ON CONFLICT (group_id, topic, partition) DO UPDATE
+			SET next_offset = GREATEST(consumer_offsets.next_offset, EXCLUDED.next_offset), updated_at = now()
+		`, o.Group, o.Topic, o.Partition, o.Offset+1); err != nil {
+			if err := tx.Rollback(); err != nil {
+				return err
+			}
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, `
+			DELETE FROM consumed_messages WHERE group_id = $1 AND topic = $2 AND partition = $3 AND "offset" <= $4
+		`, o.Group, o.Topic, o.Partition, o.Offset); err != nil {
+			if err := tx.Rollback(); err != nil {
+				return err
+			}
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
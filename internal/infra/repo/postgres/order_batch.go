@@ -0,0 +1,208 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"order-service/internal/domain"
+	"order-service/internal/infra/repo"
+	"strings"
+	"time"
+)
+
+// maxQueryParams is the Postgres limit of bind parameters per statement.
+const maxQueryParams = 65535
+
+// bulkInsertTx inserts rows with multi-row INSERT statements, splitting them
+// so that no statement exceeds maxQueryParams. query ends with "VALUES".
+func bulkInsertTx(ctx context.Context, tx *sql.Tx, query string, rows [][]any) error {
+	for _, chunk := range chunkRows(rows) {
+		q, args := valuesQuery(query, chunk)
+		if _, err := tx.ExecContext(ctx, q, args...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func chunkRows(rows [][]any) [][][]any {
+	if len(rows) == 0 {
+		return nil
+	}
+	size := maxQueryParams / len(rows[0])
+
+	var chunks [][][]any
+	for len(rows) > size {
+		chunks = append(chunks, rows[:size])
+		rows = rows[size:]
+	}
+	return append(chunks, rows)
+}
+
+func valuesQuery(query string, rows [][]any) (string, []any) {
+	var (
+		b    strings.Builder
+		args = make([]any, 0, len(rows)*len(rows[0]))
+	)
+	b.WriteString(query)
+	for i, row := range rows {
+		if i > 0 {
+			b.WriteString(",")
+		}
+		b.WriteString(" (")
+		for j, v := range row {
+			if j > 0 {
+				b.WriteString(",")
+			}
+			args = append(args, v)
+			fmt.Fprintf(&b, "$%d", len(args))
+		}
+		b.WriteString(")")
+	}
+	return b.String(), args
+}
+
+// saveOrdersTx inserts the orders that are not stored yet and returns their
+// ids by index. Orders whose uid is taken, in the table or earlier in the
+// batch, are skipped.
+func (p *PostgresDB) saveOrdersTx(ctx context.Context, tx *sql.Tx, orders []*domain.Order) (map[int]int, error) {
+	rows := make([][]any, len(orders))
+	for i, o := range orders {
+		rows[i] = []any{o.OrderUID, o.TrackNumber, o.Entry, o.CustomerID, o.DeliveryService,
+			o.DateCreated, o.Locale, o.InternalSignature, o.Shardkey, o.SmID, o.OofShard, o.Status, o.ContentHash()}
+	}
+
+	type inserted struct {
+		id          int
+		dateUpdated time.Time
+	}
+	byUID := make(map[string]inserted, len(orders))
+	for _, chunk := range chunkRows(rows) {
+		q, args := valuesQuery(`INSERT INTO orders
+	(order_uid, track_number, entry, customer_id, delivery_service,
+	date_created, locale, internal_signature, shardkey, sm_id, oof_shard, status, content_hash)
+	VALUES`, chunk)
+		result, err := tx.QueryContext(ctx, q+` ON CONFLICT (order_uid) DO NOTHING RETURNING order_uid, id, date_updated`, args...)
+		if err != nil {
+			return nil, err
+		}
+		for result.Next() {
+			var (
+				uid string
+				row inserted
+			)
+			if err := result.Scan(&uid, &row.id, &row.dateUpdated); err != nil {
+				_ = result.Close()
+				return nil, err
+			}
+			byUID[uid] = row
+		}
+		if err := result.Close(); err != nil {
+			return nil, err
+		}
+	}
+
+	ids := make(map[int]int, len(byUID))
+	for i, o := range orders {
+		row, ok := byUID[o.OrderUID]
+		if !ok {
+			continue
+		}
+		ids[i] = row.id
+		o.Id = row.id
+		o.DateUpdated = row.dateUpdated
+		delete(byUID, o.OrderUID)
+	}
+	return ids, nil
+}
+
+func (p *PostgresDB) saveOrderDetailsTx(ctx context.Context, tx *sql.Tx, orders []*domain.Order, ids map[int]int) error {
+	var deliveries, payments, items, history, events [][]any
+	for i, o := range orders {
+		id, ok := ids[i]
+		if !ok {
+			continue
+		}
+		if d := o.Delivery; d != nil {
+			deliveries = append(deliveries, []any{id, d.Name, d.Phone, d.Zip, d.City, d.Address, d.Region, d.Country, d.Email})
+		}
+		if pm := o.Payment; pm != nil {
+			payments = append(payments, []any{id, pm.Transaction, pm.RequestID, pm.Currency.String(), pm.Provider,
+				pm.Amount.Amount(), pm.PaymentDt, pm.Bank, pm.DeliveryCost.Amount(), pm.GoodsTotal.Amount(), pm.CustomFee.Amount()})
+		}
+		for _, it := range o.Items {
+			items = append(items, []any{id, it.ChrtID, it.TrackNumber, it.Price.Amount(), it.Rid, it.Name,
+				it.Sale, it.Size, it.TotalPrice.Amount(), it.NmID, it.Brand, it.Status})
+		}
+		for _, t := range o.StatusHistory {
+			history = append(history, []any{id, t.Status, t.ChangedAt})
+		}
+
+		payload, err := json.Marshal(domain.NewOrderEvent(domain.EventOrderCreated, o, time.Now().UTC()))
+		if err != nil {
+			return err
+		}
+		events = append(events, []any{o.OrderUID, domain.EventOrderCreated, payload})
+	}
+
+	for _, insert := range []struct {
+		query string
+		rows  [][]any
+	}{
+		{`INSERT INTO deliveries (order_id, name, phone, zip, city, address, region, country, email) VALUES`, deliveries},
+		{`INSERT INTO payments (order_id, transaction, request_id, currency, provider, amount, payment_dt, bank, delivery_cost, goods_total, custom_fee) VALUES`, payments},
+		{`INSERT INTO order_items (order_id, chrt_id, track_number, price, rid, name, sale, size, total_price, nm_id, brand, status) VALUES`, items},
+		{`INSERT INTO order_status_history (order_id, status, changed_at) VALUES`, history},
+		{`INSERT INTO order_outbox (order_uid, event_type, payload) VALUES`, events},
+	} {
+		if err := bulkInsertTx(ctx, tx, insert.query, insert.rows); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SaveOrders stores a batch of orders in one transaction using multi-row
+// inserts. The returned slice has an error for every order that was not
+// stored: repo.ErrAlreadyExists when its uid is taken. A non-nil error means
+// the transaction failed and nothing was stored.
+func (p *PostgresDB) SaveOrders(ctx context.Context, orders []*domain.Order) ([]error, error) {
+	if len(orders) == 0 {
+		return nil, nil
+	}
+
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	ids, err := p.saveOrdersTx(ctx, tx, orders)
+	if err != nil {
+		if err := tx.Rollback(); err != nil {
+			return nil, err
+		}
+		return nil, err
+	}
+
+	if err := p.saveOrderDetailsTx(ctx, tx, orders, ids); err != nil {
+		if err := tx.Rollback(); err != nil {
+			return nil, err
+		}
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	errs := make([]error, len(orders))
+	for i, o := range orders {
+		if id, ok := ids[i]; ok {
+			o.Id = id
+			continue
+		}
+		errs[i] = fmt.Errorf("order_uid %s: %w", o.OrderUID, repo.ErrAlreadyExists)
+	}
+	return errs, nil
+}
@@ -2,12 +2,14 @@ package postgres
 
 import (
 	"context"
+	"fmt"
 	"order-service/internal/domain"
+	"strings"
 )
 
 func (p *PostgresDB) getOrderItems(ctx context.Context, orderId int) ([]*domain.Item, error) {
 	var items []*domain.Item
-	rows, err := p.db.QueryContext(ctx, `SELECT chrt_id, track_number, price, rid, name, sale, size, total_price, nm_id, brand, status FROM order_items WHERE order_id = $1`, orderId)
+	rows, err := p.db.QueryContext(ctx, `SELECT i.chrt_id, i.track_number, i.price, i.rid, i.name, i.sale, i.size, i.total_price, i.nm_id, i.brand, i.status, p.currency FROM order_items i JOIN payments p ON i.order_id = p.order_id WHERE i.order_id = $1`, orderId)
 	if err != nil {
 		return nil, err
 	}
@@ -16,11 +18,11 @@ func (p *PostgresDB) getOrderItems(ctx context.Context, orderId int) ([]*domain.
 	}()
 
 	for rows.Next() {
-		var item domain.Item
-		if err := rows.Scan(&item.ChrtID, &item.TrackNumber, &item.Price, &item.Rid, &item.Name, &item.Sale, &item.Size, &item.TotalPrice, &item.NmID, &item.Brand, &item.Status); err != nil {
+		var item itemRow
+		if err := rows.Scan(&item.ChrtID, &item.TrackNumber, &item.Price, &item.Rid, &item.Name, &item.Sale, &item.Size, &item.TotalPrice, &item.NmID, &item.Brand, &item.Status, &item.Currency); err != nil {
 			return nil, err
 		}
-		items = append(items, &item)
+		items = append(items, item.toDomain())
 	}
 	return items, nil
 
@@ -28,10 +30,11 @@ func (p *PostgresDB) getOrderItems(ctx context.Context, orderId int) ([]*domain.
 
 func (p *PostgresDB) getItemsByOrderIds(ctx context.Context, orderIdArr []int) (map[int][]*domain.Item, error) {
 	query := `
-    SELECT order_id, chrt_id, track_number, price, rid, name, sale, size,
-           total_price, nm_id, brand, status
-    FROM order_items
-    WHERE order_id = ANY($1)
+    SELECT i.order_id, i.chrt_id, i.track_number, i.price, i.rid, i.name, i.sale, i.size,
+           i.total_price, i.nm_id, i.brand, i.status, p.currency
+    FROM order_items i
+    JOIN payments p ON i.order_id = p.order_id
+    WHERE i.order_id = ANY($1)
 `
 	rows, err := p.db.QueryContext(ctx, query, orderIdArr)
 	if err != nil {
@@ -46,16 +49,16 @@ func (p *PostgresDB) getItemsByOrderIds(ctx context.Context, orderIdArr []int) (
 	for rows.Next() {
 		var (
 			orderID int
-			item    domain.Item
+			item    itemRow
 		)
 		if err := rows.Scan(
 			&orderID, &item.ChrtID, &item.TrackNumber, &item.Price,
 			&item.Rid, &item.Name, &item.Sale, &item.Size,
-			&item.TotalPrice, &item.NmID, &item.Brand, &item.Status,
+			&item.TotalPrice, &item.NmID, &item.Brand, &item.Status, &item.Currency,
 		); err != nil {
 			return nil, err
 		}
-		itemsByOrder[orderID] = append(itemsByOrder[orderID], &item)
+		itemsByOrder[orderID] = append(itemsByOrder[orderID], item.toDomain())
 	}
 	if err := rows.Err(); err != nil {
 		return nil, err
@@ -71,20 +74,85 @@ func (p *PostgresDB) getOrdersIds(orders []*domain.Order) []int {
 	return ids
 }
 
-func (p *PostgresDB) getOrdersWithoutItems(ctx context.Context, limit int) ([]*domain.Order, error) {
-	rows, err := p.db.QueryContext(ctx, `
+const selectOrdersQuery = `
 		SELECT 
 			o.id, o.order_uid, o.track_number, o.entry, o.customer_id, o.delivery_service,
-			o.date_created, o.locale, o.internal_signature, o.shardkey, o.sm_id, o.oof_shard,
-			d.name, d.phone, d.zip, d.city, d.address, d.region, d.email,
+			o.date_created, o.date_updated, o.locale, o.internal_signature, o.shardkey, o.sm_id, o.oof_shard, o.status,
+			d.name, d.phone, d.zip, d.city, d.address, d.region, d.country, d.email,
 			p.transaction, p.request_id, p.currency, p.provider, p.amount, p.payment_dt, p.bank,
 			p.delivery_cost, p.goods_total, p.custom_fee
 		FROM orders o
 		JOIN deliveries d ON o.id = d.order_id
-		JOIN payments   p ON o.id = p.order_id
+		JOIN payments   p ON o.id = p.order_id`
+
+func (p *PostgresDB) getOrdersWithoutItems(ctx context.Context, limit int) ([]*domain.Order, error) {
+	return p.queryOrdersWithoutItems(ctx, selectOrdersQuery+`
 		ORDER BY o.date_created DESC
 		LIMIT $1
 	`, limit)
+}
+
+func (p *PostgresDB) getOrdersByUidsWithoutItems(ctx context.Context, uids []string) ([]*domain.Order, error) {
+	return p.queryOrdersWithoutItems(ctx, selectOrdersQuery+`
+		WHERE o.order_uid = ANY($1)
+	`, uids)
+}
+
+func (p *PostgresDB) searchOrdersWithoutItems(ctx context.Context, filter domain.OrderFilter) ([]*domain.Order, error) {
+	var (
+		conds []string
+		args  []any
+	)
+	where := func(cond string, values ...any) {
+		for _, v := range values {
+			args = append(args, v)
+			cond = strings.Replace(cond, "?", fmt.Sprintf("$%d", len(args)), 1)
+		}
+		conds = append(conds, cond)
+	}
+
+	if filter.CustomerID != "" {
+		where("o.customer_id = ?", filter.CustomerID)
+	}
+	if filter.TrackNumber != "" {
+		where("o.track_number = ?", filter.TrackNumber)
+	}
+	if filter.DeliveryService != "" {
+		where("o.delivery_service = ?", filter.DeliveryService)
+	}
+	if filter.Provider != "" {
+		where("p.provider = ?", filter.Provider)
+	}
+	if filter.Currency != "" {
+		where("p.currency = ?", filter.Currency.String())
+	}
+	if !filter.CreatedFrom.IsZero() {
+		where("o.date_created >= ?", filter.CreatedFrom)
+	}
+	if !filter.CreatedTo.IsZero() {
+		where("o.date_created < ?", filter.CreatedTo)
+	}
+
+	direction, cmp := "DESC", "<"
+	if filter.Sort == domain.SortAsc {
+		direction, cmp = "ASC", ">"
+	}
+	if filter.After != nil {
+		where("(o.date_created, o.id) "+cmp+" (?, ?)", filter.After.DateCreated, filter.After.Id)
+	}
+
+	query := selectOrdersQuery
+	if len(conds) > 0 {
+		query += "\n\t\tWHERE " + strings.Join(conds, " AND ")
+	}
+	args = append(args, filter.Limit)
+	query += fmt.Sprintf("\n\t\tORDER BY o.date_created %s, o.id %s\n\t\tLIMIT $%d", direction, direction, len(args))
+
+	return p.queryOrdersWithoutItems(ctx, query, args...)
+}
+
+func (p *PostgresDB) queryOrdersWithoutItems(ctx context.Context, query string, args ...any) ([]*domain.Order, error) {
+	rows, err := p.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -96,12 +164,12 @@ func (p *PostgresDB) getOrdersWithoutItems(ctx context.Context, limit int) ([]*d
 	for rows.Next() {
 		var o domain.Order
 		var delivery domain.Delivery
-		var payment domain.Payment
+		var payment paymentRow
 
 		if err := rows.Scan(
 			&o.Id, &o.OrderUID, &o.TrackNumber, &o.Entry, &o.CustomerID, &o.DeliveryService,
-			&o.DateCreated, &o.Locale, &o.InternalSignature, &o.Shardkey, &o.SmID, &o.OofShard,
-			&delivery.Name, &delivery.Phone, &delivery.Zip, &delivery.City, &delivery.Address, &delivery.Region, &delivery.Email,
+			&o.DateCreated, &o.DateUpdated, &o.Locale, &o.InternalSignature, &o.Shardkey, &o.SmID, &o.OofShard, &o.Status,
+			&delivery.Name, &delivery.Phone, &delivery.Zip, &delivery.City, &delivery.Address, &delivery.Region, &delivery.Country, &delivery.Email,
 			&payment.Transaction, &payment.RequestID, &payment.Currency, &payment.Provider, &payment.Amount, &payment.PaymentDt,
 			&payment.Bank, &payment.DeliveryCost, &payment.GoodsTotal, &payment.CustomFee,
 		); err != nil {
@@ -109,7 +177,7 @@ func (p *PostgresDB) getOrdersWithoutItems(ctx context.Context, limit int) ([]*d
 		}
 
 		o.Delivery = &delivery
-		o.Payment = &payment
+		o.Payment = payment.toDomain()
 		orders = append(orders, &o)
 	}
 
@@ -128,3 +196,67 @@ func (p *PostgresDB) attachItemsToOrder(orders []*domain.Order, items map[int][]
 		}
 	}
 }
+
+func (p *PostgresDB) getStatusHistory(ctx context.Context, orderId int) ([]domain.StatusTransition, error) {
+	var history []domain.StatusTransition
+	rows, err := p.db.QueryContext(ctx, `SELECT status, changed_at FROM order_status_history WHERE order_id = $1 ORDER BY changed_at, id`, orderId)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	for rows.Next() {
+		var transition domain.StatusTransition
+		if err := rows.Scan(&transition.Status, &transition.ChangedAt); err != nil {
+			return nil, err
+		}
+		history = append(history, transition)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return history, nil
+}
+
+func (p *PostgresDB) getStatusHistoryByOrderIds(ctx context.Context, orderIdArr []int) (map[int][]domain.StatusTransition, error) {
+	query := `
+    SELECT order_id, status, changed_at
+    FROM order_status_history
+    WHERE order_id = ANY($1)
+    ORDER BY changed_at, id
+`
+	rows, err := p.db.QueryContext(ctx, query, orderIdArr)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	historyByOrder := make(map[int][]domain.StatusTransition)
+
+	for rows.Next() {
+		var (
+			orderID    int
+			transition domain.StatusTransition
+		)
+		if err := rows.Scan(&orderID, &transition.Status, &transition.ChangedAt); err != nil {
+			return nil, err
+		}
+		historyByOrder[orderID] = append(historyByOrder[orderID], transition)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return historyByOrder, nil
+}
+
+func (p *PostgresDB) attachStatusHistoryToOrder(orders []*domain.Order, history map[int][]domain.StatusTransition) {
+	for _, o := range orders {
+		if h, ok := history[o.Id]; ok {
+			o.StatusHistory = h
+		}
+	}
+}
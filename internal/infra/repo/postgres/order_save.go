@@ -3,16 +3,30 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"fmt"
 	"order-service/internal/domain"
+	"order-service/internal/infra/repo"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const (
+	uniqueViolationCode      = "23505"
+	orderUIDUniqueConstraint = "orders_order_uid_key"
 )
 
+func isUniqueViolation(err error, constraint string) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == uniqueViolationCode && pgErr.ConstraintName == constraint
+}
+
 func (p *PostgresDB) saveOrderTx(ctx context.Context, tx *sql.Tx, order *domain.Order) (int, error) {
 	insertOrderQuery := `INSERT INTO orders 
 	(order_uid, track_number, entry, customer_id, delivery_service, 
-	date_created, locale, internal_signature, shardkey, sm_id, oof_shard)
-	VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11)
-	RETURNING id`
-	var orderId int
+	date_created, locale, internal_signature, shardkey, sm_id, oof_shard, status, content_hash)
+	VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13)
+	RETURNING id, date_updated`
 	err := tx.QueryRowContext(ctx, insertOrderQuery,
 		order.OrderUID,
 		order.TrackNumber,
@@ -24,18 +38,63 @@ func (p *PostgresDB) saveOrderTx(ctx context.Context, tx *sql.Tx, order *domain.
 		order.InternalSignature,
 		order.Shardkey,
 		order.SmID,
-		order.OofShard).Scan(&orderId)
+		order.OofShard,
+		order.Status,
+		order.ContentHash()).Scan(&order.Id, &order.DateUpdated)
+	if err != nil {
+		if isUniqueViolation(err, orderUIDUniqueConstraint) {
+			return -1, fmt.Errorf("order_uid %s: %w", order.OrderUID, repo.ErrAlreadyExists)
+		}
+		return -1, err
+	}
+
+	return order.Id, nil
+}
+
+// updateOrderTx keeps the content hash the order was created with, so a
+// redelivered create message is still recognized as a replay.
+func (p *PostgresDB) updateOrderTx(ctx context.Context, tx *sql.Tx, order *domain.Order) (int, error) {
+	updateOrderQuery := `UPDATE orders SET
+	track_number = $2, entry = $3, customer_id = $4, delivery_service = $5,
+	locale = $6, internal_signature = $7, shardkey = $8, sm_id = $9, oof_shard = $10,
+	date_updated = now()
+	WHERE order_uid = $1
+	RETURNING id, date_updated`
+	err := tx.QueryRowContext(ctx, updateOrderQuery,
+		order.OrderUID,
+		order.TrackNumber,
+		order.Entry,
+		order.CustomerID,
+		order.DeliveryService,
+		order.Locale,
+		order.InternalSignature,
+		order.Shardkey,
+		order.SmID,
+		order.OofShard).Scan(&order.Id, &order.DateUpdated)
 	if err != nil {
 		return -1, err
 	}
 
-	return orderId, nil
+	return order.Id, nil
+}
+
+func (p *PostgresDB) deleteOrderDetailsTx(ctx context.Context, tx *sql.Tx, orderId int) error {
+	for _, query := range []string{
+		`DELETE FROM deliveries WHERE order_id = $1`,
+		`DELETE FROM payments WHERE order_id = $1`,
+		`DELETE FROM order_items WHERE order_id = $1`,
+	} {
+		if _, err := tx.ExecContext(ctx, query, orderId); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (p *PostgresDB) saveDeliveryTx(ctx context.Context, tx *sql.Tx, orderId int, delivery *domain.Delivery) error {
 	insertDeliveryQuery := `INSERT INTO deliveries
-	(order_id, name, phone, zip, city, address, region, email)
-	VALUES ($1,$2,$3,$4,$5,$6,$7,$8)`
+	(order_id, name, phone, zip, city, address, region, country, email)
+	VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9)`
 	_, err := tx.ExecContext(ctx, insertDeliveryQuery,
 		orderId,
 		delivery.Name,
@@ -44,6 +103,7 @@ func (p *PostgresDB) saveDeliveryTx(ctx context.Context, tx *sql.Tx, orderId int
 		delivery.City,
 		delivery.Address,
 		delivery.Region,
+		delivery.Country,
 		delivery.Email)
 	if err != nil {
 		return err
@@ -60,14 +120,14 @@ func (p *PostgresDB) savePaymentsTx(ctx context.Context, tx *sql.Tx, orderId int
 		orderId,
 		payment.Transaction,
 		payment.RequestID,
-		payment.Currency,
+		payment.Currency.String(),
 		payment.Provider,
-		payment.Amount,
+		payment.Amount.Amount(),
 		payment.PaymentDt,
 		payment.Bank,
-		payment.DeliveryCost,
-		payment.GoodsTotal,
-		payment.CustomFee,
+		payment.DeliveryCost.Amount(),
+		payment.GoodsTotal.Amount(),
+		payment.CustomFee.Amount(),
 	)
 	return err
 }
@@ -89,12 +149,12 @@ func (p *PostgresDB) saveItemsTx(ctx context.Context, tx *sql.Tx, orderId int, i
 			orderId,
 			item.ChrtID,
 			item.TrackNumber,
-			item.Price,
+			item.Price.Amount(),
 			item.Rid,
 			item.Name,
 			item.Sale,
 			item.Size,
-			item.TotalPrice,
+			item.TotalPrice.Amount(),
 			item.NmID,
 			item.Brand,
 			item.Status,
@@ -106,3 +166,25 @@ func (p *PostgresDB) saveItemsTx(ctx context.Context, tx *sql.Tx, orderId int, i
 
 	return nil
 }
+
+func (p *PostgresDB) saveStatusHistoryTx(ctx context.Context, tx *sql.Tx, orderId int, history []domain.StatusTransition) error {
+	insertStatusQuery := `INSERT INTO order_status_history
+	(order_id, status, changed_at)
+	VALUES ($1,$2,$3)`
+
+	stmt, err := tx.PrepareContext(ctx, insertStatusQuery)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = stmt.Close()
+	}()
+	for _, transition := range history {
+		_, err := stmt.ExecContext(ctx, orderId, transition.Status, transition.ChangedAt)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,96 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"order-service/internal/domain"
+	"order-service/internal/infra/repo"
+	"sort"
+	"time"
+)
+
+func (p *PostgresDB) saveOutboxTx(ctx context.Context, tx *sql.Tx, eventType domain.EventType, order *domain.Order) error {
+	payload, err := json.Marshal(domain.NewOrderEvent(eventType, order, time.Now().UTC()))
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `INSERT INTO order_outbox (order_uid, event_type, payload) VALUES ($1, $2, $3)`,
+		order.OrderUID, eventType, payload)
+	return err
+}
+
+// ClaimOutbox leases up to limit events for publishing. Only the oldest
+// unpublished event of each order is eligible, so events of one order are
+// published in order even across retries and relay instances. A claimed
+// event that is neither published nor failed becomes eligible again after
+// the lease.
+func (p *PostgresDB) ClaimOutbox(ctx context.Context, limit int, lease time.Duration) ([]repo.OutboxMessage, error) {
+	rows, err := p.db.QueryContext(ctx, `
+		UPDATE order_outbox SET next_attempt_at = now() + make_interval(secs => $2)
+		WHERE id IN (
+			SELECT o.id FROM order_outbox o
+			WHERE o.published_at IS NULL AND o.next_attempt_at <= now()
+			  AND NOT EXISTS (
+				SELECT 1 FROM order_outbox e
+				WHERE e.order_uid = o.order_uid AND e.published_at IS NULL AND e.id < o.id
+			  )
+			ORDER BY o.id
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, order_uid, event_type, payload, attempts, created_at
+	`, limit, lease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var msgs []repo.OutboxMessage
+	for rows.Next() {
+		var m repo.OutboxMessage
+		if err := rows.Scan(&m.Id, &m.OrderUID, &m.Type, &m.Payload, &m.Attempts, &m.CreatedAt); err != nil {
+			return nil, err
+		}
+		msgs = append(msgs, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(msgs, func(i, j int) bool { return msgs[i].Id < msgs[j].Id })
+	return msgs, nil
+}
+
+func (p *PostgresDB) MarkOutboxPublished(ctx context.Context, ids []int64) error {
+	_, err := p.db.ExecContext(ctx, `UPDATE order_outbox SET published_at = now(), last_error = NULL WHERE id = ANY($1)`, ids)
+	return err
+}
+
+func (p *PostgresDB) MarkOutboxFailed(ctx context.Context, id int64, retryAfter time.Duration, cause string) error {
+	_, err := p.db.ExecContext(ctx, `
+		UPDATE order_outbox
+		SET attempts = attempts + 1, next_attempt_at = now() + make_interval(secs => $2), last_error = $3
+		WHERE id = $1
+	`, id, retryAfter.Seconds(), cause)
+	return err
+}
+
+func (p *PostgresDB) OutboxStats(ctx context.Context) (repo.OutboxStats, error) {
+	var (
+		stats repo.OutboxStats
+		lag   float64
+	)
+	err := p.db.QueryRowContext(ctx, `
+		SELECT count(*), COALESCE(EXTRACT(EPOCH FROM now() - min(created_at)), 0)
+		FROM order_outbox WHERE published_at IS NULL
+	`).Scan(&stats.Pending, &lag)
+	if err != nil {
+		return stats, err
+	}
+	stats.Lag = time.Duration(lag * float64(time.Second))
+	return stats, nil
+}
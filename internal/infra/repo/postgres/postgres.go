@@ -20,12 +20,22 @@ func NewPostgresDB(db *sql.DB) *PostgresDB {
 	}
 }
 
+// SaveOrder sets the stored id and update time on order. It returns
+// repo.ErrAlreadyExists if an order with the same uid is already stored. An OrderCreated event is written to the outbox in the same
+// transaction, and so is the offset of the message ctx is processing.
 func (p *PostgresDB) SaveOrder(ctx context.Context, order *domain.Order) error {
 	tx, err := p.db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
 
+	if err := p.consumeOffsetTx(ctx, tx); err != nil {
+		if err := tx.Rollback(); err != nil {
+			return err
+		}
+		return err
+	}
+
 	orderID, err := p.saveOrderTx(ctx, tx, order)
 	if err != nil {
 		if err := tx.Rollback(); err != nil {
@@ -60,15 +70,163 @@ func (p *PostgresDB) SaveOrder(ctx context.Context, order *domain.Order) error {
 			return err
 		}
 	}
+
+	if len(order.StatusHistory) > 0 {
+		if err := p.saveStatusHistoryTx(ctx, tx, orderID, order.StatusHistory); err != nil {
+			if err := tx.Rollback(); err != nil {
+				return err
+			}
+			return err
+		}
+	}
+
+	if err := p.saveOutboxTx(ctx, tx, domain.EventOrderCreated, order); err != nil {
+		if err := tx.Rollback(); err != nil {
+			return err
+		}
+		return err
+	}
+	return tx.Commit()
+}
+
+func (p *PostgresDB) UpdateOrder(ctx context.Context, order *domain.Order) error {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := p.consumeOffsetTx(ctx, tx); err != nil {
+		if err := tx.Rollback(); err != nil {
+			return err
+		}
+		return err
+	}
+
+	orderID, err := p.updateOrderTx(ctx, tx, order)
+	if err != nil {
+		if err := tx.Rollback(); err != nil {
+			return err
+		}
+		if errors.Is(err, sql.ErrNoRows) {
+			return repo.ErrNotFound
+		}
+		return err
+	}
+
+	if err := p.deleteOrderDetailsTx(ctx, tx, orderID); err != nil {
+		if err := tx.Rollback(); err != nil {
+			return err
+		}
+		return err
+	}
+
+	if order.Delivery != nil {
+		if err := p.saveDeliveryTx(ctx, tx, orderID, order.Delivery); err != nil {
+			if err := tx.Rollback(); err != nil {
+				return err
+			}
+			return err
+		}
+	}
+
+	if order.Payment != nil {
+		if err := p.savePaymentsTx(ctx, tx, orderID, order.Payment); err != nil {
+			if err := tx.Rollback(); err != nil {
+				return err
+			}
+			return err
+		}
+	}
+
+	if len(order.Items) > 0 {
+		if err := p.saveItemsTx(ctx, tx, orderID, order.Items); err != nil {
+			if err := tx.Rollback(); err != nil {
+				return err
+			}
+			return err
+		}
+	}
+
+	if err := p.saveOutboxTx(ctx, tx, domain.EventOrderUpdated, order); err != nil {
+		if err := tx.Rollback(); err != nil {
+			return err
+		}
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	order.Id = orderID
+
+	return nil
+}
+
+// UpdateOrderStatus stores the last transition of the order. It applies only
+// if the stored status is still the one the transition started from, so of
+// two concurrent transitions from the same status one fails with
+// domain.ErrInvalidState.
+func (p *PostgresDB) UpdateOrderStatus(ctx context.Context, order *domain.Order) error {
+	if len(order.StatusHistory) < 2 {
+		return fmt.Errorf("order %s has no status transitions: %w", order.OrderUID, domain.ErrInvalidState)
+	}
+	prev := order.StatusHistory[len(order.StatusHistory)-2]
+	last := order.StatusHistory[len(order.StatusHistory)-1]
+
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	var orderID int
+	err = tx.QueryRowContext(ctx, `UPDATE orders SET status = $1, date_updated = now() WHERE order_uid = $2 AND status = $3 RETURNING id, date_updated`,
+		order.Status, order.OrderUID, prev.Status).Scan(&orderID, &order.DateUpdated)
+	if errors.Is(err, sql.ErrNoRows) {
+		err = p.statusConflictTx(ctx, tx, order.OrderUID, prev.Status)
+	}
+	if err != nil {
+		if err := tx.Rollback(); err != nil {
+			return err
+		}
+		return err
+	}
+
+	if err := p.saveStatusHistoryTx(ctx, tx, orderID, []domain.StatusTransition{last}); err != nil {
+		if err := tx.Rollback(); err != nil {
+			return err
+		}
+		return err
+	}
+
+	if err := p.saveOutboxTx(ctx, tx, domain.EventOrderStatusChanged, order); err != nil {
+		if err := tx.Rollback(); err != nil {
+			return err
+		}
+		return err
+	}
+
 	return tx.Commit()
 }
 
+// statusConflictTx explains why a status update matched no rows.
+func (p *PostgresDB) statusConflictTx(ctx context.Context, tx *sql.Tx, orderUID string, prev domain.Status) error {
+	var current domain.Status
+	err := tx.QueryRowContext(ctx, `SELECT status FROM orders WHERE order_uid = $1`, orderUID).Scan(&current)
+	if errors.Is(err, sql.ErrNoRows) {
+		return repo.ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	return fmt.Errorf("order %s status is %s, not %s: %w", orderUID, current, prev, domain.ErrInvalidState)
+}
+
 func (p *PostgresDB) GetOrderByUid(ctx context.Context, orderUID string) (*domain.Order, error) {
 	row := p.db.QueryRowContext(ctx, `
 	SELECT 
     o.id, o.order_uid, o.track_number, o.entry, o.customer_id, o.delivery_service,
-    o.date_created, o.date_updated, o.locale, o.internal_signature, o.shardkey, o.sm_id, o.oof_shard,
-    d.id AS delivery_id, d.name, d.phone, d.zip, d.city, d.address, d.region, d.email,
+    o.date_created, o.date_updated, o.locale, o.internal_signature, o.shardkey, o.sm_id, o.oof_shard, o.status,
+    d.id AS delivery_id, d.name, d.phone, d.zip, d.city, d.address, d.region, d.country, d.email,
     p.id AS payment_id, p.transaction, p.request_id, p.currency, p.provider, p.amount, p.payment_dt, p.bank, p.delivery_cost, p.goods_total, p.custom_fee
 	FROM orders o
 	JOIN deliveries d ON o.id = d.order_id
@@ -78,14 +236,14 @@ func (p *PostgresDB) GetOrderByUid(ctx context.Context, orderUID string) (*domai
 	var orderId int
 	var order domain.Order
 	var delivery domain.Delivery
-	var payment domain.Payment
+	var payment paymentRow
 	var deliveryID, paymentID int
 	var orderUpdated time.Time
 
 	err := row.Scan(
 		&orderId, &order.OrderUID, &order.TrackNumber, &order.Entry, &order.CustomerID, &order.DeliveryService,
-		&order.DateCreated, &orderUpdated, &order.Locale, &order.InternalSignature, &order.Shardkey, &order.SmID, &order.OofShard,
-		&deliveryID, &delivery.Name, &delivery.Phone, &delivery.Zip, &delivery.City, &delivery.Address, &delivery.Region, &delivery.Email,
+		&order.DateCreated, &orderUpdated, &order.Locale, &order.InternalSignature, &order.Shardkey, &order.SmID, &order.OofShard, &order.Status,
+		&deliveryID, &delivery.Name, &delivery.Phone, &delivery.Zip, &delivery.City, &delivery.Address, &delivery.Region, &delivery.Country, &delivery.Email,
 		&paymentID, &payment.Transaction, &payment.RequestID, &payment.Currency, &payment.Provider, &payment.Amount, &payment.PaymentDt, &payment.Bank, &payment.DeliveryCost, &payment.GoodsTotal, &payment.CustomFee,
 	)
 	if err != nil {
@@ -95,8 +253,10 @@ func (p *PostgresDB) GetOrderByUid(ctx context.Context, orderUID string) (*domai
 		return nil, err
 	}
 
+	order.Id = orderId
+	order.DateUpdated = orderUpdated
 	order.Delivery = &delivery
-	order.Payment = &payment
+	order.Payment = payment.toDomain()
 
 	items, err := p.getOrderItems(ctx, orderId)
 	if err != nil {
@@ -104,17 +264,28 @@ func (p *PostgresDB) GetOrderByUid(ctx context.Context, orderUID string) (*domai
 	}
 	order.Items = items
 
+	history, err := p.getStatusHistory(ctx, orderId)
+	if err != nil {
+		return nil, fmt.Errorf("error getting status history %w", err)
+	}
+	order.StatusHistory = history
+
 	return &order, nil
 
 }
 
-func (p *PostgresDB) CheckIdempotencyKey(ctx context.Context, key string) (bool, error) {
-	var exists bool
-	err := p.db.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM orders WHERE order_uid = $1)`, key).Scan(&exists)
+// GetContentHash returns the content hash stored for the order. Orders saved
+// before hashes were introduced have an empty hash.
+func (p *PostgresDB) GetContentHash(ctx context.Context, orderUID string) (string, error) {
+	var hash string
+	err := p.db.QueryRowContext(ctx, `SELECT COALESCE(content_hash, '') FROM orders WHERE order_uid = $1`, orderUID).Scan(&hash)
 	if err != nil {
-		return false, err
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", repo.ErrNotFound
+		}
+		return "", err
 	}
-	return exists, nil
+	return hash, nil
 }
 
 func (p *PostgresDB) GetLastOrders(ctx context.Context, limit int) ([]*domain.Order, error) {
@@ -122,7 +293,59 @@ func (p *PostgresDB) GetLastOrders(ctx context.Context, limit int) ([]*domain.Or
 	if err != nil {
 		return nil, err
 	}
+	return p.withDetails(ctx, orders)
+}
 
+// GetOrdersByUids returns the stored orders among uids, in no particular
+// order.
+func (p *PostgresDB) GetOrdersByUids(ctx context.Context, uids []string) ([]*domain.Order, error) {
+	orders, err := p.getOrdersByUidsWithoutItems(ctx, uids)
+	if err != nil {
+		return nil, err
+	}
+	return p.withDetails(ctx, orders)
+}
+
+// GetOrderVersions returns the last update time of the stored orders among
+// uids, which tells whether a copy of an order is still current.
+func (p *PostgresDB) GetOrderVersions(ctx context.Context, uids []string) (map[string]time.Time, error) {
+	rows, err := p.db.QueryContext(ctx, `SELECT order_uid, date_updated FROM orders WHERE order_uid = ANY($1)`, uids)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	versions := make(map[string]time.Time, len(uids))
+	for rows.Next() {
+		var (
+			uid     string
+			updated time.Time
+		)
+		if err := rows.Scan(&uid, &updated); err != nil {
+			return nil, err
+		}
+		versions[uid] = updated
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
+
+// SearchOrders returns up to filter.Limit orders matching the filter, ordered
+// by (date_created, id) in the filter's direction and starting after
+// filter.After.
+func (p *PostgresDB) SearchOrders(ctx context.Context, filter domain.OrderFilter) ([]*domain.Order, error) {
+	orders, err := p.searchOrdersWithoutItems(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	return p.withDetails(ctx, orders)
+}
+
+func (p *PostgresDB) withDetails(ctx context.Context, orders []*domain.Order) ([]*domain.Order, error) {
 	orderIds := p.getOrdersIds(orders)
 
 	orderItems, err := p.getItemsByOrderIds(ctx, orderIds)
@@ -130,8 +353,13 @@ func (p *PostgresDB) GetLastOrders(ctx context.Context, limit int) ([]*domain.Or
 		return nil, err
 	}
 	p.attachItemsToOrder(orders, orderItems)
-	return orders, nil
 
+	statusHistory, err := p.getStatusHistoryByOrderIds(ctx, orderIds)
+	if err != nil {
+		return nil, err
+	}
+	p.attachStatusHistoryToOrder(orders, statusHistory)
+	return orders, nil
 }
 
 func (p *PostgresDB) Close() error {
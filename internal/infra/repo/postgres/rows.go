@@ -0,0 +1,66 @@
+package postgres
+
+import "order-service/internal/domain"
+
+// paymentRow and itemRow hold money columns as raw minor units until the
+// payment currency is known.
+type paymentRow struct {
+	Transaction  string
+	RequestID    string
+	Currency     string
+	Provider     string
+	Amount       int64
+	PaymentDt    int
+	Bank         string
+	DeliveryCost int64
+	GoodsTotal   int64
+	CustomFee    int64
+}
+
+func (r paymentRow) toDomain() *domain.Payment {
+	currency := domain.Currency(r.Currency)
+	return &domain.Payment{
+		Transaction:  r.Transaction,
+		RequestID:    r.RequestID,
+		Currency:     currency,
+		Provider:     r.Provider,
+		Amount:       domain.NewMoney(r.Amount, currency),
+		PaymentDt:    r.PaymentDt,
+		Bank:         r.Bank,
+		DeliveryCost: domain.NewMoney(r.DeliveryCost, currency),
+		GoodsTotal:   domain.NewMoney(r.GoodsTotal, currency),
+		CustomFee:    domain.NewMoney(r.CustomFee, currency),
+	}
+}
+
+type itemRow struct {
+	ChrtID      int
+	TrackNumber string
+	Price       int64
+	Rid         string
+	Name        string
+	Sale        int
+	Size        string
+	TotalPrice  int64
+	NmID        int
+	Brand       string
+	Status      int
+	Currency    string
+}
+
+func (r itemRow) toDomain() *domain.Item {
+	currency := domain.Currency(r.Currency)
+	return &domain.Item{
+		ChrtID:      r.ChrtID,
+		TrackNumber: r.TrackNumber,
+		Price:       domain.NewMoney(r.Price, currency),
+		Rid:         r.Rid,
+		Name:        r.Name,
+		Sale:        r.Sale,
+		Size:        r.Size,
+		TotalPrice:  domain.NewMoney(r.TotalPrice, currency),
+		NmID:        r.NmID,
+		Brand:       r.Brand,
+		Status:      r.Status,
+	}
+}
@@ -0,0 +1,71 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"fmt"
+	"order-service/internal/domain"
+	"order-service/internal/infra/repo"
+	"order-service/internal/infra/repo/postgres"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSaveOrdersBatch(t *testing.T) {
+	ctx := context.Background()
+	db := setupTestDB(t, ctx)
+	defer teardownTestDB(t, db)
+	pg := postgres.NewPostgresDB(db)
+
+	newOrder := func(uid string) *domain.Order {
+		order, err := domain.NewOrder(domain.OrderParams{
+			OrderUID:    uid,
+			TrackNumber: "WBILMTESTTRACK",
+			Entry:       "WBIL",
+			Delivery: domain.DeliveryParams{
+				Name: "Test Testov", Phone: "+9720000000", Zip: "2639809", City: "Kiryat Mozkin",
+				Address: "Ploshad Mira 15", Region: "Kraiot", Email: "test@gmail.com",
+			},
+			Payment: domain.PaymentParams{
+				Transaction: uid, Currency: "USD", Provider: "wbpay", Amount: 1817,
+				PaymentDt: 1637907727, Bank: "alpha", DeliveryCost: 1500, GoodsTotal: 317,
+			},
+			Items: []domain.ItemParams{
+				{ChrtID: 9934930, TrackNumber: "WBILMTESTTRACK", Price: 453, Name: "Mascaras", Sale: 30, TotalPrice: 317, Status: 202},
+			},
+			DateCreated: time.Date(2021, 11, 26, 6, 22, 19, 0, time.UTC),
+		})
+		require.NoError(t, err)
+		return order
+	}
+
+	require.NoError(t, pg.SaveOrder(ctx, newOrder("batch-stored")))
+
+	orders := []*domain.Order{newOrder("batch-stored")}
+	for i := 0; i < 50; i++ {
+		orders = append(orders, newOrder(fmt.Sprintf("batch-%d", i)))
+	}
+	orders = append(orders, newOrder("batch-0"))
+
+	errs, err := pg.SaveOrders(ctx, orders)
+	require.NoError(t, err)
+	require.Len(t, errs, len(orders))
+	assert.ErrorIs(t, errs[0], repo.ErrAlreadyExists)
+	assert.ErrorIs(t, errs[len(errs)-1], repo.ErrAlreadyExists)
+	for _, err := range errs[1 : len(errs)-1] {
+		assert.NoError(t, err)
+	}
+
+	stored, err := pg.GetOrderByUid(ctx, "batch-7")
+	require.NoError(t, err)
+	assert.Len(t, stored.Items, 1)
+	assert.Equal(t, "USD", stored.Payment.Currency.String())
+
+	var events int
+	require.NoError(t, db.QueryRowContext(ctx, `SELECT count(*) FROM order_outbox WHERE order_uid LIKE 'batch-%'`).Scan(&events))
+	assert.Equal(t, 51, events)
+}
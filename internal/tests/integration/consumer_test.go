@@ -243,7 +243,7 @@ func TestBrokerIntegration(t *testing.T) {
 		t.Fatalf("conumer id nil")
 	}
 
-	broker := broker.NewBroker(consumer)
+	broker := broker.NewBroker(consumer, logger)
 	go broker.Run(ctx)
 
 	if err := produceTestMessages(cfg, msgs); err != nil {
@@ -298,6 +298,10 @@ func createKafkaCfg(addr string) *config.KafkaConfig {
 			KafkaTopic: "orders",
 			GroupID:    "test-orders",
 		},
+		UpdateTopicCfg: config.UpdateTopicConfig{
+			KafkaTopic: "order-updates",
+			GroupID:    "test-order-updates",
+		},
 		RetryTopicCfg: config.RetryTopicConfig{
 			KafkaTopic: "retry-order",
 			GroupID:    "test-retry",
@@ -330,6 +334,7 @@ func createTopics(t *testing.T, cfg config.KafkaConfig) {
 
 	topics := []kafkago.TopicConfig{
 		{Topic: cfg.OrderTopicCfg.KafkaTopic, NumPartitions: 1, ReplicationFactor: 1},
+		{Topic: cfg.UpdateTopicCfg.KafkaTopic, NumPartitions: 1, ReplicationFactor: 1},
 		{Topic: cfg.RetryTopicCfg.KafkaTopic, NumPartitions: 1, ReplicationFactor: 1},
 		{Topic: cfg.DLQTopicCfg.KafkaTopic, NumPartitions: 1, ReplicationFactor: 1},
 	}
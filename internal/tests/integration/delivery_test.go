@@ -0,0 +1,59 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"order-service/internal/domain"
+	"order-service/internal/infra/repo/postgres"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeliveryCountryRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	db := setupTestDB(t, ctx)
+	defer teardownTestDB(t, db)
+	pg := postgres.NewPostgresDB(db)
+
+	newOrder := func(uid string) *domain.Order {
+		order, err := domain.NewOrder(domain.OrderParams{
+			OrderUID:    uid,
+			TrackNumber: "WBILMTESTTRACK",
+			Entry:       "WBIL",
+			Delivery: domain.DeliveryParams{
+				Name: "Test Testov", Phone: "+9720000000", Zip: "10001", City: "New York",
+				Address: "Broadway 1", Region: "NY", Country: "US", Email: "test@gmail.com",
+			},
+			Payment: domain.PaymentParams{
+				Transaction: uid, Currency: "USD", Provider: "wbpay", Amount: 1817,
+				PaymentDt: 1637907727, Bank: "alpha", DeliveryCost: 1500, GoodsTotal: 317,
+			},
+			Items: []domain.ItemParams{
+				{ChrtID: 9934930, TrackNumber: "WBILMTESTTRACK", Price: 453, Name: "Mascaras", Sale: 30, TotalPrice: 317, Status: 202},
+			},
+			DateCreated: time.Date(2021, 11, 26, 6, 22, 19, 0, time.UTC),
+		})
+		require.NoError(t, err)
+		return order
+	}
+
+	single, batched := newOrder("country-order-uid"), newOrder("country-batch-order-uid")
+	require.NoError(t, pg.SaveOrder(ctx, single))
+	errs, err := pg.SaveOrders(ctx, []*domain.Order{batched})
+	require.NoError(t, err)
+	require.NoError(t, errs[0])
+
+	stored, err := pg.GetOrderByUid(ctx, single.OrderUID)
+	require.NoError(t, err)
+	assert.Equal(t, "US", stored.Delivery.Country)
+	assert.Equal(t, single.ContentHash(), stored.ContentHash())
+
+	orders, err := pg.GetOrdersByUids(ctx, []string{batched.OrderUID})
+	require.NoError(t, err)
+	require.Len(t, orders, 1)
+	assert.Equal(t, "US", orders[0].Delivery.Country)
+}
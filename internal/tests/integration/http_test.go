@@ -5,6 +5,7 @@ package integration
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -24,7 +25,7 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
-var expectedJSON = `{"order_uid":"b563feb7b2b84b6test","track_number":"WBILMTESTTRACK","delivery":{"name":"Test Testov","phone":"+9720000000","zip":"2639809","city":"Kiryat Mozkin","address":"Ploshad Mira 15","region":"Kraiot","email":"test@gmail.com"},"payment":{"transaction":"b563feb7b2b84b6test","request_id":"","currency":"USD","provider":"wbpay","amount":1817,"bank":"alpha","delivery_cost":1500,"goods_total":317,"custom_fee":0},"items":[{"chrt_id":9934930,"track_number":"WBILMTESTTRACK","price":453,"name":"Mascaras","sale":30,"size":"0","total_price":317,"brand":"Vivienne Sabo","status":202}],"customer_id":"test","delivery_service":"meest","date_created":"2021-11-26T06:22:19Z"}`
+var expectedJSON = `{"order_uid":"b563feb7b2b84b6test","track_number":"WBILMTESTTRACK","delivery":{"name":"Test Testov","phone":"+9720000000","zip":"2639809","city":"Kiryat Mozkin","address":"Ploshad Mira 15","region":"Kraiot","email":"test@gmail.com"},"payment":{"transaction":"b563feb7b2b84b6test","request_id":"","currency":"USD","currency_exponent":2,"provider":"wbpay","amount":1817,"amount_formatted":"18.17 USD","bank":"alpha","delivery_cost":1500,"delivery_cost_formatted":"15.00 USD","goods_total":317,"goods_total_formatted":"3.17 USD","custom_fee":0,"custom_fee_formatted":"0.00 USD"},"items":[{"chrt_id":9934930,"track_number":"WBILMTESTTRACK","price":453,"price_formatted":"4.53 USD","name":"Mascaras","sale":30,"size":"0","total_price":317,"total_price_formatted":"3.17 USD","brand":"Vivienne Sabo","status":202}],"customer_id":"test","delivery_service":"meest","date_created":"2021-11-26T06:22:19Z","status":"created","status_history":[{"status":"created","changed_at":"2021-11-26T06:22:19Z"}]}`
 
 func TestGetOrderHTTP(t *testing.T) {
 	ctx := context.Background()
@@ -55,7 +56,13 @@ func TestGetOrderHTTP(t *testing.T) {
 	body, err := io.ReadAll(resp.Body)
 	require.NoError(t, err)
 	t.Logf("body: %s", string(body))
-	assert.JSONEq(t, expectedJSON, string(body))
+
+	var got map[string]any
+	require.NoError(t, json.Unmarshal(body, &got))
+	delete(got, "date_updated")
+	normalized, err := json.Marshal(got)
+	require.NoError(t, err)
+	assert.JSONEq(t, expectedJSON, string(normalized))
 
 }
 
@@ -0,0 +1,109 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"order-service/internal/domain"
+	"order-service/internal/usecase"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateOrderConcurrently(t *testing.T) {
+	ctx := context.Background()
+	db := setupTestDB(t, ctx)
+	defer teardownTestDB(t, db)
+
+	params := idempotencyOrderParams("concurrent-order-uid")
+
+	const workers = 10
+	errs := make(chan error, workers)
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		// separate use cases so no consumer sees another one's cache
+		uc := buildUCase(t, db)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			errs <- uc.CreateOrder(ctx, params)
+		}()
+	}
+	close(start)
+	wg.Wait()
+	close(errs)
+
+	created := 0
+	for err := range errs {
+		if err == nil {
+			created++
+			continue
+		}
+		assert.ErrorIs(t, err, usecase.ErrDuplicateOrder)
+	}
+	assert.Equal(t, 1, created)
+
+	var count int
+	require.NoError(t, db.QueryRowContext(ctx, `SELECT count(*) FROM orders WHERE order_uid = $1`, params.OrderUID).Scan(&count))
+	assert.Equal(t, 1, count)
+}
+
+func TestCreateOrderCachesStoredID(t *testing.T) {
+	ctx := context.Background()
+	db := setupTestDB(t, ctx)
+	defer teardownTestDB(t, db)
+	uc := buildUCase(t, db)
+
+	require.NoError(t, uc.CreateOrder(ctx, idempotencyOrderParams("cached-id-order-uid")))
+	errs := uc.CreateOrders(ctx, []domain.OrderParams{idempotencyOrderParams("cached-id-batch-order-uid")})
+	require.NoError(t, errs[0])
+
+	for _, uid := range []string{"cached-id-order-uid", "cached-id-batch-order-uid"} {
+		var id int
+		require.NoError(t, db.QueryRowContext(ctx, `SELECT id FROM orders WHERE order_uid = $1`, uid).Scan(&id))
+
+		// served from the cache filled on creation
+		cached, err := uc.GetOrder(ctx, uid)
+		require.NoError(t, err)
+		assert.Equal(t, id, cached.Id, uid)
+	}
+}
+
+func idempotencyOrderParams(uid string) domain.OrderParams {
+	return domain.OrderParams{
+		OrderUID:    uid,
+		TrackNumber: "WBILMTESTTRACK",
+		Entry:       "WBIL",
+		Delivery: domain.DeliveryParams{
+			Name:    "Test Testov",
+			Phone:   "+9720000000",
+			Zip:     "2639809",
+			City:    "Kiryat Mozkin",
+			Address: "Ploshad Mira 15",
+			Region:  "Kraiot",
+			Email:   "test@gmail.com",
+		},
+		Payment: domain.PaymentParams{
+			Transaction:  uid,
+			Currency:     "USD",
+			Provider:     "wbpay",
+			Amount:       1817,
+			PaymentDt:    1637907727,
+			Bank:         "alpha",
+			DeliveryCost: 1500,
+			GoodsTotal:   317,
+		},
+		Items: []domain.ItemParams{
+			{ChrtID: 9934930, TrackNumber: "WBILMTESTTRACK", Price: 453, Rid: "ab4219087a764ae0btest", Name: "Mascaras", Sale: 30, Size: "0", TotalPrice: 317, NmID: 2389212, Brand: "Vivienne Sabo", Status: 202},
+		},
+		Locale:      "en",
+		CustomerID:  "test",
+		DateCreated: time.Date(2021, 11, 26, 6, 22, 19, 0, time.UTC),
+	}
+}
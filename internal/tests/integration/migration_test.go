@@ -82,7 +82,7 @@ func TestMigrationWithDB(t *testing.T) {
 	})
 
 	t.Run("check schema", func(t *testing.T) {
-		tables := []string{"orders", "order_items", "payments", "deliveries"}
+		tables := []string{"orders", "order_items", "payments", "deliveries", "order_status_history", "order_outbox", "consumer_offsets", "consumed_messages"}
 		for _, table := range tables {
 			var exists bool
 			err := db.QueryRow(
@@ -96,7 +96,7 @@ func TestMigrationWithDB(t *testing.T) {
 	})
 
 	t.Run("rollback", func(t *testing.T) {
-		if err := goose.DownContext(ctx, db, migrationsDir); err != nil {
+		if err := goose.DownToContext(ctx, db, migrationsDir, 0); err != nil {
 			t.Fatalf("failed to rollback migrations: %s", err)
 		}
 
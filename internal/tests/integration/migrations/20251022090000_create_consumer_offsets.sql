@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS consumer_offsets (
+    group_id VARCHAR(255) NOT NULL,
+    topic VARCHAR(255) NOT NULL,
+    partition INT NOT NULL,
+    next_offset BIGINT NOT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT now(),
+    PRIMARY KEY (group_id, topic, partition)
+);
+
+CREATE TABLE IF NOT EXISTS consumed_messages (
+    group_id VARCHAR(255) NOT NULL,
+    topic VARCHAR(255) NOT NULL,
+    partition INT NOT NULL,
+    "offset" BIGINT NOT NULL,
+    consumed_at TIMESTAMP NOT NULL DEFAULT now(),
+    PRIMARY KEY (group_id, topic, partition, "offset")
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS consumed_messages;
+DROP TABLE IF EXISTS consumer_offsets;
+-- +goose StatementEnd
@@ -0,0 +1,73 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"order-service/internal/domain"
+	"order-service/internal/infra/repo"
+	"order-service/internal/infra/repo/postgres"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConsumedOffsets(t *testing.T) {
+	ctx := context.Background()
+	db := setupTestDB(t, ctx)
+	defer teardownTestDB(t, db)
+	pg := postgres.NewPostgresDB(db)
+
+	newOrder := func(uid string) *domain.Order {
+		order, err := domain.NewOrder(domain.OrderParams{
+			OrderUID:    uid,
+			TrackNumber: "WBILMTESTTRACK",
+			Entry:       "WBIL",
+			Delivery: domain.DeliveryParams{
+				Name: "Test Testov", Phone: "+9720000000", Zip: "2639809", City: "Kiryat Mozkin",
+				Address: "Ploshad Mira 15", Region: "Kraiot", Email: "test@gmail.com",
+			},
+			Payment: domain.PaymentParams{
+				Transaction: uid, Currency: "USD", Provider: "wbpay", Amount: 1817,
+				PaymentDt: 1637907727, Bank: "alpha", DeliveryCost: 1500, GoodsTotal: 317,
+			},
+			Items: []domain.ItemParams{
+				{ChrtID: 9934930, TrackNumber: "WBILMTESTTRACK", Price: 453, Name: "Mascaras", Sale: 30, TotalPrice: 317, Status: 202},
+			},
+			DateCreated: time.Date(2021, 11, 26, 6, 22, 19, 0, time.UTC),
+		})
+		require.NoError(t, err)
+		return order
+	}
+	at := func(offset int64) context.Context {
+		return repo.WithConsumedOffset(ctx, repo.ConsumedOffset{Group: "orders-group", Topic: "orders", Partition: 0, Offset: offset})
+	}
+
+	require.NoError(t, pg.SaveOrder(at(5), newOrder("offset-5")))
+	// the message is delivered again before its offset was committed
+	assert.ErrorIs(t, pg.SaveOrder(at(5), newOrder("offset-5-again")), repo.ErrAlreadyConsumed)
+	_, err := pg.GetOrderByUid(ctx, "offset-5-again")
+	assert.ErrorIs(t, err, repo.ErrNotFound, "the order of a consumed message must be rolled back")
+
+	next, err := pg.NextOffset(ctx, "orders-group", "orders", 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), next)
+
+	require.NoError(t, pg.CommitOffsets(ctx, []repo.ConsumedOffset{{Group: "orders-group", Topic: "orders", Partition: 0, Offset: 7}}))
+	next, err = pg.NextOffset(ctx, "orders-group", "orders", 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(8), next)
+
+	// a lower offset committed late does not move the partition back
+	require.NoError(t, pg.CommitOffsets(ctx, []repo.ConsumedOffset{{Group: "orders-group", Topic: "orders", Partition: 0, Offset: 3}}))
+	next, err = pg.NextOffset(ctx, "orders-group", "orders", 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(8), next)
+
+	assert.ErrorIs(t, pg.SaveOrder(at(6), newOrder("offset-6")), repo.ErrAlreadyConsumed)
+	require.NoError(t, pg.SaveOrder(at(8), newOrder("offset-8")))
+	// orders written outside the consumer record no offset
+	require.NoError(t, pg.SaveOrder(ctx, newOrder("http")))
+}
@@ -0,0 +1,82 @@
+//go:build integration
+
+package integration
+
+import (
+	"bytes"
+	"context"
+	"order-service/internal/domain"
+	"order-service/internal/infra/cache"
+	"order-service/internal/infra/repo/postgres"
+	"order-service/internal/usecase"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRestoreCacheSnapshot(t *testing.T) {
+	ctx := context.Background()
+	db := setupTestDB(t, ctx)
+	defer teardownTestDB(t, db)
+	pg := postgres.NewPostgresDB(db)
+
+	params := func(uid string) domain.OrderParams {
+		return domain.OrderParams{
+			OrderUID:    uid,
+			TrackNumber: "WBILMTESTTRACK",
+			Entry:       "WBIL",
+			Delivery: domain.DeliveryParams{
+				Name: "Test Testov", Phone: "+9720000000", Zip: "2639809", City: "Kiryat Mozkin",
+				Address: "Ploshad Mira 15", Region: "Kraiot", Email: "test@gmail.com",
+			},
+			Payment: domain.PaymentParams{
+				Transaction: uid, Currency: "USD", Provider: "wbpay", Amount: 1817,
+				PaymentDt: 1637907727, Bank: "alpha", DeliveryCost: 1500, GoodsTotal: 317,
+			},
+			Items: []domain.ItemParams{
+				{ChrtID: 9934930, TrackNumber: "WBILMTESTTRACK", Price: 453, Name: "Mascaras", Sale: 30, TotalPrice: 317, Status: 202},
+			},
+			DateCreated: time.Date(2021, 11, 26, 6, 22, 19, 0, time.UTC),
+		}
+	}
+
+	lru, err := cache.NewLRUCache(10)
+	require.NoError(t, err)
+	uc := usecase.NewOrderUseCase(pg, lru)
+	require.NoError(t, uc.CreateOrder(ctx, params("snapshot-1")))
+	require.NoError(t, uc.CreateOrder(ctx, params("snapshot-2")))
+
+	var buf bytes.Buffer
+	require.NoError(t, cache.WriteSnapshot(&buf, lru.Snapshot(true)))
+	snap, err := cache.ReadSnapshot(&buf)
+	require.NoError(t, err)
+
+	versions, err := pg.GetOrderVersions(ctx, []string{"snapshot-1", "snapshot-2", "missing"})
+	require.NoError(t, err)
+	require.Len(t, versions, 2)
+	assert.True(t, versions["snapshot-1"].Equal(snap.Entries[0].Order.DateUpdated), "a snapshot order is current until it changes")
+
+	// changes after the snapshot must not be restored from it
+	changed := params("snapshot-2")
+	changed.TrackNumber = "WBILMNEWTRACK"
+	_, err = uc.UpdateOrder(ctx, changed)
+	require.NoError(t, err)
+
+	entries := []usecase.CacheEntry{{OrderUID: "missing"}}
+	for _, e := range snap.Entries {
+		entries = append(entries, usecase.CacheEntry{OrderUID: e.OrderUID, Order: e.Order})
+	}
+	restoredCache, err := cache.NewLRUCache(10)
+	require.NoError(t, err)
+	restored, err := usecase.NewOrderUseCase(pg, restoredCache).RestoreOrdersCache(ctx, entries, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 2, restored)
+
+	order, ok := restoredCache.Get("snapshot-2")
+	require.True(t, ok)
+	assert.Equal(t, "WBILMNEWTRACK", order.TrackNumber)
+	assert.Len(t, order.Items, 1)
+	assert.NotEmpty(t, order.StatusHistory)
+}
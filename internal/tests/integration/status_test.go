@@ -0,0 +1,56 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"order-service/internal/domain"
+	"order-service/internal/infra/repo/postgres"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateOrderStatusConcurrently(t *testing.T) {
+	ctx := context.Background()
+	db := setupTestDB(t, ctx)
+	defer teardownTestDB(t, db)
+	pg := postgres.NewPostgresDB(db)
+
+	order, err := domain.NewOrder(domain.OrderParams{
+		OrderUID:    "status-order-uid",
+		TrackNumber: "WBILMTESTTRACK",
+		Entry:       "WBIL",
+		Delivery: domain.DeliveryParams{
+			Name: "Test Testov", Phone: "+9720000000", Zip: "2639809", City: "Kiryat Mozkin",
+			Address: "Ploshad Mira 15", Region: "Kraiot", Email: "test@gmail.com",
+		},
+		Payment: domain.PaymentParams{
+			Transaction: "status-order-uid", Currency: "USD", Provider: "wbpay", Amount: 1817,
+			PaymentDt: 1637907727, Bank: "alpha", DeliveryCost: 1500, GoodsTotal: 317,
+		},
+		Items: []domain.ItemParams{
+			{ChrtID: 9934930, TrackNumber: "WBILMTESTTRACK", Price: 453, Name: "Mascaras", Sale: 30, TotalPrice: 317, Status: 202},
+		},
+		DateCreated: time.Date(2021, 11, 26, 6, 22, 19, 0, time.UTC),
+	})
+	require.NoError(t, err)
+	require.NoError(t, pg.SaveOrder(ctx, order))
+
+	// both transitions start from the same stored status
+	paid := order.Clone()
+	require.NoError(t, paid.Pay(time.Now().UTC()))
+	cancelled := order.Clone()
+	require.NoError(t, cancelled.Cancel(time.Now().UTC()))
+
+	require.NoError(t, pg.UpdateOrderStatus(ctx, paid))
+	assert.ErrorIs(t, pg.UpdateOrderStatus(ctx, cancelled), domain.ErrInvalidState)
+
+	stored, err := pg.GetOrderByUid(ctx, order.OrderUID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.StatusPaid, stored.Status)
+	assert.Len(t, stored.StatusHistory, 2)
+	assert.True(t, stored.DateUpdated.Equal(paid.DateUpdated), "the returned order must carry the stored update time")
+}
@@ -0,0 +1,94 @@
+package usecase
+
+import (
+	"sync"
+	"time"
+)
+
+// maxNotFound bounds the uids remembered as missing, so lookups of random
+// uids cannot grow the set without limit.
+const maxNotFound = 10000
+
+// notFoundCache remembers for a short time the uids the repository did not
+// find.
+type notFoundCache struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu   sync.Mutex
+	uids map[string]time.Time
+	// gen changes on every forget. A lookup started before a forget may have
+	// missed the stored order and must not be remembered.
+	gen uint64
+}
+
+func newNotFoundCache(ttl time.Duration) *notFoundCache {
+	return &notFoundCache{ttl: ttl, now: time.Now, uids: make(map[string]time.Time)}
+}
+
+func (n *notFoundCache) enabled() bool {
+	return n.ttl > 0
+}
+
+func (n *notFoundCache) has(uid string) bool {
+	if !n.enabled() {
+		return false
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	expiresAt, ok := n.uids[uid]
+	if !ok {
+		return false
+	}
+	if !n.now().Before(expiresAt) {
+		delete(n.uids, uid)
+		return false
+	}
+	return true
+}
+
+func (n *notFoundCache) generation() uint64 {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.gen
+}
+
+// add remembers uid unless an order was stored since generation gen.
+func (n *notFoundCache) add(uid string, gen uint64) {
+	if !n.enabled() {
+		return
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if gen != n.gen {
+		return
+	}
+	now := n.now()
+	if len(n.uids) >= maxNotFound {
+		for k, expiresAt := range n.uids {
+			if !now.Before(expiresAt) {
+				delete(n.uids, k)
+			}
+		}
+		if len(n.uids) >= maxNotFound {
+			return
+		}
+	}
+	n.uids[uid] = now.Add(n.ttl)
+}
+
+func (n *notFoundCache) forget(uid string) {
+	if !n.enabled() {
+		return
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	delete(n.uids, uid)
+	n.gen++
+}
@@ -6,39 +6,193 @@ import (
 	"fmt"
 	"order-service/internal/domain"
 	"order-service/internal/infra/repo"
+	"slices"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+var (
+	ErrIdempotencyKeyExists = errors.New("idempotency key already exists")
+	// ErrDuplicateOrder means the order is already stored with the same content,
+	// so the message is a replay and can be acknowledged.
+	ErrDuplicateOrder = fmt.Errorf("duplicate order: %w", ErrIdempotencyKeyExists)
+	// ErrOrderConflict means the order uid is taken by an order with different
+	// content. The error is returned as a *ConflictError.
+	ErrOrderConflict = fmt.Errorf("conflicting order: %w", ErrIdempotencyKeyExists)
 )
 
-var ErrIdempotencyKeyExists = errors.New("idempotency key already exists")
+// ConflictError lists the fields in which an incoming order differs from the
+// stored one with the same uid.
+type ConflictError struct {
+	OrderUID string
+	Diff     []domain.FieldDiff
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("order_uid %s: %s", e.OrderUID, ErrOrderConflict)
+}
+
+func (e *ConflictError) Unwrap() error {
+	return ErrOrderConflict
+}
+
+func AsConflictError(err error) (*ConflictError, bool) {
+	var cErr *ConflictError
+	if errors.As(err, &cErr) {
+		return cErr, true
+	}
+	return nil, false
+}
+
+type Option func(*OrderUseCase)
+
+// WithOrderOptions sets the options orders are validated with.
+func WithOrderOptions(opts ...domain.OrderOption) Option {
+	return func(c *OrderUseCase) {
+		c.orderOpts = opts
+	}
+}
+
+// WithNotFoundTTL makes GetOrder remember for ttl that an order does not
+// exist, so repeated lookups of a missing uid do not reach the repository.
+// Storing the order forgets it at once.
+func WithNotFoundTTL(ttl time.Duration) Option {
+	return func(c *OrderUseCase) {
+		c.notFound = newNotFoundCache(ttl)
+	}
+}
 
 type OrderUseCase struct {
 	repository OrderRepository
 	cache      Cache
+	orderOpts  []domain.OrderOption
+	notFound   *notFoundCache
+	// lookups coalesces concurrent repository reads of the same uid.
+	lookups singleflight.Group
 }
 
-func NewOrderUseCase(repository OrderRepository, cache Cache) *OrderUseCase {
-	return &OrderUseCase{
+func NewOrderUseCase(repository OrderRepository, cache Cache, opts ...Option) *OrderUseCase {
+	c := &OrderUseCase{
 		repository: repository,
 		cache:      cache,
+		notFound:   newNotFoundCache(0),
+	}
+	for _, opt := range opts {
+		opt(c)
 	}
+	return c
 }
 
 func (c *OrderUseCase) CreateOrder(ctx context.Context, params domain.OrderParams) error {
-	if err := c.checkIdempotency(params.OrderUID); err != nil {
-		return err
-	}
+	_, err := c.createOrder(ctx, params)
+	return err
+}
 
-	order, err := domain.NewOrder(params)
+// ValidateOrder runs the validation of CreateOrder without storing the order.
+func (c *OrderUseCase) ValidateOrder(params domain.OrderParams) error {
+	_, err := domain.NewOrder(params, c.orderOpts...)
+	return err
+}
+
+func (c *OrderUseCase) createOrder(ctx context.Context, params domain.OrderParams) (*domain.Order, error) {
+	order, err := domain.NewOrder(params, c.orderOpts...)
 	if err != nil {
-		return err
+		return nil, err
 	}
+
 	if err = c.repository.SaveOrder(ctx, order); err != nil {
-		return err
+		if errors.Is(err, repo.ErrAlreadyExists) {
+			return nil, c.checkDuplicate(ctx, order)
+		}
+		return nil, err
 	}
 
+	c.stored(order)
+
+	return order, nil
+}
+
+// CreateOrders creates a batch of orders in one repository call. It returns
+// one error per params, nil for created orders. Invalid orders and
+// duplicates are rejected individually; when the batch cannot be stored,
+// every valid order gets the storage error.
+func (c *OrderUseCase) CreateOrders(ctx context.Context, params []domain.OrderParams) []error {
+	errs := make([]error, len(params))
+
+	orders := make([]*domain.Order, 0, len(params))
+	indexes := make([]int, 0, len(params))
+	for i, p := range params {
+		order, err := domain.NewOrder(p, c.orderOpts...)
+		if err != nil {
+			errs[i] = err
+			continue
+		}
+		orders = append(orders, order)
+		indexes = append(indexes, i)
+	}
+	if len(orders) == 0 {
+		return errs
+	}
+
+	saveErrs, err := c.repository.SaveOrders(ctx, orders)
+	for j, order := range orders {
+		i := indexes[j]
+		switch {
+		case err != nil:
+			errs[i] = err
+		case errors.Is(saveErrs[j], repo.ErrAlreadyExists):
+			errs[i] = c.checkDuplicate(ctx, order)
+		case saveErrs[j] != nil:
+			errs[i] = saveErrs[j]
+		default:
+			c.stored(order)
+		}
+	}
+
+	return errs
+}
+
+// UpdateOrder replaces an existing order's data, delivery, payment and items.
+// The creation date and status history are kept from the stored order.
+func (c *OrderUseCase) UpdateOrder(ctx context.Context, params domain.OrderParams) (*domain.Order, error) {
+	order, err := domain.NewOrder(params, c.orderOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := c.GetOrder(ctx, order.OrderUID)
+	if err != nil {
+		return nil, err
+	}
+	order.DateCreated = current.DateCreated
+	order.Status = current.Status
+	order.StatusHistory = slices.Clone(current.StatusHistory)
+
+	if err := c.repository.UpdateOrder(ctx, order); err != nil {
+		return nil, err
+	}
 	c.cache.Set(order)
 
-	return nil
+	return order, nil
+}
+
+// UpsertOrder updates the order if it exists and creates it otherwise. The
+// returned flag reports whether the order was created.
+func (c *OrderUseCase) UpsertOrder(ctx context.Context, params domain.OrderParams) (*domain.Order, bool, error) {
+	order, err := c.UpdateOrder(ctx, params)
+	if err == nil {
+		return order, false, nil
+	}
+	if !errors.Is(err, repo.ErrNotFound) {
+		return nil, false, err
+	}
 
+	order, err = c.createOrder(ctx, params)
+	if err != nil {
+		return nil, false, err
+	}
+	return order, true, nil
 }
 
 func (c *OrderUseCase) GetOrder(ctx context.Context, uid string) (*domain.Order, error) {
@@ -50,11 +204,40 @@ func (c *OrderUseCase) GetOrder(ctx context.Context, uid string) (*domain.Order,
 	if ok {
 		return order, nil
 	}
+	if c.notFound.has(uid) {
+		return nil, fmt.Errorf("order_uid %s: %w", uid, repo.ErrNotFound)
+	}
+
+	select {
+	case res := <-c.lookups.DoChan(uid, func() (any, error) {
+		// shared by the callers, so not canceled with the first one
+		return c.lookup(context.WithoutCancel(ctx), uid)
+	}):
+		if res.Err != nil {
+			if errors.Is(res.Err, repo.ErrNotFound) {
+				return nil, fmt.Errorf("order_uid %s: %w", uid, res.Err)
+			}
+			return nil, res.Err
+		}
+		order := res.Val.(*domain.Order)
+		if res.Shared {
+			// every caller may change the order it got
+			order = order.Clone()
+		}
+		return order, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// lookup reads the order from the repository into the cache.
+func (c *OrderUseCase) lookup(ctx context.Context, uid string) (*domain.Order, error) {
+	gen := c.notFound.generation()
 
 	order, err := c.repository.GetOrderByUid(ctx, uid)
 	if err != nil {
 		if errors.Is(err, repo.ErrNotFound) {
-			return nil, fmt.Errorf("order_uid %s: %w", uid, err)
+			c.notFound.add(uid, gen)
 		}
 		return nil, err
 	}
@@ -63,6 +246,54 @@ func (c *OrderUseCase) GetOrder(ctx context.Context, uid string) (*domain.Order,
 	return order, nil
 }
 
+// stored caches a newly stored order. A lookup that missed it while it was
+// being stored is not joined or remembered.
+func (c *OrderUseCase) stored(order *domain.Order) {
+	c.notFound.forget(order.OrderUID)
+	c.lookups.Forget(order.OrderUID)
+	c.cache.Set(order)
+}
+
+// SearchOrders returns one page of orders matching the filter. Orders are
+// read from the repository, not the cache, so the page is consistent.
+func (c *OrderUseCase) SearchOrders(ctx context.Context, filter domain.OrderFilter) (*domain.OrderPage, error) {
+	limit := filter.Limit
+	filter.Limit++
+
+	orders, err := c.repository.SearchOrders(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &domain.OrderPage{Orders: orders}
+	if len(orders) > limit {
+		page.Orders = orders[:limit]
+		last := page.Orders[limit-1]
+		page.NextCursor = domain.OrderCursor{DateCreated: last.DateCreated, Id: last.Id}.String()
+	}
+	return page, nil
+}
+
+func (c *OrderUseCase) ChangeOrderStatus(ctx context.Context, uid string, status domain.Status) (*domain.Order, error) {
+	current, err := c.GetOrder(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+
+	order := *current
+	order.StatusHistory = slices.Clone(current.StatusHistory)
+	if err := order.TransitionTo(status, time.Now().UTC()); err != nil {
+		return nil, err
+	}
+
+	if err := c.repository.UpdateOrderStatus(ctx, &order); err != nil {
+		return nil, err
+	}
+	c.cache.Set(&order)
+
+	return &order, nil
+}
+
 func (c *OrderUseCase) LoadOrdersCache(ctx context.Context, limit int) error {
 	orders, err := c.repository.GetLastOrders(ctx, limit)
 	if err != nil {
@@ -76,18 +307,99 @@ func (c *OrderUseCase) LoadOrdersCache(ctx context.Context, limit int) error {
 
 }
 
-func (c *OrderUseCase) checkIdempotency(uid string) error {
-	if uid == "" {
-		return fmt.Errorf("uid is empty %w", domain.ErrInvalidState)
+// CacheEntry is an order of a cache snapshot. Order is nil when the snapshot
+// keeps only the uids.
+type CacheEntry struct {
+	OrderUID string
+	Order    *domain.Order
+}
+
+// RestoreOrdersCache fills the cache from the last limit entries of a
+// snapshot, listed from the least to the most recently used. A snapshot order
+// is cached as it is while its update time matches the stored one, other
+// orders are read again and the ones no longer stored are skipped. It returns
+// the number of orders cached.
+func (c *OrderUseCase) RestoreOrdersCache(ctx context.Context, entries []CacheEntry, limit int) (int, error) {
+	if len(entries) > limit {
+		entries = entries[len(entries)-limit:]
+	}
+	if len(entries) == 0 {
+		return 0, nil
 	}
 
-	exists, err := c.repository.CheckIdempotencyKey(context.Background(), uid)
+	uids := make([]string, 0, len(entries))
+	for _, e := range entries {
+		uids = append(uids, e.OrderUID)
+	}
+	versions, err := c.repository.GetOrderVersions(ctx, uids)
 	if err != nil {
-		return fmt.Errorf("idempotnecy check failed %w", err)
+		return 0, err
 	}
-	if exists {
-		return ErrIdempotencyKeyExists
+
+	orders := make(map[string]*domain.Order, len(entries))
+	var stale []string
+	for _, e := range entries {
+		updated, ok := versions[e.OrderUID]
+		switch {
+		case !ok:
+		case e.Order != nil && e.Order.DateUpdated.Equal(updated):
+			orders[e.OrderUID] = e.Order
+		default:
+			stale = append(stale, e.OrderUID)
+		}
+	}
+	if len(stale) > 0 {
+		loaded, err := c.repository.GetOrdersByUids(ctx, stale)
+		if err != nil {
+			return 0, err
+		}
+		for _, order := range loaded {
+			orders[order.OrderUID] = order
+		}
 	}
 
-	return nil
+	// the most recently used orders go last, so they are evicted last
+	restored := 0
+	for _, e := range entries {
+		if order, ok := orders[e.OrderUID]; ok {
+			c.cache.Set(order)
+			delete(orders, e.OrderUID)
+			restored++
+		}
+	}
+	return restored, nil
+}
+
+// checkDuplicate tells a replay of a stored order from a different order
+// reusing its uid. It is called after the database rejected the insert, so
+// concurrent consumers cannot both pass it.
+func (c *OrderUseCase) checkDuplicate(ctx context.Context, order *domain.Order) error {
+	storedHash, err := c.repository.GetContentHash(ctx, order.OrderUID)
+	if err != nil {
+		return fmt.Errorf("idempotency check failed: %w", err)
+	}
+
+	if storedHash == order.ContentHash() {
+		return ErrDuplicateOrder
+	}
+	return c.conflict(ctx, order, storedHash)
+}
+
+// conflict compares the incoming order with the stored one. Orders saved
+// without a hash are hashed on the fly, so their replays still count as
+// duplicates. The stored order is read past the cache, which may still
+// remember it as missing or hold an older version.
+func (c *OrderUseCase) conflict(ctx context.Context, order *domain.Order, storedHash string) error {
+	stored, err := c.repository.GetOrderByUid(ctx, order.OrderUID)
+	if err != nil {
+		return fmt.Errorf("idempotency check failed: %w", err)
+	}
+	if storedHash == "" && stored.ContentHash() == order.ContentHash() {
+		return ErrDuplicateOrder
+	}
+
+	return &ConflictError{
+		OrderUID: order.OrderUID,
+		Diff:     domain.DiffParams(stored.Params(), order.Params()),
+	}
 }
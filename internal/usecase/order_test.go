@@ -5,6 +5,8 @@ import (
 	"errors"
 	"fmt"
 	"order-service/internal/domain"
+	"order-service/internal/infra/repo"
+	"sync"
 	"testing"
 	"time"
 
@@ -28,22 +30,23 @@ var expectedOrder = &domain.Order{
 		Transaction:  "b563feb7b2b84b6test",
 		Currency:     "USD",
 		Provider:     "wbpay",
-		Amount:       1817,
+		Amount:       domain.NewMoney(1817, "USD"),
 		PaymentDt:    1637907727,
 		Bank:         "alpha",
-		DeliveryCost: 1500,
-		GoodsTotal:   317,
+		DeliveryCost: domain.NewMoney(1500, "USD"),
+		GoodsTotal:   domain.NewMoney(317, "USD"),
+		CustomFee:    domain.NewMoney(0, "USD"),
 	},
 	Items: []*domain.Item{
 		{
 			ChrtID:      9934930,
 			TrackNumber: "WBILMTESTTRACK",
-			Price:       453,
+			Price:       domain.NewMoney(453, "USD"),
 			Rid:         "ab4219087a764ae0btest",
 			Name:        "Mascaras",
 			Sale:        30,
 			Size:        "0",
-			TotalPrice:  317,
+			TotalPrice:  domain.NewMoney(317, "USD"),
 			NmID:        2389212,
 			Brand:       "Vivienne Sabo",
 			Status:      202,
@@ -59,34 +62,80 @@ var expectedOrder = &domain.Order{
 }
 
 type MockOrderRepo struct {
+	mu         sync.Mutex
 	validOrder domain.Order
 	saveErr    error
 	getErr     error
 	idempErr   error
+	updateErr  error
+	hashes     map[string]string
 	called     bool
+	gets       int
+	updated    *domain.Order
+	filter     domain.OrderFilter
+	// getBlock, when set, holds GetOrderByUid until it is closed
+	getBlock chan struct{}
+	// stored and versions back GetOrdersByUids and GetOrderVersions
+	stored   map[string]*domain.Order
+	versions map[string]time.Time
+	loaded   []string
 }
 
+// SaveOrder enforces order uid uniqueness the way the database does, using
+// hashes as the table of stored orders.
 func (m *MockOrderRepo) SaveOrder(ctx context.Context, order *domain.Order) error {
-	return m.saveErr
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.saveErr != nil {
+		return m.saveErr
+	}
+	if _, ok := m.hashes[order.OrderUID]; ok {
+		return repo.ErrAlreadyExists
+	}
+	if m.hashes == nil {
+		m.hashes = make(map[string]string)
+	}
+	m.hashes[order.OrderUID] = order.ContentHash()
+	return nil
+}
+
+func (m *MockOrderRepo) SaveOrders(ctx context.Context, orders []*domain.Order) ([]error, error) {
+	if m.saveErr != nil {
+		return nil, m.saveErr
+	}
+	errs := make([]error, len(orders))
+	for i, order := range orders {
+		errs[i] = m.SaveOrder(ctx, order)
+	}
+	return errs, nil
 }
 
 func (m *MockOrderRepo) GetOrderByUid(ctx context.Context, uid string) (*domain.Order, error) {
+	m.mu.Lock()
 	m.called = true
+	m.gets++
+	m.mu.Unlock()
+	if m.getBlock != nil {
+		<-m.getBlock
+	}
 	if m.getErr != nil {
 		return nil, m.getErr
 	}
 	return &m.validOrder, nil
 }
 
-func (m *MockOrderRepo) CheckIdempotencyKey(ctx context.Context, key string) (bool, error) {
+func (m *MockOrderRepo) GetContentHash(ctx context.Context, uid string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	m.called = true
 	if m.idempErr != nil {
-		return false, m.idempErr
+		return "", m.idempErr
 	}
-	if key == m.validOrder.OrderUID {
-		return false, nil
+	hash, ok := m.hashes[uid]
+	if !ok {
+		return "", repo.ErrNotFound
 	}
-	return true, nil
+	return hash, nil
 }
 
 func (m *MockOrderRepo) GetLastOrders(ctx context.Context, limit int) ([]*domain.Order, error) {
@@ -100,7 +149,66 @@ func (m *MockOrderRepo) GetLastOrders(ctx context.Context, limit int) ([]*domain
 	return orders, nil
 }
 
+func (m *MockOrderRepo) GetOrdersByUids(ctx context.Context, uids []string) ([]*domain.Order, error) {
+	m.loaded = append(m.loaded, uids...)
+	if m.getErr != nil {
+		return nil, m.getErr
+	}
+	var orders []*domain.Order
+	for _, uid := range uids {
+		if order, ok := m.stored[uid]; ok {
+			orders = append(orders, order)
+		}
+	}
+	return orders, nil
+}
+
+func (m *MockOrderRepo) GetOrderVersions(ctx context.Context, uids []string) (map[string]time.Time, error) {
+	versions := make(map[string]time.Time)
+	for _, uid := range uids {
+		if v, ok := m.versions[uid]; ok {
+			versions[uid] = v
+		}
+	}
+	return versions, nil
+}
+
+func (m *MockOrderRepo) UpdateOrder(ctx context.Context, order *domain.Order) error {
+	m.called = true
+	if m.updateErr != nil {
+		return m.updateErr
+	}
+	m.updated = order
+	return nil
+}
+
+func (m *MockOrderRepo) UpdateOrderStatus(ctx context.Context, order *domain.Order) error {
+	m.called = true
+	if m.updateErr != nil {
+		return m.updateErr
+	}
+	m.updated = order
+	return nil
+}
+
+func (m *MockOrderRepo) SearchOrders(ctx context.Context, filter domain.OrderFilter) ([]*domain.Order, error) {
+	m.called = true
+	m.filter = filter
+	if m.getErr != nil {
+		return nil, m.getErr
+	}
+	orders, _ := m.GetLastOrders(ctx, 3)
+	for i, order := range orders {
+		order.Id = i + 1
+	}
+	if len(orders) > filter.Limit {
+		orders = orders[:filter.Limit]
+	}
+	return orders, nil
+}
+
 type MockCache struct {
+	mu     sync.Mutex
 	cache  map[string]*domain.Order
 	called bool
 }
@@ -108,12 +216,16 @@ type MockCache struct {
 func NewMockCache() *MockCache { return &MockCache{cache: make(map[string]*domain.Order)} }
 
 func (mc *MockCache) Get(uid string) (*domain.Order, bool) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
 	order, ok := mc.cache[uid]
 	mc.called = true
 	return order, ok
 }
 
 func (mc *MockCache) Set(order *domain.Order) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
 	mc.called = true
 	mc.cache[order.OrderUID] = order
 }
@@ -142,24 +254,24 @@ func TestOrderUseCase_CreateOrder(t *testing.T) {
 		},
 		Payment: domain.PaymentParams{
 			Transaction:  expectedOrder.Payment.Transaction,
-			Currency:     expectedOrder.Payment.Currency,
+			Currency:     expectedOrder.Payment.Currency.String(),
 			Provider:     expectedOrder.Payment.Provider,
-			Amount:       expectedOrder.Payment.Amount,
+			Amount:       int(expectedOrder.Payment.Amount.Amount()),
 			PaymentDt:    expectedOrder.Payment.PaymentDt,
 			Bank:         expectedOrder.Payment.Bank,
-			DeliveryCost: expectedOrder.Payment.DeliveryCost,
-			GoodsTotal:   expectedOrder.Payment.GoodsTotal,
+			DeliveryCost: int(expectedOrder.Payment.DeliveryCost.Amount()),
+			GoodsTotal:   int(expectedOrder.Payment.GoodsTotal.Amount()),
 		},
 		Items: []domain.ItemParams{
 			{
 				ChrtID:      expectedOrder.Items[0].ChrtID,
 				TrackNumber: expectedOrder.Items[0].TrackNumber,
-				Price:       expectedOrder.Items[0].Price,
+				Price:       int(expectedOrder.Items[0].Price.Amount()),
 				Rid:         expectedOrder.Items[0].Rid,
 				Name:        expectedOrder.Items[0].Name,
 				Sale:        expectedOrder.Items[0].Sale,
 				Size:        expectedOrder.Items[0].Size,
-				TotalPrice:  expectedOrder.Items[0].TotalPrice,
+				TotalPrice:  int(expectedOrder.Items[0].TotalPrice.Amount()),
 				NmID:        expectedOrder.Items[0].NmID,
 				Brand:       expectedOrder.Items[0].Brand,
 				Status:      expectedOrder.Items[0].Status,
@@ -183,6 +295,166 @@ func TestOrderUseCase_CreateOrder(t *testing.T) {
 	})
 }
 
+func TestOrderUseCase_CreateOrderDuplicates(t *testing.T) {
+	ctx := context.Background()
+	stored := *expectedOrder
+
+	t.Run("exact replay", func(t *testing.T) {
+		mockRepo := &MockOrderRepo{validOrder: stored, hashes: map[string]string{stored.OrderUID: stored.ContentHash()}}
+		uc, _ := setupUseCase(mockRepo)
+
+		err := uc.CreateOrder(ctx, stored.Params())
+		assert.ErrorIs(t, err, ErrDuplicateOrder)
+		assert.ErrorIs(t, err, ErrIdempotencyKeyExists)
+	})
+
+	t.Run("replay of order saved without hash", func(t *testing.T) {
+		mockRepo := &MockOrderRepo{validOrder: stored, hashes: map[string]string{stored.OrderUID: ""}}
+		uc, _ := setupUseCase(mockRepo)
+
+		err := uc.CreateOrder(ctx, stored.Params())
+		assert.ErrorIs(t, err, ErrDuplicateOrder)
+	})
+
+	t.Run("conflicting payload", func(t *testing.T) {
+		mockRepo := &MockOrderRepo{validOrder: stored, hashes: map[string]string{stored.OrderUID: stored.ContentHash()}}
+		uc, _ := setupUseCase(mockRepo)
+
+		params := stored.Params()
+		params.TrackNumber = "WBILMNEWTRACK"
+
+		err := uc.CreateOrder(ctx, params)
+		assert.ErrorIs(t, err, ErrOrderConflict)
+		assert.NotErrorIs(t, err, ErrDuplicateOrder)
+
+		cErr, ok := AsConflictError(err)
+		assert.True(t, ok)
+		assert.Equal(t, []domain.FieldDiff{
+			{Field: "track_number", Stored: "WBILMTESTTRACK", Incoming: "WBILMNEWTRACK"},
+		}, cErr.Diff)
+	})
+
+	t.Run("conflict with an order remembered as missing", func(t *testing.T) {
+		// stored concurrently by another instance after the miss was cached
+		mockRepo := &MockOrderRepo{validOrder: stored, hashes: map[string]string{stored.OrderUID: stored.ContentHash()}}
+		uc := NewOrderUseCase(mockRepo, NewMockCache(), WithNotFoundTTL(time.Minute))
+		uc.notFound.add(stored.OrderUID, uc.notFound.generation())
+
+		params := stored.Params()
+		params.TrackNumber = "WBILMNEWTRACK"
+
+		err := uc.CreateOrder(ctx, params)
+		assert.ErrorIs(t, err, ErrOrderConflict)
+		assert.NotErrorIs(t, err, repo.ErrNotFound)
+	})
+
+	t.Run("stored order lookup fails", func(t *testing.T) {
+		mockRepo := &MockOrderRepo{getErr: errors.New("repo error"), hashes: map[string]string{stored.OrderUID: stored.ContentHash()}}
+		uc, _ := setupUseCase(mockRepo)
+
+		params := stored.Params()
+		params.TrackNumber = "WBILMNEWTRACK"
+
+		err := uc.CreateOrder(ctx, params)
+		assert.Error(t, err)
+		assert.NotErrorIs(t, err, ErrIdempotencyKeyExists)
+	})
+}
+
+func TestOrderUseCase_ValidateOrder(t *testing.T) {
+	mockRepo := &MockOrderRepo{}
+	uc, cache := setupUseCase(mockRepo)
+
+	assert.NoError(t, uc.ValidateOrder(expectedOrder.Params()))
+
+	params := expectedOrder.Params()
+	params.Items = nil
+	assert.Error(t, uc.ValidateOrder(params))
+
+	_, ok := cache.Get(expectedOrder.OrderUID)
+	assert.False(t, ok, "validation must not store the order")
+}
+
+func TestOrderUseCase_CreateOrders(t *testing.T) {
+	ctx := context.Background()
+
+	valid := expectedOrder.Params()
+	other := expectedOrder.Params()
+	other.OrderUID = "other-order-uid"
+	invalid := expectedOrder.Params()
+	invalid.OrderUID = "invalid-order-uid"
+	invalid.Items = nil
+	conflicting := expectedOrder.Params()
+	conflicting.OrderUID = "stored-order-uid"
+	conflicting.TrackNumber = "WBILMNEWTRACK"
+
+	stored := *expectedOrder
+	stored.OrderUID = "stored-order-uid"
+
+	t.Run("orders are rejected individually", func(t *testing.T) {
+		mockRepo := &MockOrderRepo{validOrder: stored, hashes: map[string]string{stored.OrderUID: stored.ContentHash()}}
+		uc, cache := setupUseCase(mockRepo)
+
+		errs := uc.CreateOrders(ctx, []domain.OrderParams{valid, invalid, valid, conflicting, other})
+		assert.Len(t, errs, 5)
+		assert.NoError(t, errs[0])
+		assert.ErrorIs(t, errs[1], domain.ErrInvalidState)
+		assert.ErrorIs(t, errs[2], ErrDuplicateOrder)
+		assert.ErrorIs(t, errs[3], ErrOrderConflict)
+		assert.NoError(t, errs[4])
+
+		_, ok := cache.Get(valid.OrderUID)
+		assert.True(t, ok)
+		_, ok = cache.Get(other.OrderUID)
+		assert.True(t, ok)
+	})
+
+	t.Run("failed batch", func(t *testing.T) {
+		mockRepo := &MockOrderRepo{saveErr: errors.New("repo error")}
+		uc, cache := setupUseCase(mockRepo)
+
+		errs := uc.CreateOrders(ctx, []domain.OrderParams{valid, invalid})
+		assert.EqualError(t, errs[0], "repo error")
+		assert.ErrorIs(t, errs[1], domain.ErrInvalidState)
+
+		_, ok := cache.Get(valid.OrderUID)
+		assert.False(t, ok)
+	})
+}
+
+func TestOrderUseCase_CreateOrderConcurrently(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := &MockOrderRepo{}
+	uc, _ := setupUseCase(mockRepo)
+
+	const workers = 20
+	errs := make(chan error, workers)
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			errs <- uc.CreateOrder(ctx, expectedOrder.Params())
+		}()
+	}
+	close(start)
+	wg.Wait()
+	close(errs)
+
+	created := 0
+	for err := range errs {
+		if err == nil {
+			created++
+			continue
+		}
+		assert.ErrorIs(t, err, ErrDuplicateOrder)
+	}
+	assert.Equal(t, 1, created)
+	assert.Len(t, mockRepo.hashes, 1)
+}
+
 func TestOrderUseCase_GetOrder(t *testing.T) {
 	repo := &MockOrderRepo{validOrder: *expectedOrder}
 	uc, cache := setupUseCase(repo)
@@ -229,6 +501,85 @@ func TestOrderUseCase_GetOrder(t *testing.T) {
 	})
 }
 
+func TestOrderUseCase_GetOrderCoalescesLookups(t *testing.T) {
+	repo := &MockOrderRepo{validOrder: *expectedOrder, getBlock: make(chan struct{})}
+	uc, _ := setupUseCase(repo)
+	gets := func() int {
+		repo.mu.Lock()
+		defer repo.mu.Unlock()
+		return repo.gets
+	}
+
+	var wg sync.WaitGroup
+	orders := make([]*domain.Order, 10)
+	errs := make([]error, 10)
+	for i := range errs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			orders[i], errs[i] = uc.GetOrder(context.Background(), expectedOrder.OrderUID)
+		}()
+	}
+	assert.Eventually(t, func() bool { return gets() == 1 }, time.Second, time.Millisecond)
+	time.Sleep(20 * time.Millisecond) // let the other callers join the lookup
+	close(repo.getBlock)
+	wg.Wait()
+
+	for _, err := range errs {
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, 1, gets())
+	for i := 1; i < len(orders); i++ {
+		assert.NotSame(t, orders[0], orders[i], "callers must not share the order")
+	}
+
+	t.Run("caller gives up without canceling the lookup", func(t *testing.T) {
+		repo := &MockOrderRepo{validOrder: *expectedOrder, getBlock: make(chan struct{})}
+		uc, cache := setupUseCase(repo)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := uc.GetOrder(ctx, expectedOrder.OrderUID)
+		assert.ErrorIs(t, err, context.Canceled)
+
+		close(repo.getBlock)
+		assert.Eventually(t, func() bool {
+			_, ok := cache.Get(expectedOrder.OrderUID)
+			return ok
+		}, time.Second, time.Millisecond)
+	})
+}
+
+func TestOrderUseCase_GetOrderNotFound(t *testing.T) {
+	ctx := context.Background()
+	uid := expectedOrder.OrderUID
+
+	mockRepo := &MockOrderRepo{getErr: fmt.Errorf("get order: %w", repo.ErrNotFound)}
+	uc := NewOrderUseCase(mockRepo, NewMockCache(), WithNotFoundTTL(time.Minute))
+	now := time.Now()
+	uc.notFound.now = func() time.Time { return now }
+
+	for range 3 {
+		_, err := uc.GetOrder(ctx, uid)
+		assert.ErrorIs(t, err, repo.ErrNotFound)
+	}
+	assert.Equal(t, 1, mockRepo.gets)
+
+	now = now.Add(time.Minute)
+	_, err := uc.GetOrder(ctx, uid)
+	assert.ErrorIs(t, err, repo.ErrNotFound)
+	assert.Equal(t, 2, mockRepo.gets, "the miss expired")
+
+	assert.NoError(t, uc.CreateOrder(ctx, expectedOrder.Params()))
+	assert.False(t, uc.notFound.has(uid), "a stored order is not missing")
+
+	// a lookup that started before the order was stored is not remembered
+	gen := uc.notFound.generation()
+	uc.notFound.forget(uid)
+	uc.notFound.add(uid, gen)
+	assert.False(t, uc.notFound.has(uid))
+}
+
 func TestOrderUseCase_LoadOrdersCache(t *testing.T) {
 	repo := &MockOrderRepo{}
 	cache := NewMockCache()
@@ -245,3 +596,221 @@ func TestOrderUseCase_LoadOrdersCache(t *testing.T) {
 		assert.Equal(t, order.OrderUID, cached.OrderUID)
 	}
 }
+
+func TestOrderUseCase_RestoreOrdersCache(t *testing.T) {
+	ctx := context.Background()
+	updated := time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC)
+	order := func(uid string, at time.Time) *domain.Order {
+		return &domain.Order{OrderUID: uid, DateUpdated: at}
+	}
+
+	mockRepo := &MockOrderRepo{
+		stored: map[string]*domain.Order{
+			"current": order("current", updated),
+			"changed": order("changed", updated.Add(time.Hour)),
+			"key":     order("key", updated),
+		},
+		versions: map[string]time.Time{
+			"current": updated,
+			"changed": updated.Add(time.Hour),
+			"key":     updated,
+		},
+	}
+	uc, cache := setupUseCase(mockRepo)
+
+	snapshotted := order("current", updated)
+	snapshotted.TrackNumber = "FROM SNAPSHOT"
+	entries := []CacheEntry{
+		{OrderUID: "evicted"},
+		{OrderUID: "current", Order: snapshotted},
+		{OrderUID: "changed", Order: order("changed", updated)},
+		{OrderUID: "deleted", Order: order("deleted", updated)},
+		{OrderUID: "key"},
+	}
+
+	restored, err := uc.RestoreOrdersCache(ctx, entries, 4)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, restored)
+	assert.ElementsMatch(t, []string{"changed", "key"}, mockRepo.loaded, "only stale orders are read again")
+
+	got, ok := cache.Get("current")
+	assert.True(t, ok)
+	assert.Equal(t, "FROM SNAPSHOT", got.TrackNumber)
+	got, ok = cache.Get("changed")
+	assert.True(t, ok)
+	assert.Equal(t, updated.Add(time.Hour), got.DateUpdated)
+	_, ok = cache.Get("deleted")
+	assert.False(t, ok)
+	_, ok = cache.Get("evicted")
+	assert.False(t, ok, "entries beyond the limit are not restored")
+
+	restored, err = uc.RestoreOrdersCache(ctx, nil, 4)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, restored)
+}
+
+func TestOrderUseCase_SearchOrders(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("full page has next cursor", func(t *testing.T) {
+		mockRepo := &MockOrderRepo{}
+		uc, _ := setupUseCase(mockRepo)
+
+		page, err := uc.SearchOrders(ctx, domain.OrderFilter{Limit: 2})
+		assert.NoError(t, err)
+		assert.Equal(t, 3, mockRepo.filter.Limit)
+		assert.Len(t, page.Orders, 2)
+
+		cursor, err := domain.ParseOrderCursor(page.NextCursor)
+		assert.NoError(t, err)
+		assert.Equal(t, page.Orders[1].Id, cursor.Id)
+		assert.True(t, page.Orders[1].DateCreated.Equal(cursor.DateCreated))
+	})
+
+	t.Run("last page", func(t *testing.T) {
+		mockRepo := &MockOrderRepo{}
+		uc, _ := setupUseCase(mockRepo)
+
+		page, err := uc.SearchOrders(ctx, domain.OrderFilter{Limit: 3})
+		assert.NoError(t, err)
+		assert.Len(t, page.Orders, 3)
+		assert.Empty(t, page.NextCursor)
+	})
+
+	t.Run("repo error", func(t *testing.T) {
+		mockRepo := &MockOrderRepo{getErr: errors.New("repo error")}
+		uc, _ := setupUseCase(mockRepo)
+
+		page, err := uc.SearchOrders(ctx, domain.OrderFilter{Limit: 2})
+		assert.Error(t, err)
+		assert.Nil(t, page)
+	})
+}
+
+func TestOrderUseCase_ChangeOrderStatus(t *testing.T) {
+	ctx := context.Background()
+	created := *expectedOrder
+	created.Status = domain.StatusCreated
+	created.StatusHistory = []domain.StatusTransition{{Status: domain.StatusCreated, ChangedAt: created.DateCreated}}
+
+	t.Run("valid transition", func(t *testing.T) {
+		repo := &MockOrderRepo{validOrder: created}
+		uc, cache := setupUseCase(repo)
+
+		order, err := uc.ChangeOrderStatus(ctx, created.OrderUID, domain.StatusPaid)
+		assert.NoError(t, err)
+		assert.Equal(t, domain.StatusPaid, order.Status)
+		assert.Len(t, order.StatusHistory, 2)
+		assert.Same(t, order, repo.updated)
+
+		cached, ok := cache.Get(created.OrderUID)
+		assert.True(t, ok)
+		assert.Equal(t, domain.StatusPaid, cached.Status)
+		assert.Len(t, repo.validOrder.StatusHistory, 1)
+	})
+
+	t.Run("invalid transition", func(t *testing.T) {
+		repo := &MockOrderRepo{validOrder: created}
+		uc, _ := setupUseCase(repo)
+
+		order, err := uc.ChangeOrderStatus(ctx, created.OrderUID, domain.StatusDelivered)
+		assert.ErrorIs(t, err, domain.ErrInvalidState)
+		assert.Nil(t, order)
+		assert.Nil(t, repo.updated)
+	})
+
+	t.Run("repo error keeps cache untouched", func(t *testing.T) {
+		repo := &MockOrderRepo{validOrder: created, updateErr: errors.New("repo error")}
+		uc, cache := setupUseCase(repo)
+		cache.Set(&created)
+
+		order, err := uc.ChangeOrderStatus(ctx, created.OrderUID, domain.StatusCancelled)
+		assert.Error(t, err)
+		assert.Nil(t, order)
+
+		cached, ok := cache.Get(created.OrderUID)
+		assert.True(t, ok)
+		assert.Equal(t, domain.StatusCreated, cached.Status)
+	})
+}
+
+func TestOrderUseCase_UpdateOrder(t *testing.T) {
+	ctx := context.Background()
+	stored := *expectedOrder
+	stored.Status = domain.StatusPaid
+	stored.StatusHistory = []domain.StatusTransition{
+		{Status: domain.StatusCreated, ChangedAt: stored.DateCreated},
+		{Status: domain.StatusPaid, ChangedAt: stored.DateCreated.Add(time.Hour)},
+	}
+
+	t.Run("existing order is replaced", func(t *testing.T) {
+		mockRepo := &MockOrderRepo{validOrder: stored}
+		uc, cache := setupUseCase(mockRepo)
+
+		params := expectedOrder.Params()
+		params.TrackNumber = "WBILMNEWTRACK"
+		params.DateCreated = time.Now()
+
+		order, err := uc.UpdateOrder(ctx, params)
+		assert.NoError(t, err)
+		assert.Same(t, order, mockRepo.updated)
+		assert.Equal(t, "WBILMNEWTRACK", order.TrackNumber)
+		assert.Equal(t, stored.DateCreated, order.DateCreated)
+		assert.Equal(t, domain.StatusPaid, order.Status)
+		assert.Equal(t, stored.StatusHistory, order.StatusHistory)
+
+		cached, ok := cache.Get(expectedOrder.OrderUID)
+		assert.True(t, ok)
+		assert.Equal(t, "WBILMNEWTRACK", cached.TrackNumber)
+	})
+
+	t.Run("invalid params", func(t *testing.T) {
+		mockRepo := &MockOrderRepo{validOrder: stored}
+		uc, _ := setupUseCase(mockRepo)
+
+		params := expectedOrder.Params()
+		params.Items = nil
+
+		order, err := uc.UpdateOrder(ctx, params)
+		assert.ErrorIs(t, err, domain.ErrInvalidState)
+		assert.Nil(t, order)
+		assert.False(t, mockRepo.called)
+	})
+
+	t.Run("missing order", func(t *testing.T) {
+		mockRepo := &MockOrderRepo{getErr: repo.ErrNotFound}
+		uc, _ := setupUseCase(mockRepo)
+
+		order, err := uc.UpdateOrder(ctx, expectedOrder.Params())
+		assert.ErrorIs(t, err, repo.ErrNotFound)
+		assert.Nil(t, order)
+		assert.Nil(t, mockRepo.updated)
+	})
+}
+
+func TestOrderUseCase_UpsertOrder(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("creates missing order", func(t *testing.T) {
+		mockRepo := &MockOrderRepo{validOrder: *expectedOrder, getErr: repo.ErrNotFound}
+		uc, cache := setupUseCase(mockRepo)
+
+		order, created, err := uc.UpsertOrder(ctx, expectedOrder.Params())
+		assert.NoError(t, err)
+		assert.True(t, created)
+		assert.Equal(t, domain.StatusCreated, order.Status)
+
+		_, ok := cache.Get(expectedOrder.OrderUID)
+		assert.True(t, ok)
+	})
+
+	t.Run("updates existing order", func(t *testing.T) {
+		mockRepo := &MockOrderRepo{validOrder: *expectedOrder}
+		uc, _ := setupUseCase(mockRepo)
+
+		order, created, err := uc.UpsertOrder(ctx, expectedOrder.Params())
+		assert.NoError(t, err)
+		assert.False(t, created)
+		assert.Same(t, order, mockRepo.updated)
+	})
+}
@@ -3,11 +3,18 @@ package usecase
 import (
 	"context"
 	"order-service/internal/domain"
+	"time"
 )
 
 type OrderRepository interface {
 	SaveOrder(ctx context.Context, order *domain.Order) error
+	SaveOrders(ctx context.Context, orders []*domain.Order) ([]error, error)
 	GetOrderByUid(ctx context.Context, orderUID string) (*domain.Order, error)
-	CheckIdempotencyKey(ctx context.Context, key string) (bool, error)
+	GetContentHash(ctx context.Context, orderUID string) (string, error)
 	GetLastOrders(ctx context.Context, limit int) ([]*domain.Order, error)
+	GetOrdersByUids(ctx context.Context, uids []string) ([]*domain.Order, error)
+	GetOrderVersions(ctx context.Context, uids []string) (map[string]time.Time, error)
+	UpdateOrder(ctx context.Context, order *domain.Order) error
+	UpdateOrderStatus(ctx context.Context, order *domain.Order) error
+	SearchOrders(ctx context.Context, filter domain.OrderFilter) ([]*domain.Order, error)
 }
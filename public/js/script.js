@@ -1,6 +1,6 @@
 
 
-function formatCurrency(amount, currency = 'USD') {
+function formatCurrency(amount, currency = 'USD', exponent = 2) {
   const validCurrencies = new Set([
     'USD', 'EUR', 'RUB', 'GBP', 'JPY', 'CNY', 'KZT', 'UAH', 'BYN', 'PLN', 'INR', 'BRL', 'CAD', 'AUD'
   ]);
@@ -13,7 +13,7 @@ function formatCurrency(amount, currency = 'USD') {
   const formatted = new Intl.NumberFormat('en-US', {
     style: 'currency',
     currency: currency.toUpperCase()
-  }).format(amount);
+  }).format(amount / Math.pow(10, exponent));
 
   return formatted;
 }
@@ -50,7 +50,7 @@ function displayOrder(orderData) {
           </div>
           <div class="order-status-section">
             <div class="order-date">Created ${formatDate(orderData.date_created)}</div>
-            <div class="order-total-badge">${formatCurrency(orderData.payment.amount, orderData.payment.currency)}</div>
+            <div class="order-total-badge">${formatCurrency(orderData.payment.amount, orderData.payment.currency, orderData.payment.currency_exponent)}</div>
           </div>
         </div>
         <div class="order-meta">
@@ -79,10 +79,10 @@ function displayOrder(orderData) {
             <div class="info-grid">
               <div class="info-row"><span class="info-label">Provider</span><span class="info-value">${orderData.payment.provider.toUpperCase()}</span></div>
               <div class="info-row"><span class="info-label">Bank</span><span class="info-value">${orderData.payment.bank.toUpperCase()}</span></div>
-              <div class="info-row"><span class="info-label">Items Subtotal</span><span class="info-value">${formatCurrency(orderData.payment.goods_total, orderData.payment.currency)}</span></div>
-              <div class="info-row"><span class="info-label">Delivery Cost</span><span class="info-value">${formatCurrency(orderData.payment.delivery_cost, orderData.payment.currency)}</span></div>
-              <div class="info-row"><span class="info-label">Custom Fees</span><span class="info-value">${formatCurrency(orderData.payment.custom_fee, orderData.payment.currency)}</span></div>
-              <div class="info-row total-row"><span class="info-label">Total Amount</span><span class="info-value">${formatCurrency(orderData.payment.amount, orderData.payment.currency)}</span></div>
+              <div class="info-row"><span class="info-label">Items Subtotal</span><span class="info-value">${formatCurrency(orderData.payment.goods_total, orderData.payment.currency, orderData.payment.currency_exponent)}</span></div>
+              <div class="info-row"><span class="info-label">Delivery Cost</span><span class="info-value">${formatCurrency(orderData.payment.delivery_cost, orderData.payment.currency, orderData.payment.currency_exponent)}</span></div>
+              <div class="info-row"><span class="info-label">Custom Fees</span><span class="info-value">${formatCurrency(orderData.payment.custom_fee, orderData.payment.currency, orderData.payment.currency_exponent)}</span></div>
+              <div class="info-row total-row"><span class="info-label">Total Amount</span><span class="info-value">${formatCurrency(orderData.payment.amount, orderData.payment.currency, orderData.payment.currency_exponent)}</span></div>
             </div>
           </div>
         </div>
@@ -111,9 +111,9 @@ function displayOrder(orderData) {
                   <td class="brand-cell">${item.brand}</td>
                   <td>${item.chrt_id}</td>
                   <td>${item.size || 'N/A'}</td>
-                  <td class="price-cell"><span class="price-original">${formatCurrency(item.price, orderData.payment.currency)}</span></td>
+                  <td class="price-cell"><span class="price-original">${formatCurrency(item.price, orderData.payment.currency, orderData.payment.currency_exponent)}</span></td>
                   <td><span class="price-sale">-${item.sale}%</span></td>
-                  <td class="price-cell">${formatCurrency(item.total_price, orderData.payment.currency)}</td>
+                  <td class="price-cell">${formatCurrency(item.total_price, orderData.payment.currency, orderData.payment.currency_exponent)}</td>
                   <td>${getStatusBadge(item.status)}</td>
                   <td><span class="track-cell">${item.track_number}</span></td>
                 </tr>